@@ -0,0 +1,139 @@
+// Package apperrors holds the typed errors of the application. Controllers,
+// domain services and handlers fail with an *Error whose kind tells what went
+// wrong, and the Problems middleware renders it as a problem+json response.
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind is the kind of failure of an operation
+type Kind int
+
+const (
+	// KindInternal is a failure of the database or of the application itself
+	KindInternal Kind = iota
+	// KindValidation is an input breaking the rules of the operation
+	KindValidation
+	// KindNotFound is a missing record the operation applies to
+	KindNotFound
+	// KindConflict is an operation clashing with the current state
+	KindConflict
+	// KindForbidden is an actor not allowed to perform the operation
+	KindForbidden
+	// KindUnauthorized is a caller whose identity could not be established
+	KindUnauthorized
+	// KindUpstream is a failure of TOSS or another service the operation relies on
+	KindUpstream
+	// KindTooLarge is an upload over the size limit
+	KindTooLarge
+	// KindUnsupportedMedia is an upload of a type that is not allowed
+	KindUnsupportedMedia
+	// KindUnprocessable is a well formed input the operation refuses, like an
+	// infected file
+	KindUnprocessable
+)
+
+// Error is the failure of an operation. Err is its cause, if any.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return "internal error"
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns an error of the kind with the message
+func New(kind Kind, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap returns an error of the kind caused by err. The message defaults to the
+// one of err. If err is already typed it is returned as it is, since the code
+// that failed knows best what went wrong.
+func Wrap(kind Kind, message string, err error) error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// Invalid fails the operation because of its input
+func Invalid(message string) error {
+	return New(KindValidation, message)
+}
+
+// Validation fails the operation with the error of binding the request or of
+// the Validate method of a model
+func Validation(err error) error {
+	return Wrap(KindValidation, "", err)
+}
+
+func NotFound(message string) error {
+	return New(KindNotFound, message)
+}
+
+func Conflict(message string) error {
+	return New(KindConflict, message)
+}
+
+func Forbidden(message string) error {
+	return New(KindForbidden, message)
+}
+
+func Unauthorized(message string) error {
+	return New(KindUnauthorized, message)
+}
+
+// Upstream fails the operation because TOSS could not be reached or failed
+func Upstream(message string, err error) error {
+	return Wrap(KindUpstream, message, err)
+}
+
+// Internal fails the operation because of the database or a bug
+func Internal(err error) error {
+	return Wrap(KindInternal, "", err)
+}
+
+// FromStatus converts the failure of a TOSS check of utils, which comes with
+// the HTTP status it used to be answered with. Server errors are failures of
+// TOSS.
+func FromStatus(code int, err error) error {
+	switch code {
+	case http.StatusBadRequest:
+		return Wrap(KindValidation, "", err)
+	case http.StatusNotFound:
+		return Wrap(KindNotFound, "", err)
+	case http.StatusConflict:
+		return Wrap(KindConflict, "", err)
+	case http.StatusForbidden:
+		return Wrap(KindForbidden, "", err)
+	case http.StatusUnauthorized:
+		return Wrap(KindUnauthorized, "", err)
+	}
+
+	return Wrap(KindUpstream, "", err)
+}
+
+// KindOf returns the kind of failure of err, KindInternal if it is not typed
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindInternal
+}
@@ -0,0 +1,250 @@
+// Command openapigen turns the annotations of the handlers of the service
+// package into the operations the docs package documents the routes with. See
+// the docs package for the annotations.
+//
+//	go generate ./docs
+//
+// With -check it writes nothing and fails if the operations are stale.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// operation is a handler and the Go source of its documentation
+type operation struct {
+	key       string
+	summary   string
+	auth      bool
+	query     []string
+	form      []string
+	body      string
+	responses []string
+}
+
+var (
+	typeExpr  = regexp.MustCompile(`^(\[\])?(\w+)\.(\w+)$`)
+	paramType = map[string]bool{"string": true, "integer": true, "number": true, "boolean": true, "date": true, "file": true}
+)
+
+func main() {
+	serviceDir := flag.String("service", "service", "directory of the service package")
+	out := flag.String("out", "docs/operations_gen.go", "file to write the operations to")
+	check := flag.Bool("check", false, "fail if the operations are stale instead of writing them")
+	flag.Parse()
+
+	source, err := generate(*serviceDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *check {
+		current, err := os.ReadFile(*out)
+		if err != nil || !bytes.Equal(current, source) {
+			fmt.Fprintf(os.Stderr, "%s is stale, run go generate ./docs\n", *out)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := os.WriteFile(*out, source, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// generate parses the annotated handlers of the package in dir and returns the
+// source of the operations
+func generate(dir string) ([]byte, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Annotations name types by the qualifiers of the imports of the package
+	fset := token.NewFileSet()
+	var files []*ast.File
+	pkgImports := map[string]string{}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			name := importPath[strings.LastIndex(importPath, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			pkgImports[name] = importPath
+		}
+	}
+
+	var operations []operation
+	imports := map[string]string{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Doc == nil || !fn.Name.IsExported() {
+				continue
+			}
+
+			op, err := parseOperation(fset, fn, pkgImports, imports)
+			if err != nil {
+				return nil, err
+			}
+			if op != nil {
+				operations = append(operations, *op)
+			}
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool { return operations[i].key < operations[j].key })
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by openapigen from the annotations of the handlers. DO NOT EDIT.\n\n")
+	src.WriteString("package docs\n\nimport (\n\t\"reflect\"\n\n")
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&src, "\t%q\n", imports[name])
+	}
+	src.WriteString(")\n\nvar operations = map[string]operation{\n")
+	for _, op := range operations {
+		fmt.Fprintf(&src, "%q: {\nSummary: %q,\n", op.key, op.summary)
+		if op.auth {
+			src.WriteString("Auth: true,\n")
+		}
+		if len(op.query) > 0 {
+			fmt.Fprintf(&src, "Query: []param{\n%s},\n", strings.Join(op.query, ""))
+		}
+		if len(op.form) > 0 {
+			fmt.Fprintf(&src, "Form: []param{\n%s},\n", strings.Join(op.form, ""))
+		}
+		if op.body != "" {
+			fmt.Fprintf(&src, "Body: %s,\n", op.body)
+		}
+		fmt.Fprintf(&src, "Responses: []response{\n%s},\n},\n", strings.Join(op.responses, ""))
+	}
+	src.WriteString("}\n")
+
+	return format.Source(src.Bytes())
+}
+
+// parseOperation reads the annotations of the doc comment of a handler. It
+// returns nil if the method is not annotated.
+func parseOperation(fset *token.FileSet, fn *ast.FuncDecl, pkgImports, imports map[string]string) (*operation, error) {
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return nil, nil
+	}
+
+	op := &operation{key: ident.Name + "." + fn.Name.Name}
+	annotated := false
+	for _, comment := range fn.Doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		annotated = true
+
+		fail := func(format string, args ...interface{}) error {
+			return fmt.Errorf("%s: %s: %s", fset.Position(comment.Pos()), op.key, fmt.Sprintf(format, args...))
+		}
+		goType := func(expr string) (string, error) {
+			if expr == "object" {
+				return "reflect.TypeOf(map[string]interface{}{})", nil
+			}
+			match := typeExpr.FindStringSubmatch(expr)
+			if match == nil || pkgImports[match[2]] == "" {
+				return "", fail("invalid type %q", expr)
+			}
+			imports[match[2]] = pkgImports[match[2]]
+			return fmt.Sprintf("reflect.TypeOf((*%s)(nil)).Elem()", expr), nil
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "@summary":
+			op.summary = strings.TrimSpace(strings.TrimPrefix(line, "@summary"))
+		case "@auth":
+			op.auth = true
+		case "@query", "@form":
+			if len(fields) < 3 || !paramType[fields[2]] {
+				return nil, fail("expected %s <name> <type> <description>", fields[0])
+			}
+			param := fmt.Sprintf("{Name: %q, Type: %q, Description: %q},\n", fields[1], fields[2], strings.Join(fields[3:], " "))
+			if fields[0] == "@query" {
+				op.query = append(op.query, param)
+			} else {
+				op.form = append(op.form, param)
+			}
+		case "@body":
+			if len(fields) != 2 {
+				return nil, fail("expected @body <type>")
+			}
+			body, err := goType(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			op.body = body
+		case "@success":
+			if len(fields) < 2 || len(fields) > 4 {
+				return nil, fail("expected @success <status> [<type>]")
+			}
+			status, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fail("invalid status %q", fields[1])
+			}
+			switch {
+			case len(fields) == 2:
+				op.responses = append(op.responses, fmt.Sprintf("{Status: %d},\n", status))
+			case fields[2] == "file" && len(fields) == 4:
+				op.responses = append(op.responses, fmt.Sprintf("{Status: %d, MediaType: %q},\n", status, fields[3]))
+			case len(fields) == 3:
+				respType, err := goType(fields[2])
+				if err != nil {
+					return nil, err
+				}
+				op.responses = append(op.responses, fmt.Sprintf("{Status: %d, Type: %s},\n", status, respType))
+			default:
+				return nil, fail("expected @success <status> [<type>]")
+			}
+		default:
+			return nil, fail("unknown annotation %s", fields[0])
+		}
+	}
+
+	if !annotated {
+		return nil, nil
+	}
+	if op.summary == "" || len(op.responses) == 0 {
+		return nil, fmt.Errorf("%s: %s: a handler needs a @summary and a @success", fset.Position(fn.Pos()), op.key)
+	}
+
+	return op, nil
+}
@@ -0,0 +1,8 @@
+package constants
+
+// Analytics group by options
+const (
+	GROUP_BY_TEAM        = "team"
+	GROUP_BY_DESIGNATION = "designation"
+	GROUP_BY_SUPERVISOR  = "supervisor"
+)
@@ -0,0 +1,21 @@
+package constants
+
+// States of an appeal
+const (
+	APPEAL_STATUS_FILED        = "filed"
+	APPEAL_STATUS_UNDER_REVIEW = "under_review"
+	APPEAL_STATUS_UPHELD       = "upheld"
+	APPEAL_STATUS_ADJUSTED     = "adjusted"
+)
+
+// Reviewers an appeal is routed to
+const (
+	APPEAL_ROUTE_HR         = "hr"
+	APPEAL_ROUTE_SKIP_LEVEL = "skip_level"
+)
+
+// Number of days the reviewer has to resolve an appeal
+const APPEAL_REVIEW_DAYS = 14
+
+// Source of the score adjustments made by resolving appeals
+const ADJUSTMENT_SOURCE_APPEAL = "appeal"
@@ -5,3 +5,49 @@ const (
 	MID_YEAR_APPRAISAL = "Mid-Year"
 	ANNUAL_APPRAISAL   = "Annual"
 )
+
+// Appraisal status of an employee in an appraisal
+const (
+	APPRAISAL_STATUS_PENDING   = "pending"
+	APPRAISAL_STATUS_PUBLISHED = "published"
+)
+
+// Reasons an evaluation task is assigned to an evaluator
+const (
+	ASSIGNED_AS_SUPERVISOR = "supervisor"
+	ASSIGNED_AS_FLOW_STEP  = "flow_step"
+	ASSIGNED_AS_DELEGATE   = "delegate"
+	// The supervisor the employee was transferred from, when scoring is split
+	ASSIGNED_AS_PREVIOUS_SUPERVISOR = "previous_supervisor"
+)
+
+// Sort orders of the evaluation inbox
+const (
+	INBOX_SORT_URGENCY  = "urgency"
+	INBOX_SORT_PENDING  = "pending"
+	INBOX_SORT_EMPLOYEE = "employee"
+)
+
+// Actions recorded in the appraisal history
+const (
+	HISTORY_ACTION_SCORED                  = "scored"
+	HISTORY_ACTION_QUESTIONNAIRE_SUBMITTED = "questionnaire_submitted"
+	HISTORY_ACTION_SCORE_ADJUSTED          = "score_adjusted"
+	HISTORY_ACTION_ACKNOWLEDGED            = "acknowledged"
+	HISTORY_ACTION_DISAGREED               = "disagreed"
+	HISTORY_ACTION_APPEAL_FILED            = "appeal_filed"
+	HISTORY_ACTION_APPEAL_UNDER_REVIEW     = "appeal_under_review"
+	HISTORY_ACTION_APPEAL_RESOLVED         = "appeal_resolved"
+	HISTORY_ACTION_PIP_OPENED              = "pip_opened"
+	HISTORY_ACTION_PIP_EXTENDED            = "pip_extended"
+	HISTORY_ACTION_PIP_CLOSED              = "pip_closed"
+	HISTORY_ACTION_TRANSFERRED             = "transferred"
+	HISTORY_ACTION_ENROLLED                = "enrolled"
+	HISTORY_ACTION_WITHDRAWN               = "withdrawn"
+)
+
+// Decisions of an employee on their published results
+const (
+	ACKNOWLEDGEMENT_ACKNOWLEDGED = "acknowledged"
+	ACKNOWLEDGEMENT_DISAGREED    = "disagreed"
+)
@@ -0,0 +1,18 @@
+package constants
+
+// Default maximum size of an attachment, overridden by ATTACHMENT_MAX_SIZE_MB
+const ATTACHMENT_MAX_SIZE_MB = 10
+
+// Content types accepted for attachments, by file extension
+var ATTACHMENT_CONTENT_TYPES = map[string]string{
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".txt":  "text/plain",
+	".csv":  "text/csv",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
@@ -0,0 +1,14 @@
+package constants
+
+// Roles of the authors of comments
+const (
+	COMMENT_ROLE_EMPLOYEE   = "employee"
+	COMMENT_ROLE_SUPERVISOR = "supervisor"
+	COMMENT_ROLE_HR         = "hr"
+)
+
+// Visibility levels of comments
+const (
+	COMMENT_VISIBILITY_SHARED     = "shared"
+	COMMENT_VISIBILITY_HR_PRIVATE = "hr_private"
+)
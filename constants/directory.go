@@ -0,0 +1,34 @@
+package constants
+
+// States of a directory sync run
+const (
+	SYNC_STATUS_RUNNING   = "running"
+	SYNC_STATUS_SUCCEEDED = "succeeded"
+	SYNC_STATUS_FAILED    = "failed"
+)
+
+// Entities of the TOSS directory
+const (
+	DIRECTORY_ENTITY_EMPLOYEE    = "employee"
+	DIRECTORY_ENTITY_DESIGNATION = "designation"
+	DIRECTORY_ENTITY_PROJECT     = "project"
+)
+
+// Changes detected by the directory sync
+const (
+	DIRECTORY_CHANGE_JOINED              = "joined"
+	DIRECTORY_CHANGE_LEFT                = "left"
+	DIRECTORY_CHANGE_RENAMED             = "renamed"
+	DIRECTORY_CHANGE_DESIGNATION_CHANGED = "designation_changed"
+	DIRECTORY_CHANGE_TEAM_MOVED          = "team_moved"
+	DIRECTORY_CHANGE_ADDED               = "added"
+	DIRECTORY_CHANGE_REMOVED             = "removed"
+)
+
+// Default interval of the directory sync in minutes, overridden by
+// TOSS_SYNC_INTERVAL_MINUTES. A negative interval disables the periodic sync.
+const TOSS_SYNC_INTERVAL_MINUTES = 360
+
+// Default number of concurrent TOSS lookups made when enrolling employees,
+// overridden by TOSS_LOOKUP_WORKERS
+const TOSS_LOOKUP_WORKERS = 8
@@ -0,0 +1,8 @@
+package constants
+
+// States of an increment recommendation
+const (
+	RECOMMENDATION_STATUS_DRAFT      = "draft"
+	RECOMMENDATION_STATUS_OVERRIDDEN = "overridden"
+	RECOMMENDATION_STATUS_APPROVED   = "approved"
+)
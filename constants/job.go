@@ -0,0 +1,26 @@
+package constants
+
+import "time"
+
+// States of a background job
+const (
+	JOB_STATUS_QUEUED    = "queued"
+	JOB_STATUS_RUNNING   = "running"
+	JOB_STATUS_SUCCEEDED = "succeeded"
+	JOB_STATUS_FAILED    = "failed"
+)
+
+// Types of background jobs
+const (
+	JOB_TYPE_CREATE_APPRAISAL            = "create_appraisal"
+	JOB_TYPE_CREATE_CAMPAIGN             = "create_campaign"
+	JOB_TYPE_SYNC_DIRECTORY              = "sync_directory"
+	JOB_TYPE_APPLY_ROSTER_RECONCILIATION = "apply_roster_reconciliation"
+	JOB_TYPE_GENERATE_RECOMMENDATIONS    = "generate_recommendations"
+)
+
+// Default number of jobs run concurrently, overridden by JOB_WORKERS
+const JOB_WORKERS = 4
+
+// Time a job is given before its work is cancelled
+const JOB_TIMEOUT = 30 * time.Minute
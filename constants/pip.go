@@ -0,0 +1,26 @@
+package constants
+
+// States of a performance improvement plan
+const (
+	PIP_STATUS_OPEN     = "open"
+	PIP_STATUS_EXTENDED = "extended"
+	PIP_STATUS_CLOSED   = "closed"
+)
+
+// Outcomes of a performance improvement plan
+const (
+	PIP_OUTCOME_SUCCESSFUL   = "successful"
+	PIP_OUTCOME_UNSUCCESSFUL = "unsuccessful"
+	PIP_OUTCOME_EXTENDED     = "extended"
+)
+
+// States of a plan objective
+const (
+	PIP_OBJECTIVE_PENDING = "pending"
+	PIP_OBJECTIVE_MET     = "met"
+	PIP_OBJECTIVE_NOT_MET = "not_met"
+)
+
+// Final score below which employees are suggested for a plan, unless the
+// threshold is given in the request
+const PIP_SCORE_THRESHOLD = 60.0
@@ -0,0 +1,7 @@
+package constants
+
+import "time"
+
+// Default deadline of a request, overridden by REQUEST_TIMEOUT_SECONDS. Routes
+// with deadlines of their own are listed in routes/deadlines.go.
+const REQUEST_TIMEOUT = 30 * time.Second
@@ -0,0 +1,15 @@
+package constants
+
+// States of a roster reconciliation
+const (
+	RECONCILIATION_STATUS_PROPOSED   = "proposed"
+	RECONCILIATION_STATUS_APPLIED    = "applied"
+	RECONCILIATION_STATUS_REJECTED   = "rejected"
+	RECONCILIATION_STATUS_SUPERSEDED = "superseded"
+)
+
+// Changes of a roster reconciliation
+const (
+	ROSTER_CHANGE_ENROLL   = "enroll"
+	ROSTER_CHANGE_WITHDRAW = "withdraw"
+)
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// ResultsHash returns the SHA-256 hash of the results of an employee: the computed
+// and final scores along with every kpi score they were computed from
+func ResultsHash(db *gorm.DB, appraisalID, empID uint64) (string, error) {
+	result, err := GetEmployeeResult(db, appraisalID, empID)
+	if err != nil {
+		return "", err
+	}
+
+	var scores []models.Score
+	err = db.Model(&models.Score{}).
+		Joins("JOIN appraisal_kpis ON appraisal_kpis.id = scores.appraisal_kpi_id").
+		Where("appraisal_kpis.appraisal_id = ? AND appraisal_kpis.employee_id = ?", appraisalID, empID).
+		Order("scores.id ASC").
+		Find(&scores).Error
+	if err != nil {
+		log.Error(err.Error())
+		return "", err
+	}
+
+	type hashedScore struct {
+		AppraisalKpiID uint16   `json:"appraisal_kpi_id"`
+		EvaluatorID    uint16   `json:"evaluator_id"`
+		Score          *uint16  `json:"score"`
+		Percentage     *float64 `json:"percentage"`
+		TextAnswer     string   `json:"text_answer"`
+	}
+	content := struct {
+		AppraisalID   uint16        `json:"appraisal_id"`
+		EmployeeID    uint16        `json:"employee_id"`
+		ComputedScore *float64      `json:"computed_score"`
+		FinalScore    *float64      `json:"final_score"`
+		Scores        []hashedScore `json:"scores"`
+	}{
+		AppraisalID:   result.AppraisalID,
+		EmployeeID:    result.EmployeeID,
+		ComputedScore: result.ComputedScore,
+		FinalScore:    result.FinalScore,
+		Scores:        make([]hashedScore, 0, len(scores)),
+	}
+	for _, score := range scores {
+		content.Scores = append(content.Scores, hashedScore{
+			AppraisalKpiID: score.AppraisalKpiID,
+			EvaluatorID:    score.EvaluatorID,
+			Score:          score.Score,
+			Percentage:     score.Percentage,
+			TextAnswer:     score.TextAnswer,
+		})
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		log.Error(err.Error())
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AcknowledgeResults stores the sign-off of an employee on their published
+// results. An employee signs off on the results of an appraisal only once.
+func AcknowledgeResults(db *gorm.DB, acknowledgement *models.Acknowledgement) (*models.Acknowledgement, error) {
+	log.Info("Acknowledging appraisal results")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var employeeData models.EmployeeData
+		err := tx.Model(&models.EmployeeData{}).
+			Where("appraisal_id = ? AND toss_emp_id = ?", acknowledgement.AppraisalID, acknowledgement.EmployeeID).
+			First(&employeeData).Error
+		if err != nil {
+			return err
+		}
+		if employeeData.AppraisalStatus != constants.APPRAISAL_STATUS_PUBLISHED {
+			return apperrors.Conflict("results are not published yet")
+		}
+
+		var count int64
+		err = tx.Model(&models.Acknowledgement{}).
+			Where("appraisal_id = ? AND employee_id = ?", acknowledgement.AppraisalID, acknowledgement.EmployeeID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.Conflict("results are already acknowledged")
+		}
+
+		hash, err := ResultsHash(tx, uint64(acknowledgement.AppraisalID), uint64(acknowledgement.EmployeeID))
+		if err != nil {
+			return err
+		}
+		acknowledgement.ResultsHash = hash
+		acknowledgement.AcknowledgedAt = time.Now()
+
+		if err := tx.Create(acknowledgement).Error; err != nil {
+			return err
+		}
+
+		action := constants.HISTORY_ACTION_ACKNOWLEDGED
+		if acknowledgement.Decision == constants.ACKNOWLEDGEMENT_DISAGREED {
+			action = constants.HISTORY_ACTION_DISAGREED
+		}
+		return RecordHistory(tx, &models.AppraisalHistory{
+			AppraisalID: acknowledgement.AppraisalID,
+			EmployeeID:  acknowledgement.EmployeeID,
+			Action:      action,
+			ActorID:     acknowledgement.EmployeeID,
+			Details:     acknowledgement.Comments,
+		})
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return acknowledgement, nil
+}
+
+// GetAcknowledgement returns the sign-off of an employee and whether their results
+// changed since they signed off
+func GetAcknowledgement(db *gorm.DB, acknowledgement *models.Acknowledgement, appraisalID, empID uint64) error {
+	log.Info("Getting acknowledgement")
+
+	err := db.Model(&models.Acknowledgement{}).
+		Where("appraisal_id = ? AND employee_id = ?", appraisalID, empID).
+		First(acknowledgement).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	hash, err := ResultsHash(db, appraisalID, empID)
+	if err != nil {
+		return err
+	}
+	acknowledgement.ResultsChanged = hash != acknowledgement.ResultsHash
+
+	return nil
+}
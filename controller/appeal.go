@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// FileAppeal stores the appeal of an employee against the ratings of the given
+// kpis. An employee has at most one open appeal per appraisal.
+func FileAppeal(db *gorm.DB, appeal *models.Appeal) (*models.Appeal, error) {
+	log.Info("Filing appeal")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var employeeData models.EmployeeData
+		err := tx.Model(&models.EmployeeData{}).
+			Where("appraisal_id = ? AND toss_emp_id = ?", appeal.AppraisalID, appeal.EmployeeID).
+			First(&employeeData).Error
+		if err != nil {
+			return err
+		}
+		if employeeData.AppraisalStatus != constants.APPRAISAL_STATUS_PUBLISHED {
+			return apperrors.Conflict("results are not published yet")
+		}
+		appeal.EmployeeName = employeeData.EmployeeName
+
+		// Every contested kpi must be a scored kpi of the employee
+		var count int64
+		err = tx.Model(&models.AppraisalKpi{}).
+			Where("id IN ? AND appraisal_id = ? AND employee_id = ?", []int64(appeal.AppraisalKpiIDs), appeal.AppraisalID, appeal.EmployeeID).
+			Where(scoredCondition).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if int(count) != len(appeal.AppraisalKpiIDs) {
+			return apperrors.Invalid("appeals can only be filed against scored kpis of the employee")
+		}
+
+		err = tx.Model(&models.Appeal{}).
+			Where("appraisal_id = ? AND employee_id = ? AND status IN ?", appeal.AppraisalID, appeal.EmployeeID,
+				[]string{constants.APPEAL_STATUS_FILED, constants.APPEAL_STATUS_UNDER_REVIEW}).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.Conflict("an appeal is already open against the appraisal")
+		}
+
+		appeal.Status = constants.APPEAL_STATUS_FILED
+		appeal.DueDate = time.Now().AddDate(0, 0, constants.APPEAL_REVIEW_DAYS)
+
+		if err := tx.Create(appeal).Error; err != nil {
+			return err
+		}
+
+		return RecordHistory(tx, &models.AppraisalHistory{
+			AppraisalID: appeal.AppraisalID,
+			EmployeeID:  appeal.EmployeeID,
+			Action:      constants.HISTORY_ACTION_APPEAL_FILED,
+			ActorID:     appeal.EmployeeID,
+			Details:     fmt.Sprintf("appeal_id %v routed to %s", appeal.ID, appeal.RoutedTo),
+		})
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	appeal.Overdue = isAppealOverdue(appeal, time.Now())
+	return appeal, nil
+}
+
+func GetAppealByID(db *gorm.DB, appeal *models.Appeal, id uint64) error {
+	log.Info("Getting appeal by ID")
+
+	err := db.Model(&models.Appeal{}).Preload("Adjustment").Preload("Comments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("commented_at ASC, id ASC")
+	}).Where("id = ?", id).First(appeal).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	appeal.Overdue = isAppealOverdue(appeal, time.Now())
+	return nil
+}
+
+func GetAllAppeals(db *gorm.DB, appeals *[]models.Appeal) error {
+	log.Info("Getting all appeals")
+
+	err := db.Order("due_date ASC, id ASC").Find(appeals).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	now := time.Now()
+	for k := range *appeals {
+		(*appeals)[k].Overdue = isAppealOverdue(&(*appeals)[k], now)
+	}
+
+	return nil
+}
+
+// ReviewAppeal moves a filed appeal under review of the reviewer. An appeal
+// routed to the skip-level supervisor can only be reviewed by them.
+func ReviewAppeal(db *gorm.DB, appeal *models.Appeal, reviewerID uint16, reviewerName string) (*models.Appeal, error) {
+	log.Info("Reviewing appeal")
+
+	if appeal.Status != constants.APPEAL_STATUS_FILED {
+		log.Error("appeal is not in filed state")
+		return nil, apperrors.Conflict(fmt.Sprintf("appeal is %s, only filed appeals can be taken under review", appeal.Status))
+	}
+	if appeal.RoutedTo == constants.APPEAL_ROUTE_SKIP_LEVEL && appeal.ReviewerID != reviewerID {
+		log.Error("reviewer is not the skip-level supervisor")
+		return nil, apperrors.Forbidden("appeal is routed to another reviewer")
+	}
+	if reviewerID == appeal.EmployeeID {
+		log.Error("employee cannot review their own appeal")
+		return nil, apperrors.Forbidden("employee cannot review their own appeal")
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(appeal).Updates(map[string]interface{}{
+			"status":        constants.APPEAL_STATUS_UNDER_REVIEW,
+			"reviewer_id":   reviewerID,
+			"reviewer_name": reviewerName,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return RecordHistory(tx, &models.AppraisalHistory{
+			AppraisalID: appeal.AppraisalID,
+			EmployeeID:  appeal.EmployeeID,
+			Action:      constants.HISTORY_ACTION_APPEAL_UNDER_REVIEW,
+			ActorID:     reviewerID,
+			Details:     fmt.Sprintf("appeal_id %v", appeal.ID),
+		})
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	appeal.Status = constants.APPEAL_STATUS_UNDER_REVIEW
+	appeal.ReviewerID = reviewerID
+	appeal.ReviewerName = reviewerName
+	return appeal, nil
+}
+
+// ResolveAppeal closes an appeal under review. An adjusted appeal changes the
+// final score of the employee through a score adjustment, keeping raw scores
+// intact. The caller checks that the appeal is resolved by its reviewer or HR.
+func ResolveAppeal(db *gorm.DB, appeal *models.Appeal, resolution *models.AppealResolution) (*models.Appeal, error) {
+	log.Info("Resolving appeal")
+
+	if appeal.Status != constants.APPEAL_STATUS_UNDER_REVIEW {
+		log.Error("appeal is not under review")
+		return nil, apperrors.Conflict(fmt.Sprintf("appeal is %s, only appeals under review can be resolved", appeal.Status))
+	}
+
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"status":      resolution.Decision,
+			"resolution":  resolution.Resolution,
+			"resolved_by": resolution.ResolvedBy,
+			"resolved_at": now,
+		}
+
+		if resolution.Decision == constants.APPEAL_STATUS_ADJUSTED {
+			adjustment, err := CreateScoreAdjustment(tx, &models.ScoreAdjustment{
+				AppraisalID:   appeal.AppraisalID,
+				EmployeeID:    appeal.EmployeeID,
+				AdjustedScore: resolution.AdjustedScore,
+				Justification: resolution.Resolution,
+				AdjustedBy:    resolution.ResolvedBy,
+				Source:        constants.ADJUSTMENT_SOURCE_APPEAL,
+			})
+			if err != nil {
+				return err
+			}
+			updates["adjustment_id"] = adjustment.ID
+			appeal.AdjustmentID = &adjustment.ID
+			appeal.Adjustment = adjustment
+		}
+
+		if err := tx.Model(appeal).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return RecordHistory(tx, &models.AppraisalHistory{
+			AppraisalID: appeal.AppraisalID,
+			EmployeeID:  appeal.EmployeeID,
+			Action:      constants.HISTORY_ACTION_APPEAL_RESOLVED,
+			ActorID:     resolution.ResolvedBy,
+			Details:     fmt.Sprintf("appeal_id %v %s", appeal.ID, resolution.Decision),
+		})
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	appeal.Status = resolution.Decision
+	appeal.Resolution = resolution.Resolution
+	appeal.ResolvedBy = resolution.ResolvedBy
+	appeal.ResolvedAt = &now
+	appeal.Overdue = false
+	return appeal, nil
+}
+
+// AddAppealComment adds a comment to the trail of an appeal. Resolved appeals are
+// closed for comments.
+func AddAppealComment(db *gorm.DB, appeal *models.Appeal, comment *models.AppealComment) (*models.AppealComment, error) {
+	log.Info("Adding appeal comment")
+
+	if appeal.ResolvedAt != nil {
+		log.Error("appeal is resolved")
+		return nil, apperrors.Conflict("appeal is resolved")
+	}
+
+	comment.AppealID = appeal.ID
+	comment.CommentedAt = time.Now()
+
+	if err := db.Create(comment).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// isAppealOverdue reports whether the appeal is still open past its due date
+func isAppealOverdue(appeal *models.Appeal, at time.Time) bool {
+	return appeal.ResolvedAt == nil && appeal.DueDate.Before(at)
+}
@@ -3,6 +3,8 @@ package controller
 import (
 	"errors"
 
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
 	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
 	"github.com/mrehanabbasi/appraisal-system-backend/models"
 	"gorm.io/gorm"
@@ -18,7 +20,7 @@ func CreateAppraisal(db *gorm.DB, appraisal *models.Appraisal) (*models.Appraisa
 		err := db.Model(&models.Kpi{}).First(&k, kpi.KpiID).Error
 		if err != nil {
 			log.Error(err.Error())
-			return appraisal, errors.New("kpi id does not exist")
+			return appraisal, apperrors.Invalid("kpi id does not exist")
 		}
 	}
 
@@ -30,7 +32,7 @@ func CreateAppraisal(db *gorm.DB, appraisal *models.Appraisal) (*models.Appraisa
 	}
 	if count > 0 {
 		log.Error("appraisal name already exists")
-		return nil, errors.New("appraisal name already exists")
+		return nil, apperrors.Conflict("appraisal name already exists")
 	}
 
 	if err := db.Create(&appraisal).Error; err != nil {
@@ -49,7 +51,7 @@ func GetAppraisalByID(db *gorm.DB, appraisal *models.Appraisal, id uint64) error
 		return err
 	}
 
-	return nil
+	return AttachKpiRatingScales(db, appraisal.AppraisalKpis)
 
 }
 func GetAppraisalKpisByEmpID(db *gorm.DB, appraisalKpi *[]models.AppraisalKpi, id uint64) error {
@@ -69,7 +71,7 @@ func GetAppraisalKpisByEmpID(db *gorm.DB, appraisalKpi *[]models.AppraisalKpi, i
 		return gorm.ErrRecordNotFound
 	}
 
-	return nil
+	return AttachKpiRatingScales(db, *appraisalKpi)
 }
 
 func GetEmployeeDataByAppraisalID(db *gorm.DB, employeeData *[]models.EmployeeData, id uint64) error { // Change the parameter to a pointer to a slice
@@ -100,93 +102,130 @@ func GetAllAppraisals(db *gorm.DB, appraisal *[]models.Appraisal) (err error) {
 		return err
 	}
 
+	for k := range *appraisal {
+		if err := AttachKpiRatingScales(db, (*appraisal)[k].AppraisalKpis); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// UpdateAppraisal replaces the appraisal and its employees and kpis in a single
+// transaction. The results of the employees are left as they are.
 func UpdateAppraisal(db *gorm.DB, appraisal *models.Appraisal) (*models.Appraisal, error) {
 	log.Info("Updating appraisal")
 
-	// Check if appraisal exists in the database
-	var existingAppraisal models.Appraisal
-	if err := db.Model(&models.Appraisal{}).First(&existingAppraisal, appraisal.ID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error("appraisal with the given id not found")
-			return nil, errors.New("appraisal not found")
-		}
-		log.Error(err.Error())
-		return nil, err
-	}
-	// Check if KPI IDs exist in KPIs table
-	for _, kpi := range appraisal.AppraisalKpis {
-		var k models.Kpi
-		err := db.Model(&models.Kpi{}).First(&k, kpi.KpiID).Error
-		if err != nil {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Check if appraisal exists in the database
+		var existingAppraisal models.Appraisal
+		if err := tx.Model(&models.Appraisal{}).First(&existingAppraisal, appraisal.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Error("appraisal with the given id not found")
+				return apperrors.NotFound("appraisal not found")
+			}
 			log.Error(err.Error())
-			return appraisal, errors.New("kpi id does not exist")
+			return err
 		}
-	}
-
-	// Check if appraisal name already exists
-	var count int64
-	if err := db.Model(&models.Appraisal{}).Where("appraisal_name = ? AND id != ?", appraisal.AppraisalName, appraisal.ID).Count(&count).Error; err != nil {
-		log.Error(err.Error())
-		return nil, err
-	}
-	if count > 0 {
-		log.Error("appraisal name already exists")
-		return nil, errors.New("appraisal name already exists")
-	}
-	// Retrieve AppraisalKpis for the existing Appraisal
-	var existingAppraisalKpis []models.AppraisalKpi
-	if err := db.Model(&models.AppraisalKpi{}).Find(&existingAppraisalKpis, "appraisal_id = ?", appraisal.ID).Error; err != nil {
-		log.Error(err.Error())
-		return nil, err
-	}
-
-	// Delete remaining AppraisalKpis if the number of KPIs is reduced
-	if len(existingAppraisalKpis) > len(appraisal.AppraisalKpis) {
-		deletedAppraisalKpis := existingAppraisalKpis[len(appraisal.AppraisalKpis):]
-		for _, appraisalKpi := range deletedAppraisalKpis {
-			if err := db.Delete(&appraisalKpi).Error; err != nil {
+		// Appraisals stay in the campaign that created them
+		appraisal.CampaignID = existingAppraisal.CampaignID
+
+		// Check if KPI IDs exist in KPIs table
+		for _, kpi := range appraisal.AppraisalKpis {
+			var k models.Kpi
+			err := tx.Model(&models.Kpi{}).First(&k, kpi.KpiID).Error
+			if err != nil {
 				log.Error(err.Error())
-				return nil, err
+				return apperrors.Invalid("kpi id does not exist")
 			}
 		}
-	}
 
-	// Assign AppraisalKpis' IDs to the request AppraisalKpis
-	for k := range appraisal.AppraisalKpis {
-		if k < len(existingAppraisalKpis) {
-			appraisal.AppraisalKpis[k].ID = existingAppraisalKpis[k].ID
+		// Check if appraisal name already exists
+		var count int64
+		if err := tx.Model(&models.Appraisal{}).Where("appraisal_name = ? AND id != ?", appraisal.AppraisalName, appraisal.ID).Count(&count).Error; err != nil {
+			log.Error(err.Error())
+			return err
+		}
+		if count > 0 {
+			log.Error("appraisal name already exists")
+			return apperrors.Conflict("appraisal name already exists")
+		}
+		// Retrieve AppraisalKpis for the existing Appraisal
+		var existingAppraisalKpis []models.AppraisalKpi
+		if err := tx.Model(&models.AppraisalKpi{}).Find(&existingAppraisalKpis, "appraisal_id = ?", appraisal.ID).Error; err != nil {
+			log.Error(err.Error())
+			return err
 		}
-	}
 
-	// Retrieve existing EmployeeData for the existing Appraisal
-	var existingEmployeeData []models.EmployeeData
-	if err := db.Model(&models.EmployeeData{}).Find(&existingEmployeeData, "appraisal_id = ?", appraisal.ID).Error; err != nil {
-		log.Error(err.Error())
-		return nil, err
-	}
+		// Match the requested kpis with the existing ones by employee and kpi, and
+		// withdraw the ones left out. Only the kpis of the employees of the request
+		// are withdrawn, since the employees left out of it stay in the appraisal.
+		submittedEmployees := make(map[uint16]bool, len(appraisal.EmployeesList))
+		for _, employeeData := range appraisal.EmployeesList {
+			submittedEmployees[employeeData.TossEmpID] = true
+		}
+		existingKpiIDs := make(map[[2]uint16]uint16, len(existingAppraisalKpis))
+		for _, appraisalKpi := range existingAppraisalKpis {
+			if submittedEmployees[appraisalKpi.EmployeeID] {
+				existingKpiIDs[[2]uint16{appraisalKpi.EmployeeID, appraisalKpi.KpiID}] = appraisalKpi.ID
+			}
+		}
+		for k := range appraisal.AppraisalKpis {
+			key := [2]uint16{appraisal.AppraisalKpis[k].EmployeeID, appraisal.AppraisalKpis[k].KpiID}
+			appraisal.AppraisalKpis[k].ID = existingKpiIDs[key]
+			delete(existingKpiIDs, key)
+		}
+
+		withdrawnKpiIDs := make([]uint16, 0, len(existingKpiIDs))
+		for _, id := range existingKpiIDs {
+			withdrawnKpiIDs = append(withdrawnKpiIDs, id)
+		}
+		if len(withdrawnKpiIDs) > 0 {
+			// Scored kpis are kept, so that no score is left without its kpi
+			var scored int64
+			if err := tx.Model(&models.Score{}).Where("appraisal_kpi_id IN ?", withdrawnKpiIDs).Count(&scored).Error; err != nil {
+				log.Error(err.Error())
+				return err
+			}
+			if scored > 0 {
+				log.Error("scored appraisal kpis cannot be withdrawn")
+				return apperrors.Conflict("scored appraisal kpis cannot be withdrawn")
+			}
 
-	// Delete remaining EmployeeData if the number of employees is reduced
-	if len(existingEmployeeData) > len(appraisal.EmployeesList) {
-		deletedEmployeeData := existingEmployeeData[len(appraisal.EmployeesList):]
-		for _, employeeData := range deletedEmployeeData {
-			if err := db.Delete(&employeeData).Error; err != nil {
+			if err := tx.Delete(&models.AppraisalKpi{}, withdrawnKpiIDs).Error; err != nil {
 				log.Error(err.Error())
-				return nil, err
+				return err
 			}
 		}
-	}
 
-	// Assign EmployeeData IDs to the request EmployeeData
-	for i := range appraisal.EmployeesList {
-		if i < len(existingEmployeeData) {
-			appraisal.EmployeesList[i].ID = existingEmployeeData[i].ID
+		// Retrieve existing EmployeeData for the existing Appraisal
+		var existingEmployeeData []models.EmployeeData
+		if err := tx.Model(&models.EmployeeData{}).Find(&existingEmployeeData, "appraisal_id = ?", appraisal.ID).Error; err != nil {
+			log.Error(err.Error())
+			return err
 		}
-	}
 
-	err := db.Session(&gorm.Session{FullSaveAssociations: true}).Where("id = ?", appraisal.ID).Save(&appraisal).Error
+		// Match the requested employees with the existing ones by employee ID. The
+		// employees left out stay: joiners and leavers are reconciled against the
+		// team roster instead. Transfers are kept as well.
+		existingEmployees := make(map[uint16]models.EmployeeData, len(existingEmployeeData))
+		for _, employeeData := range existingEmployeeData {
+			existingEmployees[employeeData.TossEmpID] = employeeData
+		}
+		for i := range appraisal.EmployeesList {
+			existing := existingEmployees[appraisal.EmployeesList[i].TossEmpID]
+			appraisal.EmployeesList[i].ID = existing.ID
+			appraisal.EmployeesList[i].SupervisorID = existing.SupervisorID
+			appraisal.EmployeesList[i].SupervisorName = existing.SupervisorName
+			// Edits leave the results of the employees as they are
+			appraisal.EmployeesList[i].AppraisalStatus = existing.AppraisalStatus
+			if existing.ID == 0 {
+				appraisal.EmployeesList[i].AppraisalStatus = constants.APPRAISAL_STATUS_PENDING
+			}
+		}
+
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Where("id = ?", appraisal.ID).Save(&appraisal).Error
+	})
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
@@ -209,13 +248,62 @@ func DeleteAppraisal(db *gorm.DB, appraisal *models.Appraisal, id uint64) error
 
 //Create scores
 
-func AddScore(db *gorm.DB, score []models.Score) ([]models.Score, error) {
+func AddScore(db *gorm.DB, score []models.Score, appraisalID, empID uint16) ([]models.Score, error) {
 	log.Info("Creating Score in db...")
 
-	if err := db.Preload(clause.Associations).Create(&score).Error; err != nil {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload(clause.Associations).Create(&score).Error; err != nil {
+			return err
+		}
+
+		// Record one history entry per evaluator of the submitted scores
+		recorded := make(map[[2]uint16]bool)
+		for _, s := range score {
+			key := [2]uint16{s.EvaluatorID, s.OnBehalfOf}
+			if recorded[key] {
+				continue
+			}
+			recorded[key] = true
+
+			err := RecordHistory(tx, &models.AppraisalHistory{
+				AppraisalID: appraisalID,
+				EmployeeID:  empID,
+				Action:      constants.HISTORY_ACTION_SCORED,
+				ActorID:     s.EvaluatorID,
+				OnBehalfOf:  s.OnBehalfOf,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
 
 	return score, nil
 }
+
+func GetScoresByEmpID(db *gorm.DB, scores *[]models.Score, appraisalID, empID uint64) error {
+	log.Info("Getting scores by employee id")
+
+	var count int64
+	err := db.Model(&models.Score{}).
+		Preload("AppraisalKpi.Kpi").
+		Joins("JOIN appraisal_kpis ON appraisal_kpis.id = scores.appraisal_kpi_id").
+		Where("appraisal_kpis.appraisal_id = ? AND appraisal_kpis.employee_id = ?", appraisalID, empID).
+		Order("scores.id ASC").
+		Find(scores).Count(&count).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"errors"
 
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
 	"github.com/mrehanabbasi/appraisal-system-backend/models"
 	"gorm.io/gorm"
@@ -19,7 +20,7 @@ func CreateAppraisalFlow(db *gorm.DB, appraisalFlow *models.AppraisalFlow) (*mod
 	}
 	if count > 0 {
 		log.Error("appraisal flow name already exists")
-		return nil, errors.New("flow name already exists")
+		return nil, apperrors.Conflict("flow name already exists")
 	}
 
 	// check uniqueness of step names for current request
@@ -27,7 +28,7 @@ func CreateAppraisalFlow(db *gorm.DB, appraisalFlow *models.AppraisalFlow) (*mod
 	for _, flow := range appraisalFlow.FlowSteps {
 		if contains(stepNames, flow.StepName) {
 			log.Error("flow step name already exists in the same request")
-			return nil, errors.New("step name already exists")
+			return nil, apperrors.Conflict("step name already exists")
 		}
 		stepNames = append(stepNames, flow.StepName)
 	}
@@ -84,7 +85,7 @@ func UpdateAppraisalFlow(db *gorm.DB, appraisalFlow *models.AppraisalFlow) error
 	if err := db.Model(&models.AppraisalFlow{}).First(&existingAppraisalFlow, appraisalFlow.ID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			log.Error("Appraisal flow with the given id not found")
-			return errors.New("appraisal flow not found")
+			return apperrors.NotFound("appraisal flow not found")
 		}
 		log.Error(err.Error())
 		return err
@@ -98,7 +99,7 @@ func UpdateAppraisalFlow(db *gorm.DB, appraisalFlow *models.AppraisalFlow) error
 	}
 	if count > 0 {
 		log.Error("appraisal flow name already exists")
-		return errors.New("flow name already exists")
+		return apperrors.Conflict("flow name already exists")
 	}
 
 	// check uniqueness of step names for current request
@@ -106,7 +107,7 @@ func UpdateAppraisalFlow(db *gorm.DB, appraisalFlow *models.AppraisalFlow) error
 	for _, flow := range appraisalFlow.FlowSteps {
 		if contains(stepNames, flow.StepName) {
 			log.Error("flow step name already exists in the same request")
-			return errors.New("step name already exists")
+			return apperrors.Conflict("step name already exists")
 		}
 		stepNames = append(stepNames, flow.StepName)
 	}
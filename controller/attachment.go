@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/storage"
+	"gorm.io/gorm"
+)
+
+// IsAppraisalMember reports whether the user takes part in the appraisal of the
+// employee: the employee themselves, the supervisor of the appraisal or the one
+// the employee was transferred to, a previous supervisor still scoring the
+// employee, a user of its flow, or an active delegate of any of them
+func IsAppraisalMember(db *gorm.DB, appraisalID, employeeID, userID uint16) (bool, error) {
+	if userID == employeeID {
+		return true, nil
+	}
+
+	var appraisal models.Appraisal
+	if err := db.Model(&models.Appraisal{}).First(&appraisal, appraisalID).Error; err != nil {
+		log.Error(err.Error())
+		return false, err
+	}
+
+	var evaluatorIDs []uint16
+	err := db.Model(&models.FlowStep{}).Where("flow_id = ?", appraisal.AppraisalFlowID).Pluck("user_id", &evaluatorIDs).Error
+	if err != nil {
+		log.Error(err.Error())
+		return false, err
+	}
+	evaluatorIDs = append(evaluatorIDs, appraisal.SupervisorID)
+
+	var employee models.EmployeeData
+	err = db.Model(&models.EmployeeData{}).
+		Where("appraisal_id = ? AND toss_emp_id = ?", appraisalID, employeeID).
+		Limit(1).Find(&employee).Error
+	if err != nil {
+		log.Error(err.Error())
+		return false, err
+	}
+	if employee.SupervisorID != 0 {
+		evaluatorIDs = append(evaluatorIDs, employee.SupervisorID)
+	}
+
+	splitSupervisorIDs, err := GetSplitSupervisors(db, appraisalID, employeeID)
+	if err != nil {
+		return false, err
+	}
+	evaluatorIDs = append(evaluatorIDs, splitSupervisorIDs...)
+
+	for _, evaluatorID := range evaluatorIDs {
+		if evaluatorID == userID {
+			return true, nil
+		}
+	}
+
+	delegatorIDs, err := GetActiveDelegators(db, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, delegatorID := range delegatorIDs {
+		for _, evaluatorID := range evaluatorIDs {
+			if delegatorID == evaluatorID {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// GetAttachableKpi returns the appraisal kpi evidence is attached to. Evidence is
+// only attached to measured and observatory kpis.
+func GetAttachableKpi(db *gorm.DB, appraisalKpiID uint64) (models.AppraisalKpi, error) {
+	var appraisalKpi models.AppraisalKpi
+	if err := db.Model(&models.AppraisalKpi{}).Preload("Kpi").First(&appraisalKpi, appraisalKpiID).Error; err != nil {
+		log.Error(err.Error())
+		return appraisalKpi, err
+	}
+
+	kpiType := appraisalKpi.Kpi.KpiTypeStr
+	if kpiType != constants.MEASURED_KPI_TYPE && kpiType != constants.OBSERVATORY_KPI_TYPE {
+		log.Error("evidence attached to a kpi that is not measured or observatory")
+		return appraisalKpi, apperrors.Invalid(fmt.Sprintf("evidence cannot be attached to %s kpis", kpiType))
+	}
+
+	return appraisalKpi, nil
+}
+
+// CreateAttachment scans the file, puts it in the storage and stores its record.
+// The file is removed from the storage again if the record cannot be stored.
+func CreateAttachment(ctx context.Context, db *gorm.DB, attachment *models.Attachment, data []byte) (*models.Attachment, error) {
+	log.Info("Creating attachment")
+
+	if err := storage.FileScanner.Scan(ctx, attachment.FileName, data); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	attachment.Checksum = hex.EncodeToString(sum[:])
+	attachment.Size = int64(len(data))
+	attachment.StorageKey = fmt.Sprintf("appraisals/%d/employees/%d/kpis/%d/%d-%s",
+		attachment.AppraisalID, attachment.EmployeeID, attachment.AppraisalKpiID, time.Now().UnixNano(), attachment.Checksum[:16])
+
+	if err := storage.Store.Put(ctx, attachment.StorageKey, data, attachment.ContentType); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	if err := db.Create(attachment).Error; err != nil {
+		log.Error(err.Error())
+		if err := storage.Store.Delete(ctx, attachment.StorageKey); err != nil {
+			log.Error(err.Error())
+		}
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func GetAttachmentByID(db *gorm.DB, attachment *models.Attachment, id uint64) error {
+	log.Info("Getting attachment by ID")
+
+	if err := db.Model(&models.Attachment{}).Where("id = ?", id).First(attachment).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetAttachmentsByKpiID(db *gorm.DB, attachments *[]models.Attachment, appraisalKpiID uint64) error {
+	log.Info("Getting attachments by appraisal kpi ID")
+
+	err := db.Model(&models.Attachment{}).Where("appraisal_kpi_id = ?", appraisalKpiID).Order("id ASC").Find(attachments).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// OpenAttachment opens the file of the attachment in the storage
+func OpenAttachment(ctx context.Context, attachment *models.Attachment) (io.ReadCloser, error) {
+	file, err := storage.Store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		log.Error(err.Error())
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// DeleteAttachment removes the record of the attachment and its file
+func DeleteAttachment(ctx context.Context, db *gorm.DB, attachment *models.Attachment) error {
+	log.Info("Deleting attachment")
+
+	if err := db.Delete(attachment).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	if err := storage.Store.Delete(ctx, attachment.StorageKey); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateCalibrationSession(db *gorm.DB, session *models.CalibrationSession) (*models.CalibrationSession, error) {
+	log.Info("Creating calibration session")
+
+	// Check if session name already exists
+	var count int64
+	if err := db.Model(&models.CalibrationSession{}).Where("session_name = ?", session.SessionName).Count(&count).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if count > 0 {
+		log.Error("calibration session name already exists")
+		return nil, apperrors.Conflict("session name already exists")
+	}
+
+	if err := checkAppraisalIDs(db, session.AppraisalIDs); err != nil {
+		return nil, err
+	}
+
+	if err := db.Create(session).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func GetCalibrationSessionByID(db *gorm.DB, session *models.CalibrationSession, id uint64) error {
+	log.Info("Getting calibration session by ID")
+
+	err := db.Model(&models.CalibrationSession{}).Preload("Adjustments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("id = ?", id).First(session).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetAllCalibrationSessions(db *gorm.DB, sessions *[]models.CalibrationSession) error {
+	log.Info("Getting all calibration sessions")
+
+	err := db.Model(&models.CalibrationSession{}).Order("id ASC").Find(sessions).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// UpdateCalibrationSession updates an open calibration session. Closed sessions
+// are final, and the session stays open or closed unless is_closed is set.
+func UpdateCalibrationSession(db *gorm.DB, session *models.CalibrationSession) (*models.CalibrationSession, error) {
+	log.Info("Updating calibration session")
+
+	// Check if calibration session exists in the database
+	var existingSession models.CalibrationSession
+	if err := db.Model(&models.CalibrationSession{}).First(&existingSession, session.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Error("calibration session with the given id not found")
+			return nil, apperrors.NotFound("calibration session not found")
+		}
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	if existingSession.IsClosed != nil && *existingSession.IsClosed {
+		log.Error("calibration session is closed")
+		return nil, apperrors.Conflict("calibration session is closed")
+	}
+
+	// Check if session name already exists
+	var count int64
+	if err := db.Model(&models.CalibrationSession{}).Where("session_name = ? AND id != ?", session.SessionName, session.ID).Count(&count).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if count > 0 {
+		log.Error("calibration session name already exists")
+		return nil, apperrors.Conflict("session name already exists")
+	}
+
+	if err := checkAppraisalIDs(db, session.AppraisalIDs); err != nil {
+		return nil, err
+	}
+
+	session.CreatedBy = existingSession.CreatedBy
+	if session.IsClosed == nil {
+		session.IsClosed = existingSession.IsClosed
+	}
+	if err := db.Omit(clause.Associations).Where("id = ?", session.ID).Save(session).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func DeleteCalibrationSession(db *gorm.DB, session *models.CalibrationSession) error {
+	log.Info("Deleting calibration session")
+
+	// Adjustments stay in effect; only the session grouping is removed
+	err := db.Delete(session).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// calibrationResultsQuery narrows the employee results down to the appraisals and
+// teams grouped by the session
+func calibrationResultsQuery(db *gorm.DB, session *models.CalibrationSession) *gorm.DB {
+	query := EmployeeResultsQuery(db).Where("employee_data.appraisal_id IN ?", []int64(session.AppraisalIDs))
+	if len(session.TeamIDs) > 0 {
+		query = query.Where("employee_data.team_id IN ?", []int64(session.TeamIDs))
+	}
+	return query
+}
+
+func GetCalibrationResults(db *gorm.DB, session *models.CalibrationSession, results *[]models.EmployeeResult) error {
+	log.Info("Getting calibration session results")
+
+	err := calibrationResultsQuery(db, session).
+		Order(employeeSupervisorIDColumn + " ASC, employee_data.toss_emp_id ASC").
+		Scan(results).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return AttachResultLabels(db, *results)
+}
+
+// GetSupervisorDistribution aggregates the computed scores of the session per
+// supervisor, so that lenient and strict raters stand out before calibration.
+func GetSupervisorDistribution(db *gorm.DB, session *models.CalibrationSession, distribution *[]models.SupervisorDistribution) error {
+	log.Info("Getting supervisor score distribution")
+
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table("(?) AS results", calibrationResultsQuery(db, session)).
+		Select(`results.supervisor_id, MAX(results.supervisor_name) AS supervisor_name,
+			COUNT(*) AS employee_count, COUNT(results.computed_score) AS scored_count,
+			AVG(results.computed_score) AS average_score, MIN(results.computed_score) AS min_score,
+			MAX(results.computed_score) AS max_score, STDDEV_POP(results.computed_score) AS std_dev_score,
+			AVG(results.final_score) AS average_final, COUNT(*) FILTER (WHERE results.adjusted) AS adjusted_count`).
+		Group("results.supervisor_id").
+		Order("results.supervisor_id ASC").
+		Scan(distribution).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// AdjustCalibratedScore records a calibration adjustment for an employee that
+// belongs to the session
+func AdjustCalibratedScore(db *gorm.DB, session *models.CalibrationSession, adjustment *models.ScoreAdjustment) (*models.ScoreAdjustment, error) {
+	log.Info("Adjusting calibrated score")
+
+	if session.IsClosed != nil && *session.IsClosed {
+		log.Error("calibration session is closed")
+		return nil, apperrors.Conflict("calibration session is closed")
+	}
+
+	var count int64
+	err := calibrationResultsQuery(db, session).
+		Where("employee_data.appraisal_id = ? AND employee_data.toss_emp_id = ?", adjustment.AppraisalID, adjustment.EmployeeID).
+		Count(&count).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if count == 0 {
+		log.Error("employee is not part of the calibration session")
+		return nil, apperrors.Invalid("employee is not part of the calibration session")
+	}
+
+	adjustment.CalibrationSessionID = &session.ID
+	adjustment.Source = "calibration"
+
+	return CreateScoreAdjustment(db, adjustment)
+}
+
+// CreateScoreAdjustment stores an adjustment of the final score of an employee.
+// The final score in effect before the adjustment is kept as the previous score.
+func CreateScoreAdjustment(db *gorm.DB, adjustment *models.ScoreAdjustment) (*models.ScoreAdjustment, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result, err := GetEmployeeResult(tx, uint64(adjustment.AppraisalID), uint64(adjustment.EmployeeID))
+		if err != nil {
+			return err
+		}
+
+		adjustment.PreviousScore = result.FinalScore
+		adjustment.AdjustedAt = time.Now()
+
+		if err := tx.Create(adjustment).Error; err != nil {
+			return err
+		}
+
+		return RecordHistory(tx, &models.AppraisalHistory{
+			AppraisalID: adjustment.AppraisalID,
+			EmployeeID:  adjustment.EmployeeID,
+			Action:      constants.HISTORY_ACTION_SCORE_ADJUSTED,
+			ActorID:     adjustment.AdjustedBy,
+			Details:     adjustment.Source,
+		})
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// checkAppraisalIDs makes sure every appraisal grouped by a session exists
+func checkAppraisalIDs(db *gorm.DB, appraisalIDs []int64) error {
+	var count int64
+	if err := db.Model(&models.Appraisal{}).Where("id IN ?", appraisalIDs).Count(&count).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if int(count) != len(appraisalIDs) {
+		log.Error("invalid appraisal ids in the calibration session")
+		return apperrors.Invalid("invalid appraisal ids")
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// CreateCampaign creates the campaign along with the appraisals of its teams in
+// a single transaction, so a campaign is never left half created
+func CreateCampaign(db *gorm.DB, campaign *models.Campaign) (*models.Campaign, error) {
+	log.Info("Creating campaign")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Campaign{}).Where("campaign_name = ?", campaign.CampaignName).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.Conflict("campaign name already exists")
+		}
+
+		appraisalNames := make([]string, 0, len(campaign.Appraisals))
+		for _, appraisal := range campaign.Appraisals {
+			appraisalNames = append(appraisalNames, appraisal.AppraisalName)
+		}
+		var existingNames []string
+		err := tx.Model(&models.Appraisal{}).Where("appraisal_name IN ?", appraisalNames).Pluck("appraisal_name", &existingNames).Error
+		if err != nil {
+			return err
+		}
+		if len(existingNames) > 0 {
+			return apperrors.Conflict(fmt.Sprintf("appraisal name already exists: %s", existingNames[0]))
+		}
+
+		return tx.Create(campaign).Error
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func GetAllCampaigns(db *gorm.DB, campaigns *[]models.Campaign) error {
+	log.Info("Getting all campaigns")
+
+	err := db.Preload("SkippedTeams").Order("id DESC").Find(campaigns).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetCampaignByID(db *gorm.DB, campaign *models.Campaign, id uint64) error {
+	log.Info("Getting campaign by ID")
+
+	err := db.Model(&models.Campaign{}).
+		Preload("Appraisals", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("Appraisals.EmployeesList").
+		Preload("SkippedTeams").
+		Where("id = ?", id).First(campaign).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// CreateComment stores a comment on an appraisal kpi or a score. A reply belongs
+// to the same kpi or score as its parent and is never more visible than it.
+func CreateComment(db *gorm.DB, comment *models.Comment) (*models.Comment, error) {
+	log.Info("Creating comment")
+
+	if comment.ParentID != nil {
+		var parent models.Comment
+		if err := db.Model(&models.Comment{}).First(&parent, *comment.ParentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Error("parent comment not found")
+				return nil, apperrors.NotFound("parent comment not found")
+			}
+			log.Error(err.Error())
+			return nil, err
+		}
+
+		comment.AppraisalKpiID = parent.AppraisalKpiID
+		comment.ScoreID = parent.ScoreID
+		if parent.Visibility == constants.COMMENT_VISIBILITY_HR_PRIVATE {
+			comment.Visibility = constants.COMMENT_VISIBILITY_HR_PRIVATE
+		}
+	}
+
+	if err := ResolveCommentTarget(db, comment); err != nil {
+		return nil, err
+	}
+
+	if err := db.Create(comment).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// ResolveCommentTarget fills the appraisal and employee of the kpi or score the
+// comment is on
+func ResolveCommentTarget(db *gorm.DB, comment *models.Comment) error {
+	if (comment.AppraisalKpiID == nil) == (comment.ScoreID == nil) {
+		log.Error("comment target is ambiguous")
+		return apperrors.Invalid("either appraisal_kpi_id or score_id is required")
+	}
+
+	appraisalKpiID := comment.AppraisalKpiID
+	if comment.ScoreID != nil {
+		var score models.Score
+		if err := db.Model(&models.Score{}).First(&score, *comment.ScoreID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Error("score not found")
+				return apperrors.NotFound("score not found")
+			}
+			log.Error(err.Error())
+			return err
+		}
+		appraisalKpiID = &score.AppraisalKpiID
+	}
+
+	var appraisalKpi models.AppraisalKpi
+	if err := db.Model(&models.AppraisalKpi{}).First(&appraisalKpi, *appraisalKpiID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Error("appraisal kpi not found")
+			return apperrors.NotFound("appraisal kpi not found")
+		}
+		log.Error(err.Error())
+		return err
+	}
+
+	comment.AppraisalID = appraisalKpi.AppraisalID
+	comment.EmployeeID = appraisalKpi.EmployeeID
+	return nil
+}
+
+// GetComments returns the comments selected by the query as threads: the top
+// level comments with their replies nested under them
+func GetComments(db *gorm.DB, comments *[]models.Comment) error {
+	log.Info("Getting comments")
+
+	var flat []models.Comment
+	if err := db.Order("id ASC").Find(&flat).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	*comments = BuildCommentThreads(flat)
+	return nil
+}
+
+// BuildCommentThreads nests the replies under their parents. Replies whose parent
+// is not in the list are shown at the top level.
+func BuildCommentThreads(comments []models.Comment) []models.Comment {
+	byID := make(map[uint16]int, len(comments))
+	for k := range comments {
+		byID[comments[k].ID] = k
+	}
+
+	children := make(map[uint16][]uint16)
+	roots := make([]uint16, 0)
+	for _, comment := range comments {
+		if comment.ParentID != nil {
+			if _, ok := byID[*comment.ParentID]; ok {
+				children[*comment.ParentID] = append(children[*comment.ParentID], comment.ID)
+				continue
+			}
+		}
+		roots = append(roots, comment.ID)
+	}
+
+	var build func(id uint16) models.Comment
+	build = func(id uint16) models.Comment {
+		comment := comments[byID[id]]
+		for _, childID := range children[id] {
+			comment.Replies = append(comment.Replies, build(childID))
+		}
+		return comment
+	}
+
+	threads := make([]models.Comment, 0, len(roots))
+	for _, id := range roots {
+		threads = append(threads, build(id))
+	}
+	return threads
+}
+
+func GetCommentByID(db *gorm.DB, comment *models.Comment, id uint64) error {
+	log.Info("Getting comment by ID")
+
+	err := db.Model(&models.Comment{}).Preload("Revisions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("edited_at ASC, id ASC")
+	}).Where("id = ?", id).First(comment).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// EditComment changes the body of a comment, keeping the previous body as a
+// revision. Only the author edits their comment.
+func EditComment(db *gorm.DB, comment *models.Comment, edit *models.CommentEdit) (*models.Comment, error) {
+	log.Info("Editing comment")
+
+	if comment.AuthorID != edit.AuthorID {
+		log.Error("comment edited by someone other than the author")
+		return nil, apperrors.Forbidden("only the author can edit the comment")
+	}
+
+	revision := models.CommentRevision{
+		CommentID: comment.ID,
+		Body:      comment.Body,
+		EditedAt:  time.Now(),
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(comment).Updates(map[string]interface{}{
+			"body":     edit.Body,
+			"mentions": pq.Int64Array(edit.Mentions),
+			"edited":   true,
+		}).Error
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	comment.Body = edit.Body
+	comment.Mentions = edit.Mentions
+	comment.Edited = true
+	comment.Revisions = append(comment.Revisions, revision)
+	return comment, nil
+}
+
+func DeleteComment(db *gorm.DB, comment *models.Comment) error {
+	log.Info("Deleting comment")
+
+	if err := db.Delete(comment).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetMentions returns the comments mentioning the employee. Comments private to HR
+// are left out unless includePrivate is set.
+func GetMentions(db *gorm.DB, comments *[]models.Comment, empID uint16, includePrivate bool) error {
+	log.Info("Getting comment mentions")
+
+	query := db.Model(&models.Comment{}).Where("? = ANY(mentions)", empID)
+	if !includePrivate {
+		query = query.Where("visibility = ?", constants.COMMENT_VISIBILITY_SHARED)
+	}
+
+	if err := query.Order("id DESC").Find(comments).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
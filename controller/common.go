@@ -1,22 +1,40 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
 )
 
 func ErrValidationSlice(err error) ([]string, bool) {
 	errs := []string{}
-	ve, ok := err.(validator.ValidationErrors)
+	fields, ok := ErrValidationFields(err)
+	for _, field := range fields {
+		errs = append(errs, field.Message)
+	}
+
+	return errs, ok
+}
+
+// ErrValidationFields returns the messages of ErrValidationSlice with the field
+// and the validation tag each of them is about
+func ErrValidationFields(err error) ([]models.FieldError, bool) {
+	fields := []models.FieldError{}
+	var ve validator.ValidationErrors
+	ok := errors.As(err, &ve)
 	if ok {
 		for _, fe := range ve {
-			errStr := errMsgFromValidator(fe.Field(), fe.Tag(), fe.Param())
-			errs = append(errs, errStr)
+			fields = append(fields, models.FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Message: errMsgFromValidator(fe.Field(), fe.Tag(), fe.Param()),
+			})
 		}
 	}
 
-	return errs, ok
+	return fields, ok
 }
 
 func errMsgFromValidator(field, tag, value string) string {
@@ -31,6 +49,10 @@ func errMsgFromValidator(field, tag, value string) string {
 		return fmt.Sprintf("maximum %v characters allowed for %s", value, field)
 	case "gte":
 		return fmt.Sprintf("%s should be greater or equal to %v", field, value)
+	case "lte":
+		return fmt.Sprintf("%s should be less or equal to %v", field, value)
+	case "oneof":
+		return fmt.Sprintf("%s should be one of [%v]", field, value)
 	}
 	return tag
 }
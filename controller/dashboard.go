@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// scoredCondition is true for an appraisal kpi with at least one score
+const scoredCondition = "EXISTS (SELECT 1 FROM scores WHERE scores.appraisal_kpi_id = appraisal_kpis.id AND scores.deleted_at IS NULL)"
+
+// cycleAppraisalKpisQuery selects the appraisal kpis of the appraisals of a cycle
+// along with the employee data of the employee they are assigned to
+func cycleAppraisalKpisQuery(db *gorm.DB, appraisalYear uint16, appraisalType string) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Table("appraisal_kpis").
+		Joins("JOIN appraisals ON appraisals.id = appraisal_kpis.appraisal_id AND appraisals.deleted_at IS NULL").
+		Joins(`JOIN employee_data ON employee_data.appraisal_id = appraisal_kpis.appraisal_id
+			AND employee_data.toss_emp_id = appraisal_kpis.employee_id AND employee_data.deleted_at IS NULL`).
+		Where("appraisal_kpis.deleted_at IS NULL AND appraisals.appraisal_year = ? AND appraisals.appraisal_type_str = ?", appraisalYear, appraisalType)
+}
+
+func GetCycleDashboard(db *gorm.DB, appraisalYear uint16, appraisalType string) (models.CycleDashboard, error) {
+	log.Info("Getting appraisal cycle dashboard")
+
+	dashboard := models.CycleDashboard{
+		AppraisalYear:     appraisalYear,
+		AppraisalType:     appraisalType,
+		StatusCounts:      make([]models.StatusCount, 0),
+		OverdueEvaluators: make([]models.OverdueEvaluator, 0),
+	}
+
+	err := db.Model(&models.Appraisal{}).
+		Where("appraisal_year = ? AND appraisal_type_str = ?", appraisalYear, appraisalType).
+		Count(&dashboard.AppraisalCount).Error
+	if err != nil {
+		log.Error(err.Error())
+		return dashboard, err
+	}
+
+	err = db.Model(&models.EmployeeData{}).
+		Select("employee_data.appraisal_status AS status, COUNT(*) AS count").
+		Joins("JOIN appraisals ON appraisals.id = employee_data.appraisal_id AND appraisals.deleted_at IS NULL").
+		Where("appraisals.appraisal_year = ? AND appraisals.appraisal_type_str = ?", appraisalYear, appraisalType).
+		Group("employee_data.appraisal_status").
+		Order("employee_data.appraisal_status ASC").
+		Scan(&dashboard.StatusCounts).Error
+	if err != nil {
+		log.Error(err.Error())
+		return dashboard, err
+	}
+	for _, statusCount := range dashboard.StatusCounts {
+		dashboard.EmployeeCount += statusCount.Count
+	}
+
+	var kpiCounts struct {
+		TotalKpis  int64
+		ScoredKpis int64
+	}
+	err = cycleAppraisalKpisQuery(db, appraisalYear, appraisalType).
+		Select("COUNT(*) AS total_kpis, COUNT(*) FILTER (WHERE " + scoredCondition + ") AS scored_kpis").
+		Scan(&kpiCounts).Error
+	if err != nil {
+		log.Error(err.Error())
+		return dashboard, err
+	}
+	dashboard.TotalKpis = kpiCounts.TotalKpis
+	dashboard.ScoredKpis = kpiCounts.ScoredKpis
+	dashboard.OutstandingKpis = kpiCounts.TotalKpis - kpiCounts.ScoredKpis
+	dashboard.CompletionPercentage = completionPercentage(kpiCounts.ScoredKpis, kpiCounts.TotalKpis)
+
+	// Questionnaires are answered by the employees, not by the evaluators
+	err = cycleAppraisalKpisQuery(db, appraisalYear, appraisalType).
+		Select(employeeSupervisorIDColumn+` AS evaluator_id, MAX(`+employeeSupervisorNameColumn+`) AS evaluator_name,
+			COUNT(DISTINCT appraisals.id) AS appraisal_count, COUNT(*) AS outstanding_kpis,
+			MIN(appraisals.due_date) AS earliest_due_date`).
+		Joins("JOIN kpis ON kpis.id = appraisal_kpis.kpi_id").
+		Where("kpis.kpi_type_str != ? AND appraisals.due_date < NOW() AND NOT "+scoredCondition, constants.QUESTIONNAIRE_KPI_TYPE).
+		Group(employeeSupervisorIDColumn).
+		Order("earliest_due_date ASC").
+		Scan(&dashboard.OverdueEvaluators).Error
+	if err != nil {
+		log.Error(err.Error())
+		return dashboard, err
+	}
+
+	return dashboard, nil
+}
+
+// GetCompletionStats returns the scoring completion of a cycle per team or per
+// supervisor, depending on groupBy
+func GetCompletionStats(db *gorm.DB, appraisalYear uint16, appraisalType, groupBy string, stats *[]models.CompletionStat) error {
+	log.Info("Getting completion stats")
+
+	groupColumn, nameColumn := "employee_data.team_id", "employee_data.team_name"
+	if groupBy == constants.GROUP_BY_SUPERVISOR {
+		groupColumn, nameColumn = employeeSupervisorIDColumn, employeeSupervisorNameColumn
+	}
+
+	err := cycleAppraisalKpisQuery(db, appraisalYear, appraisalType).
+		Select(groupColumn + ` AS group_id, MAX(` + nameColumn + `) AS group_name,
+			COUNT(DISTINCT employee_data.id) AS employee_count, COUNT(*) AS total_kpis,
+			COUNT(*) FILTER (WHERE ` + scoredCondition + `) AS scored_kpis`).
+		Group(groupColumn).
+		Order(groupColumn + " ASC").
+		Scan(stats).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	for k := range *stats {
+		(*stats)[k].CompletionPercentage = completionPercentage((*stats)[k].ScoredKpis, (*stats)[k].TotalKpis)
+	}
+
+	return nil
+}
+
+func completionPercentage(scored, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(scored) * 100 / float64(total)
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// ActiveDelegationsQuery narrows the query down to the delegations in effect at the
+// given time. Delegations past their end date expire without any update.
+func ActiveDelegationsQuery(db *gorm.DB, at time.Time) *gorm.DB {
+	return db.Model(&models.Delegation{}).
+		Where("is_revoked = ? AND start_date <= ? AND end_date > ?", false, at, at)
+}
+
+func CreateDelegation(db *gorm.DB, delegation *models.Delegation) (*models.Delegation, error) {
+	log.Info("Creating delegation")
+
+	// A delegator can only hand their rights over to one delegate at a time
+	var count int64
+	err := db.Model(&models.Delegation{}).
+		Where("delegator_id = ? AND is_revoked = ? AND start_date < ? AND end_date > ?",
+			delegation.DelegatorID, false, delegation.EndDate, delegation.StartDate).
+		Count(&count).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if count > 0 {
+		log.Error("delegation overlaps an existing delegation")
+		return nil, apperrors.Conflict("delegation overlaps an existing delegation of the delegator")
+	}
+
+	if err := db.Create(delegation).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	delegation.IsActive = delegation.Active(time.Now())
+	return delegation, nil
+}
+
+func GetDelegationByID(db *gorm.DB, delegation *models.Delegation, id uint64) error {
+	log.Info("Getting delegation by ID")
+
+	err := db.Model(&models.Delegation{}).Where("id = ?", id).First(delegation).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	delegation.IsActive = delegation.Active(time.Now())
+	return nil
+}
+
+func GetAllDelegations(db *gorm.DB, delegations *[]models.Delegation) error {
+	log.Info("Getting all delegations")
+
+	err := db.Order("start_date DESC, id DESC").Find(delegations).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	now := time.Now()
+	for k := range *delegations {
+		(*delegations)[k].IsActive = (*delegations)[k].Active(now)
+	}
+
+	return nil
+}
+
+func RevokeDelegation(db *gorm.DB, delegation *models.Delegation) (*models.Delegation, error) {
+	log.Info("Revoking delegation")
+
+	if delegation.IsRevoked != nil && *delegation.IsRevoked {
+		log.Error("delegation is already revoked")
+		return nil, apperrors.Conflict("delegation is already revoked")
+	}
+
+	if err := db.Model(delegation).Update("is_revoked", true).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	isRevoked := true
+	delegation.IsRevoked = &isRevoked
+	delegation.IsActive = false
+	return delegation, nil
+}
+
+// IsActiveDelegate reports whether the delegate currently holds the evaluator
+// rights of the delegator
+func IsActiveDelegate(db *gorm.DB, delegatorID, delegateID uint16) (bool, error) {
+	var count int64
+	err := ActiveDelegationsQuery(db, time.Now()).
+		Where("delegator_id = ? AND delegate_id = ?", delegatorID, delegateID).
+		Count(&count).Error
+	if err != nil {
+		log.Error(err.Error())
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetActiveDelegators returns the users whose evaluator rights are currently
+// delegated to the delegate
+func GetActiveDelegators(db *gorm.DB, delegateID uint16) ([]uint16, error) {
+	var delegatorIDs []uint16
+	err := ActiveDelegationsQuery(db, time.Now()).
+		Where("delegate_id = ?", delegateID).
+		Distinct().Pluck("delegator_id", &delegatorIDs).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return delegatorIDs, nil
+}
@@ -0,0 +1,626 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+// ErrSyncRunning is returned when a sync is requested while another one runs
+var ErrSyncRunning = apperrors.Conflict("a directory sync is already running")
+
+var syncMutex sync.Mutex
+
+// tossDirectory is the TOSS directory as fetched by a sync run
+type tossDirectory struct {
+	designations []utils.DesignationInfo
+	projects     []utils.ProjectResponse
+	employees    map[uint16]utils.EmployeeInfo
+}
+
+// fetchTossDirectory fetches the designations, the projects and the employees
+// from TOSS. The active employees and their designations come from the
+// employees listed per designation.
+func fetchTossDirectory(ctx context.Context) (tossDirectory, error) {
+	var directory tossDirectory
+	var err error
+
+	if directory.designations, err = utils.FetchTossDesignations(ctx); err != nil {
+		return directory, err
+	}
+	if directory.projects, err = utils.FetchTossProjects(ctx); err != nil {
+		return directory, err
+	}
+
+	allEmployees, err := utils.FetchTossEmployees(ctx)
+	if err != nil {
+		return directory, err
+	}
+
+	directory.employees = make(map[uint16]utils.EmployeeInfo, len(allEmployees))
+	for _, employee := range allEmployees {
+		directory.employees[employee.EmployeeID] = employee
+	}
+
+	activeCount := 0
+	for _, designation := range directory.designations {
+		activeEmployees, err := utils.FetchTossActiveEmployeesByDesignation(ctx, designation.Value)
+		if err != nil {
+			return directory, err
+		}
+		for _, employee := range activeEmployees {
+			if employee.Name == "" {
+				employee.Name = directory.employees[employee.EmployeeID].Name
+			}
+			directory.employees[employee.EmployeeID] = employee
+			activeCount++
+		}
+	}
+
+	// Without any employee listed per designation the active state is unknown,
+	// so every employee of TOSS is taken as active
+	if activeCount == 0 {
+		for id, employee := range directory.employees {
+			employee.IsActive = true
+			directory.employees[id] = employee
+		}
+	}
+
+	return directory, nil
+}
+
+// SyncTossDirectory mirrors the TOSS directory into the local tables and records
+// the changes since the previous successful run. The first run only takes the
+// baseline. Only one sync runs at a time. The run is recorded even when ctx is
+// cancelled during the sync.
+func SyncTossDirectory(ctx context.Context, db *gorm.DB) (*models.DirectorySyncRun, error) {
+	log.Info("Syncing TOSS directory")
+
+	if !syncMutex.TryLock() {
+		log.Error(ErrSyncRunning.Error())
+		return nil, ErrSyncRunning
+	}
+	defer syncMutex.Unlock()
+
+	run := models.DirectorySyncRun{StartedAt: time.Now(), Status: constants.SYNC_STATUS_RUNNING}
+	if err := db.Create(&run).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	var baselines int64
+	err := db.Model(&models.DirectorySyncRun{}).Where("status = ?", constants.SYNC_STATUS_SUCCEEDED).Count(&baselines).Error
+	if err == nil {
+		var directory tossDirectory
+		directory, err = fetchTossDirectory(ctx)
+		if err == nil {
+			err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				applier := directorySync{tx: tx, run: &run, recordChanges: baselines > 0}
+				return applier.apply(directory)
+			})
+		}
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = constants.SYNC_STATUS_SUCCEEDED
+	if err != nil {
+		log.Error(err.Error())
+		run.Status = constants.SYNC_STATUS_FAILED
+		run.Error = err.Error()
+		run.ChangeCount = 0
+	}
+
+	if err := db.Save(&run).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// directorySync applies a fetched directory to the local tables of a sync run
+type directorySync struct {
+	tx            *gorm.DB
+	run           *models.DirectorySyncRun
+	recordChanges bool
+}
+
+func (s *directorySync) change(entityType string, entityID uint16, entityName, changeType, oldValue, newValue string) error {
+	if !s.recordChanges {
+		return nil
+	}
+
+	s.run.ChangeCount++
+	return s.tx.Create(&models.DirectoryChange{
+		SyncRunID:  s.run.ID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		EntityName: entityName,
+		ChangeType: changeType,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+		DetectedAt: s.run.StartedAt,
+	}).Error
+}
+
+func (s *directorySync) apply(directory tossDirectory) error {
+	designationNames, err := s.applyDesignations(directory.designations)
+	if err != nil {
+		return err
+	}
+
+	oldTeams, err := s.employeeTeams()
+	if err != nil {
+		return err
+	}
+	if err := s.applyProjects(directory.projects); err != nil {
+		return err
+	}
+	newTeams, err := s.employeeTeams()
+	if err != nil {
+		return err
+	}
+
+	if err := s.applyEmployees(directory.employees, designationNames); err != nil {
+		return err
+	}
+
+	// A team move is a change of the set of projects of an active employee
+	for employeeID, employee := range directory.employees {
+		if !employee.IsActive || oldTeams[employeeID] == newTeams[employeeID] {
+			continue
+		}
+		err := s.change(constants.DIRECTORY_ENTITY_EMPLOYEE, employeeID, employee.Name, constants.DIRECTORY_CHANGE_TEAM_MOVED,
+			oldTeams[employeeID], newTeams[employeeID])
+		if err != nil {
+			return err
+		}
+	}
+
+	s.run.Employees = len(directory.employees)
+	s.run.Projects = len(directory.projects)
+	return nil
+}
+
+func (s *directorySync) applyDesignations(designations []utils.DesignationInfo) (map[uint16]string, error) {
+	var existing []models.DirectoryDesignation
+	if err := s.tx.Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	existingByID := make(map[uint16]models.DirectoryDesignation, len(existing))
+	for _, designation := range existing {
+		existingByID[designation.DesignationID] = designation
+	}
+
+	names := make(map[uint16]string, len(designations))
+	for _, fetched := range designations {
+		names[fetched.Value] = fetched.Label
+
+		designation, ok := existingByID[fetched.Value]
+		delete(existingByID, fetched.Value)
+		if ok && designation.IsActive && designation.DesignationName != fetched.Label {
+			err := s.change(constants.DIRECTORY_ENTITY_DESIGNATION, fetched.Value, fetched.Label, constants.DIRECTORY_CHANGE_RENAMED,
+				designation.DesignationName, fetched.Label)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !ok || !designation.IsActive {
+			if err := s.change(constants.DIRECTORY_ENTITY_DESIGNATION, fetched.Value, fetched.Label, constants.DIRECTORY_CHANGE_ADDED, "", ""); err != nil {
+				return nil, err
+			}
+		}
+
+		designation.DesignationID = fetched.Value
+		designation.DesignationName = fetched.Label
+		designation.IsActive = true
+		designation.SyncedAt = s.run.StartedAt
+		if err := s.tx.Save(&designation).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	for _, designation := range existingByID {
+		if !designation.IsActive {
+			continue
+		}
+		err := s.change(constants.DIRECTORY_ENTITY_DESIGNATION, designation.DesignationID, designation.DesignationName, constants.DIRECTORY_CHANGE_REMOVED, "", "")
+		if err != nil {
+			return nil, err
+		}
+		if err := s.tx.Model(&designation).Updates(map[string]interface{}{"is_active": false, "synced_at": s.run.StartedAt}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return names, nil
+}
+
+func (s *directorySync) applyProjects(projects []utils.ProjectResponse) error {
+	var existing []models.DirectoryProject
+	if err := s.tx.Find(&existing).Error; err != nil {
+		return err
+	}
+	existingByID := make(map[uint16]models.DirectoryProject, len(existing))
+	for _, project := range existing {
+		existingByID[project.ProjectID] = project
+	}
+
+	for _, fetched := range projects {
+		projectName := strings.Trim(fetched.ProjectName, "\r\n")
+
+		project, ok := existingByID[fetched.ProjectID]
+		delete(existingByID, fetched.ProjectID)
+		if ok && project.IsActive && project.ProjectName != projectName {
+			err := s.change(constants.DIRECTORY_ENTITY_PROJECT, fetched.ProjectID, projectName, constants.DIRECTORY_CHANGE_RENAMED,
+				project.ProjectName, projectName)
+			if err != nil {
+				return err
+			}
+		}
+		if !ok || !project.IsActive {
+			if err := s.change(constants.DIRECTORY_ENTITY_PROJECT, fetched.ProjectID, projectName, constants.DIRECTORY_CHANGE_ADDED, "", ""); err != nil {
+				return err
+			}
+		}
+
+		project.ProjectID = fetched.ProjectID
+		project.ProjectName = projectName
+		project.IsActive = true
+		project.SyncedAt = s.run.StartedAt
+		if err := s.tx.Omit("Members").Save(&project).Error; err != nil {
+			return err
+		}
+
+		if err := s.tx.Unscoped().Where("project_id = ?", fetched.ProjectID).Delete(&models.DirectoryProjectMember{}).Error; err != nil {
+			return err
+		}
+		members := make([]models.DirectoryProjectMember, 0, len(fetched.ProjectEmployees))
+		seen := make(map[uint16]bool, len(fetched.ProjectEmployees))
+		for _, employee := range fetched.ProjectEmployees {
+			if seen[employee.EmployeeID] {
+				continue
+			}
+			seen[employee.EmployeeID] = true
+			members = append(members, models.DirectoryProjectMember{
+				ProjectID:          fetched.ProjectID,
+				EmployeeID:         employee.EmployeeID,
+				EmployeeName:       employee.EmployeeName,
+				SupervisorName:     employee.EmployeeProjectSupervisor,
+				ProjectStartedDate: employee.ProjectStartedDate,
+			})
+		}
+		if len(members) > 0 {
+			if err := s.tx.Create(&members).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, project := range existingByID {
+		if !project.IsActive {
+			continue
+		}
+		err := s.change(constants.DIRECTORY_ENTITY_PROJECT, project.ProjectID, project.ProjectName, constants.DIRECTORY_CHANGE_REMOVED, "", "")
+		if err != nil {
+			return err
+		}
+		if err := s.tx.Model(&project).Updates(map[string]interface{}{"is_active": false, "synced_at": s.run.StartedAt}).Error; err != nil {
+			return err
+		}
+		if err := s.tx.Unscoped().Where("project_id = ?", project.ProjectID).Delete(&models.DirectoryProjectMember{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *directorySync) applyEmployees(employees map[uint16]utils.EmployeeInfo, designationNames map[uint16]string) error {
+	var existing []models.DirectoryEmployee
+	if err := s.tx.Find(&existing).Error; err != nil {
+		return err
+	}
+	existingByID := make(map[uint16]models.DirectoryEmployee, len(existing))
+	for _, employee := range existing {
+		existingByID[employee.EmployeeID] = employee
+	}
+
+	for _, fetched := range employees {
+		employee, ok := existingByID[fetched.EmployeeID]
+		delete(existingByID, fetched.EmployeeID)
+
+		var err error
+		switch {
+		case fetched.IsActive && (!ok || !employee.IsActive):
+			err = s.change(constants.DIRECTORY_ENTITY_EMPLOYEE, fetched.EmployeeID, fetched.Name, constants.DIRECTORY_CHANGE_JOINED, "", designationNames[fetched.Designation])
+		case !fetched.IsActive && ok && employee.IsActive:
+			err = s.change(constants.DIRECTORY_ENTITY_EMPLOYEE, fetched.EmployeeID, fetched.Name, constants.DIRECTORY_CHANGE_LEFT, "", "")
+		}
+		if err != nil {
+			return err
+		}
+
+		if ok && employee.IsActive && fetched.IsActive {
+			if employee.EmployeeName != fetched.Name {
+				err := s.change(constants.DIRECTORY_ENTITY_EMPLOYEE, fetched.EmployeeID, fetched.Name, constants.DIRECTORY_CHANGE_RENAMED,
+					employee.EmployeeName, fetched.Name)
+				if err != nil {
+					return err
+				}
+			}
+			if fetched.Designation != 0 && employee.Designation != fetched.Designation {
+				err := s.change(constants.DIRECTORY_ENTITY_EMPLOYEE, fetched.EmployeeID, fetched.Name, constants.DIRECTORY_CHANGE_DESIGNATION_CHANGED,
+					employee.DesignationName, designationNames[fetched.Designation])
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if !fetched.IsActive && employee.IsActive {
+			leftAt := s.run.StartedAt
+			employee.LeftAt = &leftAt
+		}
+		if fetched.IsActive {
+			employee.LeftAt = nil
+		}
+		employee.EmployeeID = fetched.EmployeeID
+		employee.EmployeeName = fetched.Name
+		if fetched.Designation != 0 {
+			employee.Designation = fetched.Designation
+			employee.DesignationName = designationNames[fetched.Designation]
+		}
+		employee.IsActive = fetched.IsActive
+		employee.SyncedAt = s.run.StartedAt
+		if err := s.tx.Save(&employee).Error; err != nil {
+			return err
+		}
+	}
+
+	// Employees no longer in TOSS at all have left as well
+	for _, employee := range existingByID {
+		if !employee.IsActive {
+			continue
+		}
+		err := s.change(constants.DIRECTORY_ENTITY_EMPLOYEE, employee.EmployeeID, employee.EmployeeName, constants.DIRECTORY_CHANGE_LEFT, "", "")
+		if err != nil {
+			return err
+		}
+		err = s.tx.Model(&employee).Updates(map[string]interface{}{
+			"is_active": false,
+			"left_at":   s.run.StartedAt,
+			"synced_at": s.run.StartedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// employeeTeams returns the sorted project names of every employee in the local
+// directory, joined into a single value
+func (s *directorySync) employeeTeams() (map[uint16]string, error) {
+	var rows []struct {
+		EmployeeID  uint16
+		ProjectName string
+	}
+	err := s.tx.Table("directory_project_members").
+		Select("directory_project_members.employee_id, directory_projects.project_name").
+		Joins("JOIN directory_projects ON directory_projects.project_id = directory_project_members.project_id AND directory_projects.deleted_at IS NULL").
+		Where("directory_project_members.deleted_at IS NULL").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	projectsByEmployee := make(map[uint16][]string)
+	for _, row := range rows {
+		projectsByEmployee[row.EmployeeID] = append(projectsByEmployee[row.EmployeeID], row.ProjectName)
+	}
+
+	teams := make(map[uint16]string, len(projectsByEmployee))
+	for employeeID, projectNames := range projectsByEmployee {
+		sort.Strings(projectNames)
+		teams[employeeID] = strings.Join(projectNames, ", ")
+	}
+
+	return teams, nil
+}
+
+func GetDirectorySyncRuns(db *gorm.DB, runs *[]models.DirectorySyncRun) error {
+	log.Info("Getting directory sync runs")
+
+	if err := db.Model(&models.DirectorySyncRun{}).Order("id DESC").Find(runs).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetDirectoryChanges(db *gorm.DB, changes *[]models.DirectoryChange) error {
+	log.Info("Getting directory changes")
+
+	if err := db.Model(&models.DirectoryChange{}).Order("detected_at DESC, id ASC").Find(changes).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetDirectoryEmployees(db *gorm.DB, employees *[]models.DirectoryEmployee) error {
+	log.Info("Getting directory employees")
+
+	if err := db.Model(&models.DirectoryEmployee{}).Order("employee_id ASC").Find(employees).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetDirectoryProjects(db *gorm.DB, projects *[]models.DirectoryProject) error {
+	log.Info("Getting directory projects")
+
+	err := db.Model(&models.DirectoryProject{}).Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("employee_id ASC")
+	}).Order("project_id ASC").Find(projects).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetCycleRosterChanges returns the joiners of the organization and the leavers
+// and team moves among the employees of an appraisal cycle, from the creation of
+// its first appraisal up to its last due date, or up to now if a due date is open
+func GetCycleRosterChanges(db *gorm.DB, appraisalYear uint16, appraisalType string) (models.CycleRosterChanges, error) {
+	log.Info("Getting roster changes of the cycle")
+
+	roster := models.CycleRosterChanges{
+		AppraisalYear: appraisalYear,
+		AppraisalType: appraisalType,
+		Joiners:       make([]models.DirectoryChange, 0),
+		Leavers:       make([]models.DirectoryChange, 0),
+		TeamMoves:     make([]models.DirectoryChange, 0),
+	}
+
+	var window struct {
+		FromDate  *time.Time
+		ToDate    *time.Time
+		OpenEnded bool
+	}
+	err := db.Model(&models.Appraisal{}).
+		Select("MIN(created_at) AS from_date, MAX(due_date) AS to_date, BOOL_OR(due_date IS NULL) AS open_ended").
+		Where("appraisal_year = ? AND appraisal_type_str = ?", appraisalYear, appraisalType).
+		Scan(&window).Error
+	if err != nil {
+		log.Error(err.Error())
+		return roster, err
+	}
+	if window.FromDate == nil {
+		return roster, gorm.ErrRecordNotFound
+	}
+
+	roster.From = *window.FromDate
+	roster.To = time.Now()
+	if !window.OpenEnded && window.ToDate != nil && window.ToDate.Before(roster.To) {
+		roster.To = *window.ToDate
+	}
+
+	cycleEmployees := db.Session(&gorm.Session{NewDB: true}).Table("employee_data").
+		Select("employee_data.toss_emp_id").
+		Joins("JOIN appraisals ON appraisals.id = employee_data.appraisal_id AND appraisals.deleted_at IS NULL").
+		Where("employee_data.deleted_at IS NULL AND appraisals.appraisal_year = ? AND appraisals.appraisal_type_str = ?", appraisalYear, appraisalType)
+
+	changes := func(changeType string, cycleOnly bool, result *[]models.DirectoryChange) error {
+		query := db.Model(&models.DirectoryChange{}).
+			Where("entity_type = ? AND change_type = ? AND detected_at BETWEEN ? AND ?",
+				constants.DIRECTORY_ENTITY_EMPLOYEE, changeType, roster.From, roster.To)
+		if cycleOnly {
+			query = query.Where("entity_id IN (?)", cycleEmployees)
+		}
+		return query.Order("detected_at ASC, id ASC").Find(result).Error
+	}
+
+	if err := changes(constants.DIRECTORY_CHANGE_JOINED, false, &roster.Joiners); err != nil {
+		log.Error(err.Error())
+		return roster, err
+	}
+	if err := changes(constants.DIRECTORY_CHANGE_LEFT, true, &roster.Leavers); err != nil {
+		log.Error(err.Error())
+		return roster, err
+	}
+	if err := changes(constants.DIRECTORY_CHANGE_TEAM_MOVED, true, &roster.TeamMoves); err != nil {
+		log.Error(err.Error())
+		return roster, err
+	}
+
+	return roster, nil
+}
+
+// LocalDirectory serves the TOSS directory from the local mirror
+type LocalDirectory struct {
+	Db *gorm.DB
+}
+
+func (d LocalDirectory) Projects(ctx context.Context) ([]utils.ProjectResponse, error) {
+	var projects []models.DirectoryProject
+	if err := GetDirectoryProjects(d.Db.WithContext(ctx).Where("is_active = ?", true), &projects); err != nil {
+		return nil, err
+	}
+
+	response := make([]utils.ProjectResponse, 0, len(projects))
+	for _, project := range projects {
+		employees := make([]utils.Employee, 0, len(project.Members))
+		for _, member := range project.Members {
+			employees = append(employees, utils.Employee{
+				EmployeeID:                member.EmployeeID,
+				EmployeeName:              member.EmployeeName,
+				ProjectName:               project.ProjectName,
+				ProjectStartedDate:        member.ProjectStartedDate,
+				EmployeeProjectSupervisor: member.SupervisorName,
+			})
+		}
+		response = append(response, utils.ProjectResponse{
+			ProjectID:        project.ProjectID,
+			ProjectName:      project.ProjectName,
+			ProjectEmployees: employees,
+		})
+	}
+
+	return response, nil
+}
+
+func (d LocalDirectory) Designations(ctx context.Context) ([]utils.DesignationInfo, error) {
+	var designations []models.DirectoryDesignation
+	err := d.Db.WithContext(ctx).Model(&models.DirectoryDesignation{}).Where("is_active = ?", true).Order("designation_id ASC").Find(&designations).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	response := make([]utils.DesignationInfo, 0, len(designations))
+	for _, designation := range designations {
+		response = append(response, utils.DesignationInfo{Value: designation.DesignationID, Label: designation.DesignationName})
+	}
+
+	return response, nil
+}
+
+// Employees returns every employee of the mirror, like TOSS lists former
+// employees as well
+func (d LocalDirectory) Employees(ctx context.Context) ([]utils.EmployeeInfo, error) {
+	var employees []models.DirectoryEmployee
+	if err := GetDirectoryEmployees(d.Db.WithContext(ctx), &employees); err != nil {
+		return nil, err
+	}
+
+	response := make([]utils.EmployeeInfo, 0, len(employees))
+	for _, employee := range employees {
+		response = append(response, utils.EmployeeInfo{
+			EmployeeID:  employee.EmployeeID,
+			Name:        employee.EmployeeName,
+			Designation: employee.Designation,
+			IsActive:    employee.IsActive,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"errors"
+	"math"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateDistributionCurve(db *gorm.DB, curve *models.DistributionCurve) (*models.DistributionCurve, error) {
+	log.Info("Creating distribution curve")
+
+	if err := checkDistributionCurve(db, curve); err != nil {
+		return nil, err
+	}
+
+	if err := db.Create(curve).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return curve, nil
+}
+
+func GetDistributionCurveByID(db *gorm.DB, curve *models.DistributionCurve, id uint64) error {
+	log.Info("Getting distribution curve by ID")
+
+	err := db.Model(&models.DistributionCurve{}).Preload("Bands", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_score ASC")
+	}).Where("id = ?", id).First(curve).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetAllDistributionCurves(db *gorm.DB, curves *[]models.DistributionCurve) error {
+	log.Info("Getting all distribution curves")
+
+	err := db.Model(&models.DistributionCurve{}).Preload("Bands", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_score ASC")
+	}).Order("id ASC").Find(curves).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func UpdateDistributionCurve(db *gorm.DB, curve *models.DistributionCurve) (*models.DistributionCurve, error) {
+	log.Info("Updating distribution curve")
+
+	// Check if distribution curve exists in the database
+	var existingCurve models.DistributionCurve
+	if err := db.Model(&models.DistributionCurve{}).First(&existingCurve, curve.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Error("distribution curve with the given id not found")
+			return nil, apperrors.NotFound("distribution curve not found")
+		}
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	if err := checkDistributionCurve(db, curve); err != nil {
+		return nil, err
+	}
+
+	// Retrieve bands for the existing curve
+	var existingBands []models.DistributionBand
+	if err := db.Model(&models.DistributionBand{}).Order("id ASC").Find(&existingBands, "curve_id = ?", curve.ID).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	// Delete remaining bands if the number of bands is reduced
+	if len(existingBands) > len(curve.Bands) {
+		deletedBands := existingBands[len(curve.Bands):]
+		for _, band := range deletedBands {
+			if err := db.Delete(&band).Error; err != nil {
+				log.Error(err.Error())
+				return nil, err
+			}
+		}
+	}
+
+	// Assign bands' IDs to the request bands
+	for k := range curve.Bands {
+		if k < len(existingBands) {
+			curve.Bands[k].ID = existingBands[k].ID
+		}
+	}
+
+	if err := db.Session(&gorm.Session{FullSaveAssociations: true}).Where("id = ?", curve.ID).Save(curve).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return curve, nil
+}
+
+func DeleteDistributionCurve(db *gorm.DB, curve *models.DistributionCurve) error {
+	log.Info("Deleting distribution curve")
+
+	err := db.Select(clause.Associations).Delete(curve).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// checkDistributionCurve makes sure the curve name is unique and that no other
+// curve targets the same team, designation or the whole organization
+func checkDistributionCurve(db *gorm.DB, curve *models.DistributionCurve) error {
+	var count int64
+	if err := db.Model(&models.DistributionCurve{}).Where("curve_name = ? AND id != ?", curve.CurveName, curve.ID).Count(&count).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if count > 0 {
+		log.Error("distribution curve name already exists")
+		return apperrors.Conflict("curve name already exists")
+	}
+
+	if err := db.Model(&models.DistributionCurve{}).
+		Where("assign_type_id = ? AND selected_assign_id = ? AND id != ?", curve.AssignTypeID, curve.SelectedAssignID, curve.ID).
+		Count(&count).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if count > 0 {
+		log.Error("distribution curve already exists for the selected assignment")
+		return apperrors.Conflict("a distribution curve already exists for the selected assignment")
+	}
+
+	return nil
+}
+
+// GetDistributionReport buckets the final scores of an appraisal cycle into the
+// bands of the target curve of every team (or designation) and flags the groups
+// whose actual distribution deviates from the target beyond the curve tolerance.
+// A group without a curve of its own is compared to the organization-wide curve.
+func GetDistributionReport(db *gorm.DB, appraisalYear uint16, appraisalType, groupBy string) (models.DistributionReport, error) {
+	log.Info("Getting distribution report")
+
+	report := models.DistributionReport{
+		AppraisalYear: appraisalYear,
+		AppraisalType: appraisalType,
+		GroupBy:       groupBy,
+		Groups:        make([]models.GroupDistribution, 0),
+	}
+
+	groupColumn, nameColumn, assignType := "results.team_id", "results.team_name", constants.ASSIGN_TYPE_TEAM
+	if groupBy == constants.GROUP_BY_DESIGNATION {
+		groupColumn, nameColumn, assignType = "results.designation", "results.designation_name", constants.ASSIGN_TYPE_ROLE
+	}
+
+	results := EmployeeResultsQuery(db).
+		Where("appraisals.appraisal_year = ? AND appraisals.appraisal_type_str = ?", appraisalYear, appraisalType)
+
+	var groups []models.GroupDistribution
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table("(?) AS results", results).
+		Select(groupColumn + " AS group_id, MAX(" + nameColumn + ") AS group_name, COUNT(*) AS employee_count, COUNT(results.final_score) AS scored_count").
+		Group(groupColumn).
+		Order(groupColumn + " ASC").
+		Scan(&groups).Error
+	if err != nil {
+		log.Error(err.Error())
+		return report, err
+	}
+
+	var bandCounts []struct {
+		GroupID uint16
+		BandID  uint16
+		Count   int64
+	}
+	err = db.Session(&gorm.Session{NewDB: true}).
+		Table("(?) AS results", results).
+		Select(groupColumn + " AS group_id, distribution_bands.id AS band_id, COUNT(*) AS count").
+		Joins(`JOIN distribution_bands ON distribution_bands.deleted_at IS NULL
+			AND results.final_score >= distribution_bands.min_score
+			AND (results.final_score < distribution_bands.max_score OR distribution_bands.max_score >= 100)`).
+		Group(groupColumn + ", distribution_bands.id").
+		Scan(&bandCounts).Error
+	if err != nil {
+		log.Error(err.Error())
+		return report, err
+	}
+
+	counts := make(map[[2]uint16]int64)
+	for _, bandCount := range bandCounts {
+		counts[[2]uint16{bandCount.GroupID, bandCount.BandID}] = bandCount.Count
+	}
+
+	var curves []models.DistributionCurve
+	if err := GetAllDistributionCurves(db, &curves); err != nil {
+		return report, err
+	}
+
+	var defaultCurve *models.DistributionCurve
+	groupCurves := make(map[uint16]*models.DistributionCurve)
+	for k := range curves {
+		switch {
+		case curves[k].AssignTypeID == 0:
+			defaultCurve = &curves[k]
+		case curves[k].AssignTypeName == assignType:
+			groupCurves[curves[k].SelectedAssignID] = &curves[k]
+		}
+	}
+
+	for _, group := range groups {
+		curve, ok := groupCurves[group.GroupID]
+		if !ok {
+			curve = defaultCurve
+		}
+
+		compareWithCurve(&group, curve, counts)
+		report.Groups = append(report.Groups, group)
+	}
+
+	return report, nil
+}
+
+// compareWithCurve sets the actual share of every band of the curve in the group
+// from the counts of employees per group and band, and flags the group if a
+// share deviates from its target beyond the curve tolerance
+func compareWithCurve(group *models.GroupDistribution, curve *models.DistributionCurve, counts map[[2]uint16]int64) {
+	group.Bands = make([]models.BandResult, 0)
+	if curve == nil {
+		return
+	}
+
+	group.CurveID = curve.ID
+	group.CurveName = curve.CurveName
+	group.Tolerance = curve.Tolerance
+
+	for _, band := range curve.Bands {
+		bandResult := models.BandResult{
+			BandName:         band.BandName,
+			MinScore:         band.MinScore,
+			MaxScore:         band.MaxScore,
+			TargetPercentage: band.TargetPercentage,
+			ActualCount:      counts[[2]uint16{group.GroupID, band.ID}],
+		}
+		if group.ScoredCount > 0 {
+			bandResult.ActualPercentage = float64(bandResult.ActualCount) * 100 / float64(group.ScoredCount)
+			bandResult.Deviation = bandResult.ActualPercentage - bandResult.TargetPercentage
+		}
+		group.MaxDeviation = math.Max(group.MaxDeviation, math.Abs(bandResult.Deviation))
+		group.Bands = append(group.Bands, bandResult)
+	}
+
+	group.Flagged = group.ScoredCount > 0 && group.MaxDeviation > curve.Tolerance
+}
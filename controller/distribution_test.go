@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+func TestCompareWithCurve(t *testing.T) {
+	curve := &models.DistributionCurve{
+		CommonModel: models.CommonModel{ID: 4},
+		CurveName:   "Bell",
+		Tolerance:   10,
+		Bands: []models.DistributionBand{
+			{CommonModel: models.CommonModel{ID: 1}, BandName: "Low", MinScore: 0, MaxScore: 40, TargetPercentage: 20},
+			{CommonModel: models.CommonModel{ID: 2}, BandName: "Mid", MinScore: 40, MaxScore: 80, TargetPercentage: 60},
+			{CommonModel: models.CommonModel{ID: 3}, BandName: "High", MinScore: 80, MaxScore: 100, TargetPercentage: 20},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		scoredCount     int64
+		counts          map[[2]uint16]int64
+		curve           *models.DistributionCurve
+		wantPercentages []float64
+		wantDeviations  []float64
+		wantMax         float64
+		wantFlagged     bool
+	}{
+		{
+			name:            "on target",
+			scoredCount:     10,
+			counts:          map[[2]uint16]int64{{9, 1}: 2, {9, 2}: 6, {9, 3}: 2},
+			curve:           curve,
+			wantPercentages: []float64{20, 60, 20},
+			wantDeviations:  []float64{0, 0, 0},
+		},
+		{
+			name:            "within the tolerance",
+			scoredCount:     10,
+			counts:          map[[2]uint16]int64{{9, 1}: 1, {9, 2}: 7, {9, 3}: 2},
+			curve:           curve,
+			wantPercentages: []float64{10, 70, 20},
+			wantDeviations:  []float64{-10, 10, 0},
+			wantMax:         10,
+		},
+		{
+			name:            "beyond the tolerance",
+			scoredCount:     10,
+			counts:          map[[2]uint16]int64{{9, 2}: 5, {9, 3}: 5},
+			curve:           curve,
+			wantPercentages: []float64{0, 50, 50},
+			wantDeviations:  []float64{-20, -10, 30},
+			wantMax:         30,
+			wantFlagged:     true,
+		},
+		{
+			name:            "counts of other groups are left out",
+			scoredCount:     4,
+			counts:          map[[2]uint16]int64{{9, 2}: 4, {8, 1}: 10},
+			curve:           curve,
+			wantPercentages: []float64{0, 100, 0},
+			wantDeviations:  []float64{-20, 40, -20},
+			wantMax:         40,
+			wantFlagged:     true,
+		},
+		{
+			name:            "no scored employees",
+			counts:          map[[2]uint16]int64{},
+			curve:           curve,
+			wantPercentages: []float64{0, 0, 0},
+			wantDeviations:  []float64{0, 0, 0},
+		},
+		{
+			name:        "no curve",
+			scoredCount: 10,
+			counts:      map[[2]uint16]int64{{9, 1}: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := models.GroupDistribution{GroupID: 9, ScoredCount: tt.scoredCount}
+			compareWithCurve(&group, tt.curve, tt.counts)
+
+			if len(group.Bands) != len(tt.wantPercentages) {
+				t.Fatalf("got %d bands, want %d", len(group.Bands), len(tt.wantPercentages))
+			}
+			for k, band := range group.Bands {
+				if math.Abs(band.ActualPercentage-tt.wantPercentages[k]) > 1e-9 {
+					t.Errorf("band %s is %v%%, want %v%%", band.BandName, band.ActualPercentage, tt.wantPercentages[k])
+				}
+				if math.Abs(band.Deviation-tt.wantDeviations[k]) > 1e-9 {
+					t.Errorf("band %s deviates by %v, want %v", band.BandName, band.Deviation, tt.wantDeviations[k])
+				}
+			}
+			if math.Abs(group.MaxDeviation-tt.wantMax) > 1e-9 {
+				t.Errorf("max deviation is %v, want %v", group.MaxDeviation, tt.wantMax)
+			}
+			if group.Flagged != tt.wantFlagged {
+				t.Errorf("flagged is %v, want %v", group.Flagged, tt.wantFlagged)
+			}
+			if tt.curve != nil && group.CurveID != tt.curve.ID {
+				t.Errorf("curve id is %d, want %d", group.CurveID, tt.curve.ID)
+			}
+		})
+	}
+}
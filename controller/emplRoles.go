@@ -1,8 +1,7 @@
 package controller
 
 import (
-	"errors"
-
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	"github.com/mrehanabbasi/appraisal-system-backend/models"
 	"gorm.io/gorm"
 )
@@ -32,7 +31,7 @@ func CreateRole(db *gorm.DB, role models.Role) (models.Role, error) {
 		return role, err
 	}
 	if count > 0 {
-		return role, errors.New("role name already exists")
+		return role, apperrors.Conflict("role name already exists")
 	}
 	if err := db.Table("roles").Create(&role).Error; err != nil {
 		return role, err
@@ -47,7 +46,7 @@ func UpdateRole(db *gorm.DB, role *models.Role) error {
 		return err
 	}
 	if count > 0 {
-		return errors.New("role name already exists")
+		return apperrors.Conflict("role name already exists")
 	}
 
 	if err := db.Table("roles").Updates(role).Error; err != nil {
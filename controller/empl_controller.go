@@ -1,8 +1,7 @@
 package controller
 
 import (
-	"errors"
-
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	"github.com/mrehanabbasi/appraisal-system-backend/models"
 	"gorm.io/gorm"
 )
@@ -12,7 +11,7 @@ func GetRoleIdFromDb(db *gorm.DB, roleName string) (uint, error) {
 	var role *models.Role
 	if err := db.Table("roles").Where("role_name = ?", roleName).First(&role).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return 0, errors.New("invalid role specified")
+			return 0, apperrors.Invalid("invalid role specified")
 		}
 		return 0, err
 	}
@@ -24,7 +23,7 @@ func ChecKSupervisorExist(db *gorm.DB, id uint) error {
 	var supervisor models.Employee
 	if err := db.Table("employees").Where("id = ? AND role = ?", id, "supervisor").First(&supervisor).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return errors.New("supervisor not found")
+			return apperrors.NotFound("supervisor not found")
 		}
 		return err
 	}
@@ -35,7 +34,7 @@ func ChecKSupervisorExist(db *gorm.DB, id uint) error {
 func CreateEmployee(db *gorm.DB, employee *models.Employee) (err error) {
 
 	if db.Table("employees").Where("email = ?", employee.Email).Find(&employee).RowsAffected > 0 {
-		return errors.New("email is already registered")
+		return apperrors.Conflict("email is already registered")
 	}
 
 	if err = db.Table("employees").Create(&employee).Error; err != nil {
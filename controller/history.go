@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"time"
+
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// RecordHistory stores an entry in the appraisal history of an employee
+func RecordHistory(db *gorm.DB, entry *models.AppraisalHistory) error {
+	entry.ID = 0
+	entry.ActedAt = time.Now()
+
+	if err := db.Create(entry).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetAppraisalHistory(db *gorm.DB, history *[]models.AppraisalHistory, appraisalID, empID uint64) error {
+	log.Info("Getting appraisal history")
+
+	err := db.Model(&models.AppraisalHistory{}).
+		Where("appraisal_id = ? AND employee_id = ?", appraisalID, empID).
+		Order("acted_at ASC, id ASC").
+		Find(history).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
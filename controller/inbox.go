@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// currentStepQuery selects the current flow step of an employee: the first step,
+// by order, whose user has not scored the employee yet, personally or through a
+// delegate
+const currentStepQuery = `SELECT flow_steps.user_id, flow_steps.step_name FROM flow_steps
+	WHERE flow_steps.flow_id = appraisals.appraisal_flow_id AND flow_steps.deleted_at IS NULL
+	AND NOT EXISTS (SELECT 1 FROM scores JOIN appraisal_kpis ON appraisal_kpis.id = scores.appraisal_kpi_id
+		WHERE appraisal_kpis.appraisal_id = employee_data.appraisal_id AND appraisal_kpis.employee_id = employee_data.toss_emp_id
+		AND (scores.evaluator_id = flow_steps.user_id OR scores.on_behalf_of = flow_steps.user_id) AND scores.deleted_at IS NULL)
+	ORDER BY flow_steps.step_order ASC LIMIT 1`
+
+// kpiCountsQuery counts the kpis of an employee the evaluator has to score and
+// the ones the evaluator has not scored yet. Questionnaires are answered by the
+// employees themselves.
+const kpiCountsQuery = `SELECT COUNT(*) AS total_kpis,
+	COUNT(*) FILTER (WHERE NOT EXISTS (SELECT 1 FROM scores WHERE scores.appraisal_kpi_id = appraisal_kpis.id
+		AND (scores.evaluator_id = @evaluator OR scores.on_behalf_of = @evaluator) AND scores.deleted_at IS NULL)) AS pending_kpis
+	FROM appraisal_kpis JOIN kpis ON kpis.id = appraisal_kpis.kpi_id
+	WHERE appraisal_kpis.appraisal_id = employee_data.appraisal_id AND appraisal_kpis.employee_id = employee_data.toss_emp_id
+	AND appraisal_kpis.deleted_at IS NULL AND kpis.kpi_type_str != @questionnaire`
+
+var inboxSortOrders = map[string]string{
+	constants.INBOX_SORT_URGENCY:  "appraisals.due_date ASC NULLS LAST, kc.pending_kpis DESC, employee_data.id ASC",
+	constants.INBOX_SORT_PENDING:  "kc.pending_kpis DESC, appraisals.due_date ASC NULLS LAST, employee_data.id ASC",
+	constants.INBOX_SORT_EMPLOYEE: "employee_data.employee_name ASC, employee_data.id ASC",
+}
+
+// IsValidInboxSort reports whether sort is a supported sort order of the inbox
+func IsValidInboxSort(sort string) bool {
+	_, ok := inboxSortOrders[sort]
+	return ok
+}
+
+// GetEvaluatorInbox lists the employees of the active appraisals the evaluator
+// still has kpis to score for, either as the supervisor of the employee, as the
+// previous supervisor of a transferred employee whose scoring is split, or as
+// the user of the current step of the appraisal flow
+func GetEvaluatorInbox(db *gorm.DB, tasks *[]models.InboxTask, evaluatorID uint16, sort string) error {
+	log.Info("Getting evaluator inbox")
+
+	order, ok := inboxSortOrders[sort]
+	if !ok {
+		order = inboxSortOrders[constants.INBOX_SORT_URGENCY]
+	}
+
+	err := db.Table("employee_data").
+		Select(`employee_data.appraisal_id, appraisals.appraisal_name, employee_data.toss_emp_id AS employee_id,
+			employee_data.employee_name, employee_data.team_name, employee_data.designation_name,
+			employee_data.appraisal_status, cur.step_name AS current_step, kc.total_kpis, kc.pending_kpis,
+			appraisals.due_date, COALESCE(appraisals.due_date < NOW(), false) AS overdue,
+			CASE WHEN `+employeeSupervisorIDColumn+` = @evaluator THEN @supervisor
+				WHEN tr.from_supervisor_id = @evaluator THEN @previousSupervisor ELSE @flowStep END AS assigned_as`,
+			map[string]interface{}{
+				"evaluator":          evaluatorID,
+				"supervisor":         constants.ASSIGNED_AS_SUPERVISOR,
+				"previousSupervisor": constants.ASSIGNED_AS_PREVIOUS_SUPERVISOR,
+				"flowStep":           constants.ASSIGNED_AS_FLOW_STEP,
+			}).
+		Joins("JOIN appraisals ON appraisals.id = employee_data.appraisal_id AND appraisals.deleted_at IS NULL").
+		Joins(`LEFT JOIN (?) AS tr ON tr.appraisal_id = employee_data.appraisal_id
+			AND tr.employee_id = employee_data.toss_emp_id AND tr.split_scoring`, latestTransfersQuery(db)).
+		Joins("LEFT JOIN LATERAL ("+currentStepQuery+") AS cur ON true").
+		Joins("JOIN LATERAL ("+kpiCountsQuery+") AS kc ON true", map[string]interface{}{
+			"evaluator":     evaluatorID,
+			"questionnaire": constants.QUESTIONNAIRE_KPI_TYPE,
+		}).
+		Where("employee_data.deleted_at IS NULL AND appraisals.status = ? AND employee_data.toss_emp_id != ?", true, evaluatorID).
+		Where(employeeSupervisorIDColumn+" = ? OR tr.from_supervisor_id = ? OR cur.user_id = ?", evaluatorID, evaluatorID, evaluatorID).
+		Where("kc.pending_kpis > 0").
+		Order(order).
+		Scan(tasks).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	for k := range *tasks {
+		task := &(*tasks)[k]
+		task.ScoreURL = fmt.Sprintf("/v1/appraisals/%d/employees/%d/score", task.AppraisalID, task.EmployeeID)
+	}
+
+	return nil
+}
+
+// SortInboxTasks sorts tasks gathered from several inboxes in the same order
+// GetEvaluatorInbox sorts a single inbox
+func SortInboxTasks(tasks []models.InboxTask, sortBy string) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		switch sortBy {
+		case constants.INBOX_SORT_PENDING:
+			if a.PendingKpis != b.PendingKpis {
+				return a.PendingKpis > b.PendingKpis
+			}
+			return dueBefore(a.DueDate, b.DueDate)
+		case constants.INBOX_SORT_EMPLOYEE:
+			return a.EmployeeName < b.EmployeeName
+		default:
+			if (a.DueDate == nil) != (b.DueDate == nil) || (a.DueDate != nil && !a.DueDate.Equal(*b.DueDate)) {
+				return dueBefore(a.DueDate, b.DueDate)
+			}
+			return a.PendingKpis > b.PendingKpis
+		}
+	})
+}
+
+// dueBefore orders due dates ascending with the missing ones last
+func dueBefore(a, b *time.Time) bool {
+	if a == nil {
+		return false
+	}
+	if b == nil {
+		return true
+	}
+	return a.Before(*b)
+}
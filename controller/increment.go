@@ -0,0 +1,364 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateIncrementPolicy(db *gorm.DB, policy *models.IncrementPolicy) (*models.IncrementPolicy, error) {
+	log.Info("Creating increment policy")
+
+	if err := checkIncrementPolicy(db, policy); err != nil {
+		return nil, err
+	}
+
+	if err := db.Create(policy).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func GetIncrementPolicyByID(db *gorm.DB, policy *models.IncrementPolicy, id uint64) error {
+	log.Info("Getting increment policy by ID")
+
+	err := db.Model(&models.IncrementPolicy{}).Preload("Bands", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_score ASC")
+	}).Where("id = ?", id).First(policy).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetAllIncrementPolicies(db *gorm.DB, policies *[]models.IncrementPolicy) error {
+	log.Info("Getting all increment policies")
+
+	err := db.Model(&models.IncrementPolicy{}).Preload("Bands", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_score ASC")
+	}).Order("id ASC").Find(policies).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func UpdateIncrementPolicy(db *gorm.DB, policy *models.IncrementPolicy) (*models.IncrementPolicy, error) {
+	log.Info("Updating increment policy")
+
+	// Check if increment policy exists in the database
+	var existingPolicy models.IncrementPolicy
+	if err := db.Model(&models.IncrementPolicy{}).First(&existingPolicy, policy.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Error("increment policy with the given id not found")
+			return nil, apperrors.NotFound("increment policy not found")
+		}
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	if err := checkIncrementPolicy(db, policy); err != nil {
+		return nil, err
+	}
+
+	// Retrieve bands for the existing policy
+	var existingBands []models.IncrementBand
+	if err := db.Model(&models.IncrementBand{}).Order("id ASC").Find(&existingBands, "policy_id = ?", policy.ID).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	// Delete remaining bands if the number of bands is reduced
+	if len(existingBands) > len(policy.Bands) {
+		deletedBands := existingBands[len(policy.Bands):]
+		for _, band := range deletedBands {
+			if err := db.Delete(&band).Error; err != nil {
+				log.Error(err.Error())
+				return nil, err
+			}
+		}
+	}
+
+	// Assign bands' IDs to the request bands
+	for k := range policy.Bands {
+		if k < len(existingBands) {
+			policy.Bands[k].ID = existingBands[k].ID
+		}
+	}
+
+	if err := db.Session(&gorm.Session{FullSaveAssociations: true}).Where("id = ?", policy.ID).Save(policy).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func DeleteIncrementPolicy(db *gorm.DB, policy *models.IncrementPolicy) error {
+	log.Info("Deleting increment policy")
+
+	err := db.Select(clause.Associations).Delete(policy).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// checkIncrementPolicy makes sure the policy name is unique and that no other
+// policy targets the same designation or every designation
+func checkIncrementPolicy(db *gorm.DB, policy *models.IncrementPolicy) error {
+	var count int64
+	if err := db.Model(&models.IncrementPolicy{}).Where("policy_name = ? AND id != ?", policy.PolicyName, policy.ID).Count(&count).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if count > 0 {
+		log.Error("increment policy name already exists")
+		return apperrors.Conflict("policy name already exists")
+	}
+
+	if err := db.Model(&models.IncrementPolicy{}).
+		Where("appraisal_type_str = ? AND designation = ? AND id != ?", policy.AppraisalTypeStr, policy.Designation, policy.ID).
+		Count(&count).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if count > 0 {
+		log.Error("increment policy already exists for the designation")
+		return apperrors.Conflict("an increment policy already exists for the designation")
+	}
+
+	return nil
+}
+
+// matchIncrementBand finds the band of the policy of the designation, or of the
+// organization-wide policy, that the score falls in. A band includes its min
+// score and excludes its max score, except for a max score of 100.
+func matchIncrementBand(policies map[uint16]*models.IncrementPolicy, designation uint16, score float64) (*models.IncrementPolicy, *models.IncrementBand) {
+	policy, ok := policies[designation]
+	if !ok {
+		policy, ok = policies[0]
+		if !ok {
+			return nil, nil
+		}
+	}
+
+	for k := range policy.Bands {
+		band := &policy.Bands[k]
+		if score >= band.MinScore && (score < band.MaxScore || (band.MaxScore >= 100 && score <= band.MaxScore)) {
+			return policy, band
+		}
+	}
+
+	return policy, nil
+}
+
+// GenerateRecommendations fills the recommendation sheet of an annual cycle from
+// the final scores of its employees. Regenerating refreshes the recommended
+// values and keeps supervisor overrides; approved rows are left untouched.
+func GenerateRecommendations(db *gorm.DB, appraisalYear uint16, appraisalType string) (models.RecommendationSheet, error) {
+	log.Info("Generating increment recommendations")
+
+	sheet := models.RecommendationSheet{
+		AppraisalYear:   appraisalYear,
+		AppraisalType:   appraisalType,
+		Recommendations: make([]models.Recommendation, 0),
+	}
+
+	if appraisalType != constants.ANNUAL_APPRAISAL {
+		log.Error("recommendations requested for a non annual cycle")
+		return sheet, apperrors.Invalid("recommendations are only generated for annual appraisals")
+	}
+
+	var policies []models.IncrementPolicy
+	err := GetAllIncrementPolicies(db.Where("appraisal_type_str = ?", appraisalType), &policies)
+	if err != nil {
+		return sheet, err
+	}
+	policyByDesignation := make(map[uint16]*models.IncrementPolicy)
+	for k := range policies {
+		policyByDesignation[policies[k].Designation] = &policies[k]
+	}
+
+	var results []models.EmployeeResult
+	err = EmployeeResultsQuery(db).
+		Where("appraisals.appraisal_year = ? AND appraisals.appraisal_type_str = ?", appraisalYear, appraisalType).
+		Order("employee_data.appraisal_id ASC, employee_data.toss_emp_id ASC").
+		Scan(&results).Error
+	if err != nil {
+		log.Error(err.Error())
+		return sheet, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, result := range results {
+			if result.FinalScore == nil {
+				continue
+			}
+
+			var recommendation models.Recommendation
+			err := tx.Model(&models.Recommendation{}).
+				Where("appraisal_id = ? AND employee_id = ?", result.AppraisalID, result.EmployeeID).
+				Limit(1).Find(&recommendation).Error
+			if err != nil {
+				return err
+			}
+			if recommendation.Status == constants.RECOMMENDATION_STATUS_APPROVED {
+				continue
+			}
+
+			recommendation.AppraisalID = result.AppraisalID
+			recommendation.AppraisalYear = result.AppraisalYear
+			recommendation.AppraisalType = result.AppraisalType
+			recommendation.EmployeeID = result.EmployeeID
+			recommendation.EmployeeName = result.EmployeeName
+			recommendation.Designation = result.Designation
+			recommendation.DesignationName = result.DesignationName
+			recommendation.TeamName = result.TeamName
+			recommendation.SupervisorID = result.SupervisorID
+			recommendation.FinalScore = result.FinalScore
+			recommendation.PolicyID = nil
+			recommendation.BandName = ""
+			recommendation.RecommendedIncrement = 0
+			recommendation.RecommendedPromotion = false
+			if recommendation.Status == "" {
+				recommendation.Status = constants.RECOMMENDATION_STATUS_DRAFT
+			}
+
+			policy, band := matchIncrementBand(policyByDesignation, result.Designation, *result.FinalScore)
+			if band == nil {
+				sheet.Unmatched++
+			} else {
+				recommendation.PolicyID = &policy.ID
+				recommendation.BandName = band.BandName
+				recommendation.RecommendedIncrement = band.IncrementPercentage
+				recommendation.RecommendedPromotion = band.PromotionEligible
+			}
+
+			if err := tx.Save(&recommendation).Error; err != nil {
+				return err
+			}
+			sheet.Generated++
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return sheet, err
+	}
+
+	err = GetRecommendations(db.Where("appraisal_year = ? AND appraisal_type = ?", appraisalYear, appraisalType), &sheet.Recommendations)
+	return sheet, err
+}
+
+func GetRecommendations(db *gorm.DB, recommendations *[]models.Recommendation) error {
+	log.Info("Getting increment recommendations")
+
+	err := db.Model(&models.Recommendation{}).Order("appraisal_id ASC, employee_id ASC").Find(recommendations).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	for k := range *recommendations {
+		setFinalRecommendation(&(*recommendations)[k])
+	}
+
+	return nil
+}
+
+func GetRecommendationByID(db *gorm.DB, recommendation *models.Recommendation, id uint64) error {
+	log.Info("Getting increment recommendation by ID")
+
+	if err := db.Model(&models.Recommendation{}).Where("id = ?", id).First(recommendation).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	setFinalRecommendation(recommendation)
+	return nil
+}
+
+// OverrideRecommendation replaces the recommended values with those of the
+// supervisor of the employee. Approved recommendations are final.
+func OverrideRecommendation(db *gorm.DB, recommendation *models.Recommendation, override *models.RecommendationOverride) (*models.Recommendation, error) {
+	log.Info("Overriding increment recommendation")
+
+	if recommendation.Status == constants.RECOMMENDATION_STATUS_APPROVED {
+		log.Error("recommendation is approved")
+		return nil, apperrors.Conflict("recommendation is already approved")
+	}
+
+	now := time.Now()
+	err := db.Model(recommendation).Updates(map[string]interface{}{
+		"override_increment":     *override.IncrementPercentage,
+		"override_promotion":     *override.PromotionEligible,
+		"override_justification": override.Justification,
+		"overridden_by":          override.SupervisorID,
+		"overridden_at":          now,
+		"status":                 constants.RECOMMENDATION_STATUS_OVERRIDDEN,
+	}).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	recommendation.OverrideIncrement = override.IncrementPercentage
+	recommendation.OverridePromotion = override.PromotionEligible
+	recommendation.OverrideJustification = override.Justification
+	recommendation.OverriddenBy = override.SupervisorID
+	recommendation.OverriddenAt = &now
+	recommendation.Status = constants.RECOMMENDATION_STATUS_OVERRIDDEN
+	setFinalRecommendation(recommendation)
+
+	return recommendation, nil
+}
+
+// ApproveRecommendations approves the recommendations in bulk and returns the
+// number of recommendations approved. Already approved ones are skipped.
+func ApproveRecommendations(db *gorm.DB, approval *models.RecommendationApproval) (int64, error) {
+	log.Info("Approving increment recommendations")
+
+	tx := db.Model(&models.Recommendation{}).
+		Where("id IN ? AND status != ?", []int64(approval.RecommendationIDs), constants.RECOMMENDATION_STATUS_APPROVED).
+		Updates(map[string]interface{}{
+			"status":      constants.RECOMMENDATION_STATUS_APPROVED,
+			"approved_by": approval.ApprovedBy,
+			"approved_at": time.Now(),
+		})
+	if tx.Error != nil {
+		log.Error(tx.Error.Error())
+		return 0, tx.Error
+	}
+
+	return tx.RowsAffected, nil
+}
+
+// setFinalRecommendation sets the values in effect, the override if any and the
+// recommended values otherwise
+func setFinalRecommendation(recommendation *models.Recommendation) {
+	recommendation.FinalIncrement = recommendation.RecommendedIncrement
+	if recommendation.OverrideIncrement != nil {
+		recommendation.FinalIncrement = *recommendation.OverrideIncrement
+	}
+
+	recommendation.FinalPromotion = recommendation.RecommendedPromotion
+	if recommendation.OverridePromotion != nil {
+		recommendation.FinalPromotion = *recommendation.OverridePromotion
+	}
+}
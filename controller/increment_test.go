@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+func TestMatchIncrementBand(t *testing.T) {
+	organization := &models.IncrementPolicy{
+		CommonModel: models.CommonModel{ID: 1},
+		Bands: []models.IncrementBand{
+			{BandName: "Low", MinScore: 0, MaxScore: 50},
+			{BandName: "High", MinScore: 50, MaxScore: 100},
+		},
+	}
+	engineers := &models.IncrementPolicy{
+		CommonModel: models.CommonModel{ID: 2},
+		Designation: 7,
+		Bands: []models.IncrementBand{
+			{BandName: "Meets", MinScore: 60, MaxScore: 80},
+			{BandName: "Exceeds", MinScore: 80, MaxScore: 100},
+		},
+	}
+	policies := map[uint16]*models.IncrementPolicy{0: organization, 7: engineers}
+
+	tests := []struct {
+		name        string
+		policies    map[uint16]*models.IncrementPolicy
+		designation uint16
+		score       float64
+		wantPolicy  uint16
+		wantBand    string
+	}{
+		{name: "policy of the designation", policies: policies, designation: 7, score: 70, wantPolicy: 2, wantBand: "Meets"},
+		{name: "min score is included", policies: policies, designation: 7, score: 80, wantPolicy: 2, wantBand: "Exceeds"},
+		{name: "max score is excluded", policies: policies, designation: 7, score: 79.99, wantPolicy: 2, wantBand: "Meets"},
+		{name: "max score of 100 is included", policies: policies, designation: 7, score: 100, wantPolicy: 2, wantBand: "Exceeds"},
+		{name: "score below every band", policies: policies, designation: 7, score: 59.5, wantPolicy: 2},
+		{name: "organization-wide policy", policies: policies, designation: 3, score: 49.9, wantPolicy: 1, wantBand: "Low"},
+		{name: "no policy", policies: map[uint16]*models.IncrementPolicy{7: engineers}, designation: 3, score: 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, band := matchIncrementBand(tt.policies, tt.designation, tt.score)
+
+			var policyID uint16
+			if policy != nil {
+				policyID = policy.ID
+			}
+			if policyID != tt.wantPolicy {
+				t.Errorf("got policy %d, want %d", policyID, tt.wantPolicy)
+			}
+
+			var bandName string
+			if band != nil {
+				bandName = band.BandName
+			}
+			if bandName != tt.wantBand {
+				t.Errorf("got band %q, want %q", bandName, tt.wantBand)
+			}
+		})
+	}
+}
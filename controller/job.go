@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+func CreateJob(db *gorm.DB, job *models.Job) error {
+	log.Info("Creating job")
+
+	if err := db.Create(job).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ClaimJob marks a queued job as running. A job is only claimed once, so a job
+// queued twice is still run once.
+func ClaimJob(db *gorm.DB, id uint16) (*models.Job, bool, error) {
+	now := time.Now()
+	result := db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, constants.JOB_STATUS_QUEUED).
+		Updates(map[string]interface{}{"status": constants.JOB_STATUS_RUNNING, "started_at": now})
+	if result.Error != nil {
+		log.Error(result.Error.Error())
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, false, nil
+	}
+
+	var job models.Job
+	if err := db.Model(&models.Job{}).First(&job, id).Error; err != nil {
+		log.Error(err.Error())
+		return nil, false, err
+	}
+
+	return &job, true, nil
+}
+
+// FinishJob records the outcome of a job. A bulk job whose items failed still
+// succeeds, its failed items are listed in its errors.
+func FinishJob(db *gorm.DB, job *models.Job, jobErr error) error {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = constants.JOB_STATUS_SUCCEEDED
+	if jobErr != nil {
+		job.Status = constants.JOB_STATUS_FAILED
+		job.Error = jobErr.Error()
+	}
+
+	err := db.Model(job).Updates(map[string]interface{}{
+		"status":      job.Status,
+		"error":       job.Error,
+		"finished_at": job.FinishedAt,
+	}).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// RecoverJobs fails the jobs left running by a restart, as what they did before
+// is unknown, and returns the queued jobs to run again
+func RecoverJobs(db *gorm.DB) ([]uint16, error) {
+	log.Info("Recovering jobs")
+
+	now := time.Now()
+	err := db.Model(&models.Job{}).
+		Where("status = ?", constants.JOB_STATUS_RUNNING).
+		Updates(map[string]interface{}{
+			"status":      constants.JOB_STATUS_FAILED,
+			"error":       "interrupted by a restart",
+			"finished_at": now,
+		}).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	var queuedIDs []uint16
+	err = db.Model(&models.Job{}).Where("status = ?", constants.JOB_STATUS_QUEUED).Order("id ASC").Pluck("id", &queuedIDs).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return queuedIDs, nil
+}
+
+func GetAllJobs(db *gorm.DB, jobs *[]models.Job) error {
+	log.Info("Getting all jobs")
+
+	err := db.Preload("Errors", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Order("id DESC").Find(jobs).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetJobByID(db *gorm.DB, job *models.Job, id uint64) error {
+	log.Info("Getting job by ID")
+
+	err := db.Model(&models.Job{}).
+		Preload("Errors", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Where("id = ?", id).First(job).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// JobProgress records the progress of a running job as it goes
+type JobProgress struct {
+	db  *gorm.DB
+	job *models.Job
+}
+
+func NewJobProgress(db *gorm.DB, job *models.Job) *JobProgress {
+	return &JobProgress{db: db, job: job}
+}
+
+// SetTotal sets the number of items the job works through
+func (p *JobProgress) SetTotal(total int) error {
+	p.job.TotalItems = total
+	return p.db.Model(p.job).Update("total_items", total).Error
+}
+
+// ItemSucceeded counts an item as processed
+func (p *JobProgress) ItemSucceeded() error {
+	p.job.CompletedItems++
+	return p.db.Model(p.job).Update("completed_items", p.job.CompletedItems).Error
+}
+
+// ItemFailed counts an item as processed and records why it failed
+func (p *JobProgress) ItemFailed(item, reason string) error {
+	p.job.CompletedItems++
+	p.job.FailedItems++
+
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(p.job).Updates(map[string]interface{}{
+			"completed_items": p.job.CompletedItems,
+			"failed_items":    p.job.FailedItems,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&models.JobError{JobID: p.job.ID, Item: item, Error: reason}).Error
+	})
+}
+
+// SetResult records what the job created
+func (p *JobProgress) SetResult(resultID uint16, resultURL string) error {
+	p.job.ResultID = resultID
+	p.job.ResultURL = resultURL
+	return p.db.Model(p.job).Updates(map[string]interface{}{"result_id": resultID, "result_url": resultURL}).Error
+}
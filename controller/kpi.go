@@ -3,6 +3,7 @@ package controller
 import (
 	"errors"
 
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
 	"github.com/mrehanabbasi/appraisal-system-backend/models"
 	"gorm.io/gorm"
@@ -20,7 +21,7 @@ func CreateKPI(db *gorm.DB, kpi *models.Kpi) (*models.Kpi, error) {
 	}
 	if count > 0 {
 		log.Error("kpi name already exists")
-		return nil, errors.New("kpi name already exists")
+		return nil, apperrors.Conflict("kpi name already exists")
 	}
 
 	// Create new KPI record
@@ -40,7 +41,7 @@ func UpdateKPI(db *gorm.DB, kpi *models.Kpi) (*models.Kpi, error) {
 	if err := db.Model(&models.Kpi{}).First(&existingKpi, kpi.ID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			log.Error("kpi with the given id not found")
-			return nil, errors.New("kpi not found")
+			return nil, apperrors.NotFound("kpi not found")
 		}
 		log.Error(err.Error())
 		return nil, err
@@ -54,7 +55,7 @@ func UpdateKPI(db *gorm.DB, kpi *models.Kpi) (*models.Kpi, error) {
 	}
 	if count > 0 {
 		log.Error("invalid kpi id or kpi name already exists")
-		return nil, errors.New("invalid kpi id or kpi name already exists")
+		return nil, apperrors.Invalid("invalid kpi id or kpi name already exists")
 	}
 
 	// Retrieve statements for the existing KPI
@@ -96,7 +97,7 @@ func GetKPIByID(db *gorm.DB, id uint64) (models.Kpi, error) {
 
 	var kpi models.Kpi
 
-	if err := db.Model(&models.Kpi{}).Preload("Statements").Where("id = ?", id).First(&kpi).Error; err != nil {
+	if err := db.Model(&models.Kpi{}).Preload("Statements").Preload("RatingScale.Levels").Where("id = ?", id).First(&kpi).Error; err != nil {
 		log.Error(err.Error())
 		return kpi, err
 	}
@@ -107,7 +108,7 @@ func GetKPIByID(db *gorm.DB, id uint64) (models.Kpi, error) {
 func GetAllKPI(db *gorm.DB, kpi *[]models.Kpi) error {
 	log.Info("Getting all KPIs")
 
-	err := db.Model(&models.Kpi{}).Preload("Statements").Order("id ASC").Find(&kpi).Error
+	err := db.Model(&models.Kpi{}).Preload("Statements").Preload("RatingScale.Levels").Order("id ASC").Find(&kpi).Error
 	if err != nil {
 		log.Error(err.Error())
 		return err
@@ -124,7 +125,7 @@ func DeleteKPI(db *gorm.DB, id uint64) error {
 
 	if err := db.Model(&models.Kpi{}).First(&kpi, id).Error; err != nil {
 		log.Error("kpi with the given id not found")
-		return errors.New("kpi not found")
+		return apperrors.NotFound("kpi not found")
 	}
 
 	// Delete the KPI
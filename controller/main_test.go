@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/logger"
+)
+
+func TestMain(m *testing.M) {
+	_ = os.Setenv("LOG_LEVEL", "error")
+	logger.TextLogInit()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmployeeAppraisalsQuery selects the appraisals the employee is part of along
+// with the appraisal status of the employee in each one of them
+func EmployeeAppraisalsQuery(db *gorm.DB, empID uint16) *gorm.DB {
+	return db.Table("employee_data").
+		Select(`employee_data.appraisal_id, appraisals.appraisal_name, appraisals.appraisal_year,
+			appraisals.appraisal_type_str AS appraisal_type, `+employeeSupervisorIDColumn+` AS supervisor_id,
+			`+employeeSupervisorNameColumn+` AS supervisor_name, appraisals.due_date, appraisals.status AS is_active, employee_data.appraisal_status`).
+		Joins("JOIN appraisals ON appraisals.id = employee_data.appraisal_id AND appraisals.deleted_at IS NULL").
+		Where("employee_data.deleted_at IS NULL AND employee_data.toss_emp_id = ?", empID)
+}
+
+func GetEmployeeAppraisals(db *gorm.DB, appraisals *[]models.EmployeeAppraisal) error {
+	log.Info("Getting appraisals of the employee")
+
+	err := db.Order("appraisals.appraisal_year DESC, employee_data.appraisal_id DESC").Scan(appraisals).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetEmployeeAppraisalKpis returns the kpis of the employee only, unlike
+// GetAppraisalKpisByEmpID which returns the kpis of the whole appraisal
+func GetEmployeeAppraisalKpis(db *gorm.DB, appraisalKpis *[]models.AppraisalKpi, appraisalID uint64, empID uint16) error {
+	log.Info("Getting appraisal kpis of the employee")
+
+	tx := db.Model(&models.AppraisalKpi{}).
+		Preload(clause.Associations).Preload("Kpi.Statements").
+		Joins("JOIN appraisals ON appraisals.id = appraisal_kpis.appraisal_id AND appraisals.deleted_at IS NULL").
+		Where("appraisal_kpis.appraisal_id = ? AND appraisal_kpis.employee_id = ?", appraisalID, empID).
+		Order("appraisal_kpis.id ASC").
+		Find(appraisalKpis)
+	if tx.Error != nil {
+		log.Error(tx.Error.Error())
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return AttachKpiRatingScales(db, *appraisalKpis)
+}
+
+// GetSelfReviewTasks lists the questionnaires the employee still has to answer
+// in the active appraisals
+func GetSelfReviewTasks(db *gorm.DB, tasks *[]models.SelfReviewTask, empID uint16) error {
+	log.Info("Getting self review tasks of the employee")
+
+	err := db.Table("appraisal_kpis").
+		Select(`appraisal_kpis.appraisal_id, appraisals.appraisal_name, appraisal_kpis.id AS appraisal_kpi_id,
+			appraisal_kpis.kpi_id, kpis.kpi_name, kpis.kpi_type_str AS kpi_type, appraisals.due_date`).
+		Joins("JOIN appraisals ON appraisals.id = appraisal_kpis.appraisal_id AND appraisals.deleted_at IS NULL").
+		Joins("JOIN kpis ON kpis.id = appraisal_kpis.kpi_id").
+		Where("appraisal_kpis.deleted_at IS NULL AND appraisal_kpis.employee_id = ? AND appraisals.status = ?", empID, true).
+		Where("kpis.kpi_type_str = ? AND NOT "+scoredCondition, constants.QUESTIONNAIRE_KPI_TYPE).
+		Order("appraisals.due_date ASC NULLS LAST, appraisal_kpis.id ASC").
+		Scan(tasks).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetPublishedResults returns the final scores of the employee in the appraisals
+// whose results were published to the employee
+func GetPublishedResults(db *gorm.DB, results *[]models.EmployeeResult, empID uint16) error {
+	log.Info("Getting published results of the employee")
+
+	err := EmployeeResultsQuery(db).
+		Where("employee_data.toss_emp_id = ? AND employee_data.appraisal_status = ?", empID, constants.APPRAISAL_STATUS_PUBLISHED).
+		Order("appraisals.appraisal_year DESC, employee_data.appraisal_id DESC").
+		Scan(results).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return AttachResultLabels(db, *results)
+}
+
+// PublishAppraisalResults makes the results of the given employees of an appraisal
+// visible to them. All the employees of the appraisal are published if empIDs is
+// empty. It returns the number of employees published.
+func PublishAppraisalResults(db *gorm.DB, appraisalID uint64, empIDs []uint16) (int64, error) {
+	log.Info("Publishing appraisal results")
+
+	query := db.Model(&models.EmployeeData{}).Where("appraisal_id = ?", appraisalID)
+	if len(empIDs) > 0 {
+		query = query.Where("toss_emp_id IN ?", empIDs)
+	}
+
+	tx := query.Update("appraisal_status", constants.APPRAISAL_STATUS_PUBLISHED)
+	if tx.Error != nil {
+		log.Error(tx.Error.Error())
+		return 0, tx.Error
+	}
+
+	return tx.RowsAffected, nil
+}
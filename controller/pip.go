@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// activePipCondition matches the employee results with a plan that is not closed
+const activePipCondition = `EXISTS (SELECT 1 FROM pips WHERE pips.appraisal_id = employee_data.appraisal_id
+	AND pips.employee_id = employee_data.toss_emp_id AND pips.status != ? AND pips.deleted_at IS NULL)`
+
+// GetPipCandidates suggests the employees whose final score is below the
+// threshold and who are not already on a plan for the appraisal
+func GetPipCandidates(db *gorm.DB, candidates *[]models.PipCandidate, threshold float64) error {
+	log.Info("Getting pip candidates")
+
+	err := db.Table("(?) AS results", EmployeeResultsQuery(db.Session(&gorm.Session{NewDB: true})).
+		Where("NOT "+activePipCondition, constants.PIP_STATUS_CLOSED)).
+		Where("results.final_score < ?", threshold).
+		Order("results.final_score ASC, results.appraisal_id ASC, results.employee_id ASC").
+		Scan(candidates).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// CreatePip opens a plan for an employee of an appraisal. The owner is the
+// supervisor of the appraisal and an employee has one active plan per appraisal.
+func CreatePip(db *gorm.DB, pip *models.Pip) (*models.Pip, error) {
+	log.Info("Creating pip")
+
+	if !pip.FinalReviewDate.After(pip.StartDate) {
+		log.Error("final review date is not after the start date")
+		return nil, apperrors.Invalid("final_review_date should be after start_date")
+	}
+	if pip.MidReviewDate != nil && (pip.MidReviewDate.Before(pip.StartDate) || pip.MidReviewDate.After(pip.FinalReviewDate)) {
+		log.Error("mid review date is outside the plan")
+		return nil, apperrors.Invalid("mid_review_date should be between start_date and final_review_date")
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result, err := GetEmployeeResult(tx, uint64(pip.AppraisalID), uint64(pip.EmployeeID))
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&models.Pip{}).
+			Where("appraisal_id = ? AND employee_id = ? AND status != ?", pip.AppraisalID, pip.EmployeeID, constants.PIP_STATUS_CLOSED).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.Conflict("employee is already on a plan for the appraisal")
+		}
+
+		pip.EmployeeName = result.EmployeeName
+		pip.OwnerID = result.SupervisorID
+		pip.OwnerName = result.SupervisorName
+		pip.TriggerScore = result.FinalScore
+		pip.Status = constants.PIP_STATUS_OPEN
+		for k := range pip.Objectives {
+			pip.Objectives[k].Status = constants.PIP_OBJECTIVE_PENDING
+		}
+
+		if err := tx.Create(pip).Error; err != nil {
+			return err
+		}
+
+		return RecordHistory(tx, &models.AppraisalHistory{
+			AppraisalID: pip.AppraisalID,
+			EmployeeID:  pip.EmployeeID,
+			Action:      constants.HISTORY_ACTION_PIP_OPENED,
+			ActorID:     pip.OpenedBy,
+			Details:     fmt.Sprintf("pip_id %v owned by %v", pip.ID, pip.OwnerID),
+		})
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return pip, nil
+}
+
+func GetPipByID(db *gorm.DB, pip *models.Pip, id uint64) error {
+	log.Info("Getting pip by ID")
+
+	err := db.Model(&models.Pip{}).
+		Preload("Objectives", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("CheckIns", func(db *gorm.DB) *gorm.DB {
+			return db.Order("scheduled_at ASC, id ASC")
+		}).
+		Where("id = ?", id).First(pip).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetAllPips(db *gorm.DB, pips *[]models.Pip) error {
+	log.Info("Getting all pips")
+
+	err := db.Preload("Objectives", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Order("final_review_date ASC, id ASC").Find(pips).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// AddPipObjective adds an objective to a plan that is not closed
+func AddPipObjective(db *gorm.DB, pip *models.Pip, objective *models.PipObjective) (*models.PipObjective, error) {
+	log.Info("Adding pip objective")
+
+	if pip.Status == constants.PIP_STATUS_CLOSED {
+		log.Error("pip is closed")
+		return nil, apperrors.Conflict("pip is closed")
+	}
+
+	objective.PipID = pip.ID
+	objective.Status = constants.PIP_OBJECTIVE_PENDING
+
+	if err := db.Create(objective).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return objective, nil
+}
+
+// UpdatePipObjective updates an objective of a plan that is not closed
+func UpdatePipObjective(db *gorm.DB, pip *models.Pip, objective *models.PipObjective) (*models.PipObjective, error) {
+	log.Info("Updating pip objective")
+
+	if pip.Status == constants.PIP_STATUS_CLOSED {
+		log.Error("pip is closed")
+		return nil, apperrors.Conflict("pip is closed")
+	}
+
+	var existing models.PipObjective
+	err := db.Model(&models.PipObjective{}).Where("id = ? AND pip_id = ?", objective.ID, pip.ID).First(&existing).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	objective.PipID = pip.ID
+	if objective.Status == "" {
+		objective.Status = existing.Status
+	}
+
+	err = db.Model(&existing).Select("description", "success_criteria", "due_date", "status").Updates(objective).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return objective, nil
+}
+
+// SavePipCheckIn schedules a check-in of a plan or records the one with the id
+// of the check-in. Closed plans accept no check-ins.
+func SavePipCheckIn(db *gorm.DB, pip *models.Pip, checkIn *models.PipCheckIn) (*models.PipCheckIn, error) {
+	log.Info("Saving pip check-in")
+
+	if pip.Status == constants.PIP_STATUS_CLOSED {
+		log.Error("pip is closed")
+		return nil, apperrors.Conflict("pip is closed")
+	}
+	if checkIn.HeldAt != nil && checkIn.HeldAt.After(time.Now()) {
+		log.Error("check-in recorded before being held")
+		return nil, apperrors.Invalid("held_at should not be in the future")
+	}
+
+	checkIn.PipID = pip.ID
+	if checkIn.ID == 0 {
+		if err := db.Create(checkIn).Error; err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		return checkIn, nil
+	}
+
+	var existing models.PipCheckIn
+	err := db.Model(&models.PipCheckIn{}).Where("id = ? AND pip_id = ?", checkIn.ID, pip.ID).First(&existing).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	err = db.Model(&existing).Select("scheduled_at", "held_at", "progress", "notes", "recorded_by").Updates(checkIn).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return checkIn, nil
+}
+
+// DecidePipOutcome closes a plan as successful or unsuccessful, or extends it
+// to a later final review date
+func DecidePipOutcome(db *gorm.DB, pip *models.Pip, outcome *models.PipOutcome) (*models.Pip, error) {
+	log.Info("Deciding pip outcome")
+
+	if pip.Status == constants.PIP_STATUS_CLOSED {
+		log.Error("pip is closed")
+		return nil, apperrors.Conflict("pip is already closed")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"outcome":       outcome.Outcome,
+		"outcome_notes": outcome.Notes,
+	}
+	action := constants.HISTORY_ACTION_PIP_CLOSED
+
+	if outcome.Outcome == constants.PIP_OUTCOME_EXTENDED {
+		if outcome.FinalReviewDate == nil || !outcome.FinalReviewDate.After(pip.FinalReviewDate) {
+			log.Error("extended final review date is not after the current one")
+			return nil, apperrors.Invalid("final_review_date should be after the current final review date of the pip")
+		}
+		updates["status"] = constants.PIP_STATUS_EXTENDED
+		updates["final_review_date"] = *outcome.FinalReviewDate
+		action = constants.HISTORY_ACTION_PIP_EXTENDED
+	} else {
+		updates["status"] = constants.PIP_STATUS_CLOSED
+		updates["closed_by"] = outcome.DecidedBy
+		updates["closed_at"] = now
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(pip).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return RecordHistory(tx, &models.AppraisalHistory{
+			AppraisalID: pip.AppraisalID,
+			EmployeeID:  pip.EmployeeID,
+			Action:      action,
+			ActorID:     outcome.DecidedBy,
+			Details:     fmt.Sprintf("pip_id %v %s", pip.ID, outcome.Outcome),
+		})
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	pip.Outcome = outcome.Outcome
+	pip.OutcomeNotes = outcome.Notes
+	if outcome.Outcome == constants.PIP_OUTCOME_EXTENDED {
+		pip.Status = constants.PIP_STATUS_EXTENDED
+		pip.FinalReviewDate = *outcome.FinalReviewDate
+	} else {
+		pip.Status = constants.PIP_STATUS_CLOSED
+		pip.ClosedBy = outcome.DecidedBy
+		pip.ClosedAt = &now
+	}
+
+	return pip, nil
+}
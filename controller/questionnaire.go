@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+func GetQuestionnaireKpis(db *gorm.DB, appraisalKpis *[]models.AppraisalKpi, appraisalID, empID uint64) error {
+	log.Info("Getting questionnaire kpis of employee")
+
+	var count int64
+	err := db.Model(&models.AppraisalKpi{}).
+		Preload("Kpi.Statements").
+		Joins("JOIN kpis ON kpis.id = appraisal_kpis.kpi_id").
+		Where("appraisal_kpis.appraisal_id = ? AND appraisal_kpis.employee_id = ? AND kpis.kpi_type_str = ?", appraisalID, empID, constants.QUESTIONNAIRE_KPI_TYPE).
+		Order("appraisal_kpis.id ASC").
+		Find(appraisalKpis).Count(&count).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SubmitQuestionnaireAnswers grades the answers of every questionnaire in appraisalKpis
+// and stores the answers along with the resulting score. A statement scores its
+// weightage when answered correctly and 0 otherwise.
+func SubmitQuestionnaireAnswers(db *gorm.DB, appraisalKpis []models.AppraisalKpi, answers []models.QuestionnaireAnswer, evaluatorID uint16) ([]models.Score, error) {
+	log.Info("Submitting questionnaire answers")
+
+	answersByKpi := make(map[uint16][]models.QuestionnaireAnswer)
+	for _, answer := range answers {
+		answersByKpi[answer.AppraisalKpiID] = append(answersByKpi[answer.AppraisalKpiID], answer)
+	}
+
+	scores := make([]models.Score, 0)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, appraisalKpi := range appraisalKpis {
+			kpiAnswers, ok := answersByKpi[appraisalKpi.ID]
+			if !ok {
+				continue
+			}
+
+			var count int64
+			if err := tx.Model(&models.Score{}).Where("appraisal_kpi_id = ?", appraisalKpi.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return apperrors.Conflict(fmt.Sprintf("questionnaire of appraisal_kpi_id %v is already submitted", appraisalKpi.ID))
+			}
+
+			points, err := gradeQuestionnaire(appraisalKpi.Kpi.Statements, kpiAnswers)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Create(&kpiAnswers).Error; err != nil {
+				return err
+			}
+
+			score := models.Score{
+				AppraisalKpiID: appraisalKpi.ID,
+				EvaluatorID:    evaluatorID,
+				Score:          &points,
+			}
+
+			var maxPoints uint16
+			for _, statement := range appraisalKpi.Kpi.Statements {
+				maxPoints += uint16(statement.Weightage)
+			}
+			if maxPoints > 0 {
+				percentage := float64(points) * 100 / float64(maxPoints)
+				score.Percentage = &percentage
+			}
+
+			if err := tx.Create(&score).Error; err != nil {
+				return err
+			}
+			scores = append(scores, score)
+
+			err = RecordHistory(tx, &models.AppraisalHistory{
+				AppraisalID: appraisalKpi.AppraisalID,
+				EmployeeID:  appraisalKpi.EmployeeID,
+				Action:      constants.HISTORY_ACTION_QUESTIONNAIRE_SUBMITTED,
+				ActorID:     evaluatorID,
+				Details:     fmt.Sprintf("appraisal_kpi_id %v", appraisalKpi.ID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+// gradeQuestionnaire marks every answer and returns the sum of weightages of the
+// correctly answered statements. Every statement must be answered exactly once.
+func gradeQuestionnaire(statements []models.MultiStatementKpiData, answers []models.QuestionnaireAnswer) (uint16, error) {
+	if len(answers) != len(statements) {
+		return 0, apperrors.Invalid("number of answers does not match the number of questionnaire statements")
+	}
+
+	statementMap := make(map[uint16]models.MultiStatementKpiData)
+	for _, statement := range statements {
+		statementMap[statement.ID] = statement
+	}
+
+	var points uint16
+	answered := make(map[uint16]bool)
+	for k := range answers {
+		statement, ok := statementMap[answers[k].StatementID]
+		if !ok {
+			return 0, apperrors.Invalid(fmt.Sprintf("invalid statement_id :%v", answers[k].StatementID))
+		}
+		if answered[statement.ID] {
+			return 0, apperrors.Invalid(fmt.Sprintf("statement_id %v is answered more than once", statement.ID))
+		}
+		answered[statement.ID] = true
+
+		answers[k].Answer = strings.TrimSpace(answers[k].Answer)
+		if !contains(statement.Options, answers[k].Answer) {
+			return 0, apperrors.Invalid(fmt.Sprintf("answer of statement_id %v is not one of its options", statement.ID))
+		}
+
+		answers[k].IsCorrect = answers[k].Answer == statement.CorrectAnswer
+		if answers[k].IsCorrect {
+			points += uint16(statement.Weightage)
+		}
+	}
+
+	return points, nil
+}
+
+// GetQuestionnaireReview builds the graded view of the submitted questionnaires
+// used by supervisors to review the answers of an employee. The answer keys of
+// the questionnaires not scored yet are left out.
+func GetQuestionnaireReview(db *gorm.DB, appraisalKpis []models.AppraisalKpi) ([]models.QuestionnaireReview, error) {
+	log.Info("Getting questionnaire review")
+
+	reviews := make([]models.QuestionnaireReview, 0, len(appraisalKpis))
+	for _, appraisalKpi := range appraisalKpis {
+		var answers []models.QuestionnaireAnswer
+		if err := db.Model(&models.QuestionnaireAnswer{}).Where("appraisal_kpi_id = ?", appraisalKpi.ID).Find(&answers).Error; err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		answerMap := make(map[uint16]models.QuestionnaireAnswer)
+		for _, answer := range answers {
+			answerMap[answer.StatementID] = answer
+		}
+
+		review := models.QuestionnaireReview{
+			AppraisalKpiID: appraisalKpi.ID,
+			KpiID:          appraisalKpi.KpiID,
+			KpiName:        appraisalKpi.Kpi.KpiName,
+			Statements:     make([]models.QuestionnaireReviewItem, 0, len(appraisalKpi.Kpi.Statements)),
+		}
+
+		var score models.Score
+		err := db.Model(&models.Score{}).Where("appraisal_kpi_id = ?", appraisalKpi.ID).First(&score).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Error(err.Error())
+			return nil, err
+		}
+		review.Score = score.Score
+
+		for _, statement := range appraisalKpi.Kpi.Statements {
+			answer := answerMap[statement.ID]
+			review.MaxScore += uint16(statement.Weightage)
+			item := models.QuestionnaireReviewItem{
+				StatementID: statement.ID,
+				Statement:   statement.Statement,
+				Options:     statement.Options,
+				Weightage:   statement.Weightage,
+				Answer:      answer.Answer,
+				IsCorrect:   answer.IsCorrect,
+			}
+			if review.Score != nil {
+				item.CorrectAnswer = statement.CorrectAnswer
+			}
+			review.Statements = append(review.Statements, item)
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+func questionnaireStatements() []models.MultiStatementKpiData {
+	return []models.MultiStatementKpiData{
+		{CommonModel: models.CommonModel{ID: 1}, Options: []string{"Go", "Rust"}, Weightage: 3, CorrectAnswer: "Go"},
+		{CommonModel: models.CommonModel{ID: 2}, Options: []string{"Yes", "No"}, Weightage: 2, CorrectAnswer: "No"},
+	}
+}
+
+func TestGradeQuestionnaire(t *testing.T) {
+	tests := []struct {
+		name        string
+		answers     []models.QuestionnaireAnswer
+		wantPoints  uint16
+		wantCorrect []bool
+	}{
+		{
+			name:        "all correct",
+			answers:     []models.QuestionnaireAnswer{{StatementID: 1, Answer: "Go"}, {StatementID: 2, Answer: "No"}},
+			wantPoints:  5,
+			wantCorrect: []bool{true, true},
+		},
+		{
+			name:        "some correct",
+			answers:     []models.QuestionnaireAnswer{{StatementID: 2, Answer: "No"}, {StatementID: 1, Answer: "Rust"}},
+			wantPoints:  2,
+			wantCorrect: []bool{true, false},
+		},
+		{
+			name:        "none correct",
+			answers:     []models.QuestionnaireAnswer{{StatementID: 1, Answer: "Rust"}, {StatementID: 2, Answer: "Yes"}},
+			wantPoints:  0,
+			wantCorrect: []bool{false, false},
+		},
+		{
+			name:        "answers are trimmed",
+			answers:     []models.QuestionnaireAnswer{{StatementID: 1, Answer: "  Go "}, {StatementID: 2, Answer: "No\n"}},
+			wantPoints:  5,
+			wantCorrect: []bool{true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := gradeQuestionnaire(questionnaireStatements(), tt.answers)
+			if err != nil {
+				t.Fatalf("gradeQuestionnaire: %v", err)
+			}
+			if points != tt.wantPoints {
+				t.Errorf("got %d points, want %d", points, tt.wantPoints)
+			}
+			for k, answer := range tt.answers {
+				if answer.IsCorrect != tt.wantCorrect[k] {
+					t.Errorf("answer %d is_correct = %v, want %v", k, answer.IsCorrect, tt.wantCorrect[k])
+				}
+			}
+		})
+	}
+}
+
+func TestGradeQuestionnaireInvalidAnswers(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []models.QuestionnaireAnswer
+	}{
+		{
+			name:    "missing answer",
+			answers: []models.QuestionnaireAnswer{{StatementID: 1, Answer: "Go"}},
+		},
+		{
+			name:    "extra answer",
+			answers: []models.QuestionnaireAnswer{{StatementID: 1, Answer: "Go"}, {StatementID: 2, Answer: "No"}, {StatementID: 2, Answer: "Yes"}},
+		},
+		{
+			name:    "unknown statement",
+			answers: []models.QuestionnaireAnswer{{StatementID: 1, Answer: "Go"}, {StatementID: 3, Answer: "No"}},
+		},
+		{
+			name:    "statement answered twice",
+			answers: []models.QuestionnaireAnswer{{StatementID: 1, Answer: "Go"}, {StatementID: 1, Answer: "Rust"}},
+		},
+		{
+			name:    "answer out of the options",
+			answers: []models.QuestionnaireAnswer{{StatementID: 1, Answer: "Go"}, {StatementID: 2, Answer: "Maybe"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := gradeQuestionnaire(questionnaireStatements(), tt.answers)
+			if kind := apperrors.KindOf(err); kind != apperrors.KindValidation {
+				t.Fatalf("got %v of kind %v, want a validation error", err, kind)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateRatingScale(db *gorm.DB, ratingScale *models.RatingScale) (*models.RatingScale, error) {
+	log.Info("Creating rating scale")
+
+	// Check if rating scale name already exists
+	var count int64
+	if err := db.Model(&models.RatingScale{}).Where("scale_name = ?", ratingScale.ScaleName).Count(&count).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if count > 0 {
+		log.Error("rating scale name already exists")
+		return nil, apperrors.Conflict("rating scale name already exists")
+	}
+
+	if err := db.Create(ratingScale).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return ratingScale, nil
+}
+
+func GetRatingScaleByID(db *gorm.DB, id uint64) (models.RatingScale, error) {
+	log.Info("Getting rating scale by ID")
+
+	var ratingScale models.RatingScale
+	err := db.Model(&models.RatingScale{}).Preload("Levels", func(db *gorm.DB) *gorm.DB {
+		return db.Order("value ASC")
+	}).Where("id = ?", id).First(&ratingScale).Error
+	if err != nil {
+		log.Error(err.Error())
+		return ratingScale, err
+	}
+
+	return ratingScale, nil
+}
+
+func GetAllRatingScales(db *gorm.DB, ratingScales *[]models.RatingScale) error {
+	log.Info("Getting all rating scales")
+
+	err := db.Model(&models.RatingScale{}).Preload("Levels", func(db *gorm.DB) *gorm.DB {
+		return db.Order("value ASC")
+	}).Order("id ASC").Find(ratingScales).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func UpdateRatingScale(db *gorm.DB, ratingScale *models.RatingScale) (*models.RatingScale, error) {
+	log.Info("Updating rating scale")
+
+	// Check if rating scale exists in the database
+	var existingRatingScale models.RatingScale
+	if err := db.Model(&models.RatingScale{}).First(&existingRatingScale, ratingScale.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Error("rating scale with the given id not found")
+			return nil, apperrors.NotFound("rating scale not found")
+		}
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	// Check if rating scale name already exists
+	var count int64
+	if err := db.Model(&models.RatingScale{}).Where("scale_name = ? AND id != ?", ratingScale.ScaleName, ratingScale.ID).Count(&count).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	if count > 0 {
+		log.Error("rating scale name already exists")
+		return nil, apperrors.Conflict("rating scale name already exists")
+	}
+
+	// Retrieve levels for the existing rating scale
+	var existingLevels []models.RatingLevel
+	if err := db.Model(&models.RatingLevel{}).Order("id ASC").Find(&existingLevels, "rating_scale_id = ?", ratingScale.ID).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	// Delete remaining levels if the number of levels is reduced
+	if len(existingLevels) > len(ratingScale.Levels) {
+		deletedLevels := existingLevels[len(ratingScale.Levels):]
+		for _, level := range deletedLevels {
+			if err := db.Delete(&level).Error; err != nil {
+				log.Error(err.Error())
+				return nil, err
+			}
+		}
+	}
+
+	// Assign levels' IDs to the request levels
+	for k := range ratingScale.Levels {
+		if k < len(existingLevels) {
+			ratingScale.Levels[k].ID = existingLevels[k].ID
+		}
+	}
+
+	if err := db.Session(&gorm.Session{FullSaveAssociations: true}).Where("id = ?", ratingScale.ID).Save(ratingScale).Error; err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return ratingScale, nil
+}
+
+func DeleteRatingScale(db *gorm.DB, id uint64) error {
+	log.Info("Deleting rating scale")
+
+	var ratingScale models.RatingScale
+	if err := db.Model(&models.RatingScale{}).First(&ratingScale, id).Error; err != nil {
+		log.Error("rating scale with the given id not found")
+		return apperrors.NotFound("rating scale not found")
+	}
+
+	// A scale attached to a KPI or KPI type can't be removed
+	var kpiCount, kpiTypeCount int64
+	if err := db.Model(&models.Kpi{}).Where("rating_scale_id = ?", id).Count(&kpiCount).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if err := db.Model(&models.KpiType{}).Where("rating_scale_id = ?", id).Count(&kpiTypeCount).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	if kpiCount > 0 || kpiTypeCount > 0 {
+		log.Error("rating scale is attached to kpis or kpi types")
+		return apperrors.Conflict("rating scale is in use")
+	}
+
+	if err := db.Select(clause.Associations).Delete(&ratingScale).Error; err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetKpiRatingScale returns the rating scale that applies to the KPI. A scale
+// attached to the KPI itself takes precedence over the one of its KPI type.
+// nil is returned if neither has a scale.
+func GetKpiRatingScale(db *gorm.DB, kpi models.Kpi) (*models.RatingScale, error) {
+	scaleID := kpi.RatingScaleID
+	if scaleID == nil {
+		var kpiType models.KpiType
+		if err := db.Model(&models.KpiType{}).Where("kpi_type = ?", kpi.KpiTypeStr).First(&kpiType).Error; err != nil {
+			log.Error(err.Error())
+			return nil, err
+		}
+		scaleID = kpiType.RatingScaleID
+	}
+	if scaleID == nil {
+		return nil, nil
+	}
+
+	ratingScale, err := GetRatingScaleByID(db, uint64(*scaleID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ratingScale, nil
+}
+
+// AttachScoreLabels fills the label of every numeric score from the rating scale
+// of its KPI. The appraisal KPIs of the scores are loaded if missing.
+func AttachScoreLabels(db *gorm.DB, scores []models.Score) error {
+	scales := make(map[uint16]*models.RatingScale)
+	for k := range scores {
+		if scores[k].Score == nil {
+			continue
+		}
+
+		if scores[k].AppraisalKpi.ID == 0 {
+			if err := db.Model(&models.AppraisalKpi{}).Preload("Kpi").First(&scores[k].AppraisalKpi, scores[k].AppraisalKpiID).Error; err != nil {
+				log.Error(err.Error())
+				return err
+			}
+		}
+
+		kpi := scores[k].AppraisalKpi.Kpi
+		ratingScale, ok := scales[kpi.ID]
+		if !ok {
+			var err error
+			ratingScale, err = GetKpiRatingScale(db, kpi)
+			if err != nil {
+				return err
+			}
+			scales[kpi.ID] = ratingScale
+		}
+		if ratingScale == nil {
+			continue
+		}
+
+		if level, ok := ratingScale.Level(*scores[k].Score); ok {
+			scores[k].ScoreLabel = level.Label
+		}
+	}
+
+	return nil
+}
+
+// AttachKpiRatingScales sets the rating scale of every appraisal KPI to the one
+// that applies to it, so that reads return the labels of the levels along with
+// the KPIs
+func AttachKpiRatingScales(db *gorm.DB, appraisalKpis []models.AppraisalKpi) error {
+	scales := make(map[uint16]*models.RatingScale)
+	for k := range appraisalKpis {
+		kpi := appraisalKpis[k].Kpi
+		ratingScale, ok := scales[kpi.ID]
+		if !ok {
+			var err error
+			ratingScale, err = GetKpiRatingScale(db, kpi)
+			if err != nil {
+				return err
+			}
+			scales[kpi.ID] = ratingScale
+		}
+		appraisalKpis[k].Kpi.RatingScale = ratingScale
+	}
+
+	return nil
+}
+
+// AttachResultLabels labels the computed and final scores of every result with
+// the closest level of the rating scale of the measured KPIs of the employee.
+// Results whose KPIs have no scale, or several different ones, are left without
+// labels.
+func AttachResultLabels(db *gorm.DB, results []models.EmployeeResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	appraisalIDs := make([]uint16, 0, len(results))
+	for _, result := range results {
+		appraisalIDs = append(appraisalIDs, result.AppraisalID)
+	}
+
+	var appraisalKpis []models.AppraisalKpi
+	err := db.Model(&models.AppraisalKpi{}).Preload("Kpi").
+		Joins("JOIN kpis ON kpis.id = appraisal_kpis.kpi_id").
+		Where("appraisal_kpis.appraisal_id IN ? AND kpis.kpi_type_str = ?", appraisalIDs, constants.MEASURED_KPI_TYPE).
+		Find(&appraisalKpis).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	// Rating scales of the measured kpis of every employee of every appraisal
+	type employeeKey struct{ appraisalID, employeeID uint16 }
+	employeeScales := make(map[employeeKey]map[uint16]*models.RatingScale)
+	scales := make(map[uint16]*models.RatingScale)
+	for _, appraisalKpi := range appraisalKpis {
+		ratingScale, ok := scales[appraisalKpi.KpiID]
+		if !ok {
+			ratingScale, err = GetKpiRatingScale(db, appraisalKpi.Kpi)
+			if err != nil {
+				return err
+			}
+			scales[appraisalKpi.KpiID] = ratingScale
+		}
+		if ratingScale == nil {
+			continue
+		}
+
+		key := employeeKey{appraisalKpi.AppraisalID, appraisalKpi.EmployeeID}
+		if employeeScales[key] == nil {
+			employeeScales[key] = make(map[uint16]*models.RatingScale)
+		}
+		employeeScales[key][ratingScale.ID] = ratingScale
+	}
+
+	for k := range results {
+		resultScales := employeeScales[employeeKey{results[k].AppraisalID, results[k].EmployeeID}]
+		if len(resultScales) != 1 {
+			continue
+		}
+
+		var ratingScale *models.RatingScale
+		for _, scale := range resultScales {
+			ratingScale = scale
+		}
+
+		if results[k].ComputedScore != nil {
+			if level, ok := ratingScale.LevelForPercentage(*results[k].ComputedScore); ok {
+				results[k].ComputedScoreLabel = level.Label
+			}
+		}
+		if results[k].FinalScore != nil {
+			if level, ok := ratingScale.LevelForPercentage(*results[k].FinalScore); ok {
+				results[k].FinalScoreLabel = level.Label
+			}
+		}
+	}
+
+	return nil
+}
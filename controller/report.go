@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// GetEmployeeReport gathers everything the exports show about an employee in an
+// appraisal. Comments private to HR are left out unless includePrivate is set.
+func GetEmployeeReport(db *gorm.DB, appraisalID, empID uint64, includePrivate bool) (models.EmployeeReport, error) {
+	log.Info("Getting employee report")
+
+	var report models.EmployeeReport
+
+	result, err := GetEmployeeResult(db, appraisalID, empID)
+	if err != nil {
+		return report, err
+	}
+	results := []models.EmployeeResult{result}
+	if err := AttachResultLabels(db, results); err != nil {
+		return report, err
+	}
+	report.Result = results[0]
+
+	err = db.Model(&models.AppraisalKpi{}).Preload("Kpi").
+		Where("appraisal_id = ? AND employee_id = ?", appraisalID, empID).
+		Order("id ASC").
+		Find(&report.Kpis).Error
+	if err != nil {
+		log.Error(err.Error())
+		return report, err
+	}
+
+	err = db.Model(&models.Score{}).
+		Joins("JOIN appraisal_kpis ON appraisal_kpis.id = scores.appraisal_kpi_id").
+		Where("appraisal_kpis.appraisal_id = ? AND appraisal_kpis.employee_id = ?", appraisalID, empID).
+		Order("scores.id ASC").
+		Find(&report.Scores).Error
+	if err != nil {
+		log.Error(err.Error())
+		return report, err
+	}
+	if err := AttachScoreLabels(db, report.Scores); err != nil {
+		return report, err
+	}
+
+	query := db.Model(&models.Comment{}).Where("appraisal_id = ? AND employee_id = ?", appraisalID, empID)
+	if !includePrivate {
+		query = query.Where("visibility = ?", constants.COMMENT_VISIBILITY_SHARED)
+	}
+	if err := query.Order("id ASC").Find(&report.Comments).Error; err != nil {
+		log.Error(err.Error())
+		return report, err
+	}
+
+	return report, nil
+}
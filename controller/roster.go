@@ -0,0 +1,339 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+var ErrReconciliationReviewed = apperrors.Conflict("reconciliation is already reviewed")
+
+// GetOpenTeamAppraisals returns the active appraisals of a team, the only ones
+// whose employees follow the team roster in TOSS
+func GetOpenTeamAppraisals(db *gorm.DB, appraisals *[]models.Appraisal) error {
+	log.Info("Getting open team appraisals")
+
+	err := db.Model(&models.Appraisal{}).
+		Where("status = ? AND appraisal_for_name = ?", true, constants.ASSIGN_TYPE_TEAM).
+		Order("id ASC").
+		Find(appraisals).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAppraisalEmployeeIDs returns the employees of the appraisal, published or not
+func GetAppraisalEmployeeIDs(db *gorm.DB, appraisalID uint16) ([]uint16, error) {
+	var employeeIDs []uint16
+	err := db.Model(&models.EmployeeData{}).Where("appraisal_id = ?", appraisalID).Pluck("toss_emp_id", &employeeIDs).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return employeeIDs, nil
+}
+
+// ResolveEmployeeKpis returns the kpis of an employee of a team appraisal, the
+// same way they are resolved when the appraisal is created: the kpis of the team,
+// of the designation and of the employee
+func ResolveEmployeeKpis(db *gorm.DB, teamID, employeeID, designation uint16) ([]int64, error) {
+	var kpiIDs []int64
+	err := db.Model(&models.Kpi{}).
+		Joins("JOIN assign_types ON assign_types.id = kpis.assign_type_id").
+		Where(`(kpis.selected_assign_id = ? AND assign_types.assign_type = ?)
+			OR (kpis.selected_assign_id = ? AND assign_types.assign_type = ?)
+			OR (kpis.selected_assign_id = ? AND assign_types.assign_type = ?)`,
+			teamID, constants.ASSIGN_TYPE_TEAM, employeeID, constants.ASSIGN_TYPE_INDIVIDUAL, designation, constants.ASSIGN_TYPE_ROLE).
+		Order("kpis.id ASC").
+		Pluck("kpis.id", &kpiIDs).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return kpiIDs, nil
+}
+
+// ProposeRosterReconciliation proposes to enroll the joiners of the team and to
+// withdraw the pending employees no longer on the roster. Transferred employees
+// were moved on purpose and stay. A new proposal supersedes the pending one of
+// the appraisal. Nothing is proposed when the roster is up to date.
+func ProposeRosterReconciliation(db *gorm.DB, appraisal *models.Appraisal, rosterIDs []uint16, joiners []models.RosterChange) (*models.RosterReconciliation, error) {
+	log.Info("Proposing roster reconciliation")
+
+	if len(rosterIDs) == 0 {
+		return nil, apperrors.Invalid("team has no members in TOSS")
+	}
+
+	var leavers []models.RosterChange
+	err := db.Table("employee_data").
+		Select(`employee_data.toss_emp_id AS employee_id, employee_data.employee_name, employee_data.employee_image,
+			employee_data.designation, employee_data.designation_name,
+			(SELECT COUNT(DISTINCT appraisal_kpis.id) FROM appraisal_kpis
+				JOIN scores ON scores.appraisal_kpi_id = appraisal_kpis.id AND scores.deleted_at IS NULL
+				WHERE appraisal_kpis.appraisal_id = employee_data.appraisal_id AND appraisal_kpis.employee_id = employee_data.toss_emp_id
+				AND appraisal_kpis.deleted_at IS NULL) AS scored_kpis`).
+		Where("employee_data.deleted_at IS NULL AND employee_data.appraisal_id = ? AND employee_data.appraisal_status = ?",
+			appraisal.ID, constants.APPRAISAL_STATUS_PENDING).
+		Where("employee_data.toss_emp_id NOT IN ?", rosterIDs).
+		Where(`NOT EXISTS (SELECT 1 FROM transfers WHERE transfers.appraisal_id = employee_data.appraisal_id
+			AND transfers.employee_id = employee_data.toss_emp_id AND transfers.deleted_at IS NULL)`).
+		Order("employee_data.toss_emp_id ASC").
+		Scan(&leavers).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	reconciliation := models.RosterReconciliation{
+		AppraisalID:   appraisal.ID,
+		AppraisalName: appraisal.AppraisalName,
+		TeamID:        appraisal.SelectedFieldID,
+		TeamName:      appraisal.SelectedFieldNames,
+		Status:        constants.RECONCILIATION_STATUS_PROPOSED,
+		ProposedAt:    time.Now(),
+	}
+
+	for _, joiner := range joiners {
+		joiner.Action = constants.ROSTER_CHANGE_ENROLL
+		joiner.KpiIDs, err = ResolveEmployeeKpis(db, appraisal.SelectedFieldID, joiner.EmployeeID, joiner.Designation)
+		if err != nil {
+			return nil, err
+		}
+		reconciliation.Changes = append(reconciliation.Changes, joiner)
+	}
+	for _, leaver := range leavers {
+		leaver.Action = constants.ROSTER_CHANGE_WITHDRAW
+		reconciliation.Changes = append(reconciliation.Changes, leaver)
+	}
+
+	if len(reconciliation.Changes) == 0 {
+		return nil, nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.RosterReconciliation{}).
+			Where("appraisal_id = ? AND status = ?", appraisal.ID, constants.RECONCILIATION_STATUS_PROPOSED).
+			Update("status", constants.RECONCILIATION_STATUS_SUPERSEDED).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&reconciliation).Error
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return &reconciliation, nil
+}
+
+func GetRosterReconciliations(db *gorm.DB, reconciliations *[]models.RosterReconciliation) error {
+	log.Info("Getting roster reconciliations")
+
+	err := db.Preload("Changes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Order("proposed_at DESC, id DESC").Find(reconciliations).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func GetRosterReconciliationByID(db *gorm.DB, reconciliation *models.RosterReconciliation, id uint64) error {
+	log.Info("Getting roster reconciliation by ID")
+
+	err := db.Model(&models.RosterReconciliation{}).
+		Preload("Changes", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Where("id = ?", id).First(reconciliation).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ApplyRosterReconciliation applies the changes of a proposed reconciliation, or
+// only the listed ones. Joiners are enrolled with the kpis still defined among
+// the ones resolved for them, and leavers are withdrawn along with their kpis.
+// Changes overtaken since the proposal, like a joiner enrolled by another
+// reconciliation or a leaver whose results got published, are skipped.
+func ApplyRosterReconciliation(db *gorm.DB, id uint64, review *models.RosterReview) (*models.RosterReconciliation, error) {
+	log.Info("Applying roster reconciliation")
+
+	var reconciliation models.RosterReconciliation
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := GetRosterReconciliationByID(tx, &reconciliation, id); err != nil {
+			return err
+		}
+		if reconciliation.Status != constants.RECONCILIATION_STATUS_PROPOSED {
+			return ErrReconciliationReviewed
+		}
+
+		selected := make(map[int64]bool)
+		for _, changeID := range review.ChangeIDs {
+			selected[changeID] = true
+		}
+
+		for k := range reconciliation.Changes {
+			change := &reconciliation.Changes[k]
+			if len(selected) > 0 && !selected[int64(change.ID)] {
+				continue
+			}
+
+			var err error
+			switch change.Action {
+			case constants.ROSTER_CHANGE_ENROLL:
+				change.Applied, err = enrollEmployee(tx, &reconciliation, change, review.ReviewedBy)
+			case constants.ROSTER_CHANGE_WITHDRAW:
+				change.Applied, err = withdrawEmployee(tx, &reconciliation, change, review.ReviewedBy)
+			}
+			if err != nil {
+				return err
+			}
+
+			if change.Applied {
+				if err := tx.Model(change).Update("applied", true).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return reviewRosterReconciliation(tx, &reconciliation, constants.RECONCILIATION_STATUS_APPLIED, review)
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return &reconciliation, nil
+}
+
+func RejectRosterReconciliation(db *gorm.DB, id uint64, review *models.RosterReview) (*models.RosterReconciliation, error) {
+	log.Info("Rejecting roster reconciliation")
+
+	var reconciliation models.RosterReconciliation
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := GetRosterReconciliationByID(tx, &reconciliation, id); err != nil {
+			return err
+		}
+		if reconciliation.Status != constants.RECONCILIATION_STATUS_PROPOSED {
+			return ErrReconciliationReviewed
+		}
+
+		return reviewRosterReconciliation(tx, &reconciliation, constants.RECONCILIATION_STATUS_REJECTED, review)
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return &reconciliation, nil
+}
+
+func reviewRosterReconciliation(tx *gorm.DB, reconciliation *models.RosterReconciliation, status string, review *models.RosterReview) error {
+	now := time.Now()
+	reconciliation.Status = status
+	reconciliation.ReviewedBy = review.ReviewedBy
+	reconciliation.ReviewedAt = &now
+	reconciliation.Notes = review.Notes
+
+	return tx.Model(reconciliation).Updates(map[string]interface{}{
+		"status":      reconciliation.Status,
+		"reviewed_by": reconciliation.ReviewedBy,
+		"reviewed_at": reconciliation.ReviewedAt,
+		"notes":       reconciliation.Notes,
+	}).Error
+}
+
+func enrollEmployee(tx *gorm.DB, reconciliation *models.RosterReconciliation, change *models.RosterChange, actorID uint16) (bool, error) {
+	var count int64
+	err := tx.Model(&models.EmployeeData{}).
+		Where("appraisal_id = ? AND toss_emp_id = ?", reconciliation.AppraisalID, change.EmployeeID).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	employeeData := models.EmployeeData{
+		AppraisalID:     reconciliation.AppraisalID,
+		TossEmpID:       change.EmployeeID,
+		EmployeeName:    change.EmployeeName,
+		EmployeeImage:   change.EmployeeImage,
+		TeamID:          reconciliation.TeamID,
+		TeamName:        reconciliation.TeamName,
+		Designation:     change.Designation,
+		DesignationName: change.DesignationName,
+		AppraisalStatus: constants.APPRAISAL_STATUS_PENDING,
+	}
+	if err := tx.Create(&employeeData).Error; err != nil {
+		return false, err
+	}
+
+	var kpiIDs []uint16
+	if len(change.KpiIDs) > 0 {
+		if err := tx.Model(&models.Kpi{}).Where("id IN ?", []int64(change.KpiIDs)).Order("id ASC").Pluck("id", &kpiIDs).Error; err != nil {
+			return false, err
+		}
+	}
+	for _, kpiID := range kpiIDs {
+		appraisalKpi := models.AppraisalKpi{
+			AppraisalID: reconciliation.AppraisalID,
+			EmployeeID:  change.EmployeeID,
+			KpiID:       kpiID,
+			Status:      "pending",
+		}
+		if err := tx.Omit("Kpi").Create(&appraisalKpi).Error; err != nil {
+			return false, err
+		}
+	}
+
+	err = RecordHistory(tx, &models.AppraisalHistory{
+		AppraisalID: reconciliation.AppraisalID,
+		EmployeeID:  change.EmployeeID,
+		Action:      constants.HISTORY_ACTION_ENROLLED,
+		ActorID:     actorID,
+		Details:     fmt.Sprintf("reconciliation_id %v with %v kpis", reconciliation.ID, len(kpiIDs)),
+	})
+	return err == nil, err
+}
+
+func withdrawEmployee(tx *gorm.DB, reconciliation *models.RosterReconciliation, change *models.RosterChange, actorID uint16) (bool, error) {
+	result := tx.Where("appraisal_id = ? AND toss_emp_id = ? AND appraisal_status = ?",
+		reconciliation.AppraisalID, change.EmployeeID, constants.APPRAISAL_STATUS_PENDING).
+		Delete(&models.EmployeeData{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	// Scores stay with the withdrawn kpis
+	err := tx.Where("appraisal_id = ? AND employee_id = ?", reconciliation.AppraisalID, change.EmployeeID).
+		Delete(&models.AppraisalKpi{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	err = RecordHistory(tx, &models.AppraisalHistory{
+		AppraisalID: reconciliation.AppraisalID,
+		EmployeeID:  change.EmployeeID,
+		Action:      constants.HISTORY_ACTION_WITHDRAWN,
+		ActorID:     actorID,
+		Details:     fmt.Sprintf("reconciliation_id %v with %v scored kpis", reconciliation.ID, change.ScoredKpis),
+	})
+	return err == nil, err
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// computedScoresQuery computes the score of every employee of an appraisal as the
+// average of the score percentages of their KPIs, weighted by the KPI weight.
+// Scores without a percentage (feedback, or KPIs without a rating scale) are left out.
+// The weights of transferred employees are split between their supervisors.
+func computedScoresQuery(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Table("scores").
+		Select(`appraisal_kpis.appraisal_id, appraisal_kpis.employee_id,
+			SUM(scores.percentage * kpis.kpi_weight * `+splitScoreFactor+`)
+			/ NULLIF(SUM(kpis.kpi_weight * `+splitScoreFactor+`), 0) AS computed_score`).
+		Joins("JOIN appraisal_kpis ON appraisal_kpis.id = scores.appraisal_kpi_id AND appraisal_kpis.deleted_at IS NULL").
+		Joins("JOIN kpis ON kpis.id = appraisal_kpis.kpi_id").
+		Joins(`LEFT JOIN (?) AS tr ON tr.appraisal_id = appraisal_kpis.appraisal_id
+			AND tr.employee_id = appraisal_kpis.employee_id AND tr.split_scoring`, latestTransfersQuery(db)).
+		Where("scores.deleted_at IS NULL AND scores.percentage IS NOT NULL").
+		Group("appraisal_kpis.appraisal_id, appraisal_kpis.employee_id")
+}
+
+// latestAdjustmentsQuery returns the most recent score adjustment of every
+// employee of an appraisal.
+func latestAdjustmentsQuery(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Table("score_adjustments").
+		Select("DISTINCT ON (appraisal_id, employee_id) appraisal_id, employee_id, adjusted_score").
+		Where("deleted_at IS NULL").
+		Order("appraisal_id, employee_id, id DESC")
+}
+
+// EmployeeResultsQuery selects a models.EmployeeResult row for every employee of
+// every appraisal. The final score is the latest adjusted score if the employee
+// was adjusted, the computed score otherwise. Raw scores are never modified.
+// Callers narrow it down with conditions on employee_data and appraisals.
+func EmployeeResultsQuery(db *gorm.DB) *gorm.DB {
+	return db.Table("employee_data").
+		Select(`employee_data.appraisal_id, appraisals.appraisal_name, appraisals.appraisal_year,
+			appraisals.appraisal_type_str AS appraisal_type, employee_data.toss_emp_id AS employee_id,
+			employee_data.employee_name, employee_data.team_id, employee_data.team_name,
+			employee_data.designation, employee_data.designation_name,
+			`+employeeSupervisorIDColumn+` AS supervisor_id, `+employeeSupervisorNameColumn+` AS supervisor_name, cs.computed_score,
+			COALESCE(adj.adjusted_score, cs.computed_score) AS final_score,
+			adj.adjusted_score IS NOT NULL AS adjusted, ack.decision AS acknowledgement, ack.acknowledged_at`).
+		Joins("JOIN appraisals ON appraisals.id = employee_data.appraisal_id AND appraisals.deleted_at IS NULL").
+		Joins("LEFT JOIN (?) AS cs ON cs.appraisal_id = employee_data.appraisal_id AND cs.employee_id = employee_data.toss_emp_id", computedScoresQuery(db)).
+		Joins("LEFT JOIN (?) AS adj ON adj.appraisal_id = employee_data.appraisal_id AND adj.employee_id = employee_data.toss_emp_id", latestAdjustmentsQuery(db)).
+		Joins(`LEFT JOIN acknowledgements AS ack ON ack.appraisal_id = employee_data.appraisal_id
+			AND ack.employee_id = employee_data.toss_emp_id AND ack.deleted_at IS NULL`).
+		Where("employee_data.deleted_at IS NULL")
+}
+
+func GetEmployeeResults(db *gorm.DB, results *[]models.EmployeeResult) error {
+	log.Info("Getting employee results")
+
+	err := EmployeeResultsQuery(db).Order("employee_data.appraisal_id ASC, employee_data.toss_emp_id ASC").Scan(results).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return AttachResultLabels(db, *results)
+}
+
+// GetEmployeeResult returns the result of a single employee of an appraisal
+func GetEmployeeResult(db *gorm.DB, appraisalID, empID uint64) (models.EmployeeResult, error) {
+	var result models.EmployeeResult
+
+	tx := EmployeeResultsQuery(db).
+		Where("employee_data.appraisal_id = ? AND employee_data.toss_emp_id = ?", appraisalID, empID).
+		Limit(1).Scan(&result)
+	if tx.Error != nil {
+		log.Error(tx.Error.Error())
+		return result, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return result, gorm.ErrRecordNotFound
+	}
+
+	return result, nil
+}
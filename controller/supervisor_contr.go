@@ -1,8 +1,7 @@
 package controller
 
 import (
-	"errors"
-
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	"github.com/mrehanabbasi/appraisal-system-backend/models"
 	"gorm.io/gorm"
 )
@@ -14,7 +13,7 @@ func CreateSupervisor(db *gorm.DB, name string, email string, roleName string, r
 	// Check if the email already exists in the employees table
 	var existingEmployee models.Employee
 	if err := db.Table("employees").Where("email = ?", email).First(&existingEmployee).Error; err == nil {
-		return nil, errors.New("email already exists")
+		return nil, apperrors.Conflict("email already exists")
 	}
 
 	// Create a new Employee object
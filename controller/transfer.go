@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// The supervisor of an employee of an appraisal: the supervisor the employee was
+// transferred to, the supervisor of the appraisal otherwise
+const (
+	employeeSupervisorIDColumn   = "COALESCE(NULLIF(employee_data.supervisor_id, 0), appraisals.supervisor_id)"
+	employeeSupervisorNameColumn = "CASE WHEN employee_data.supervisor_id != 0 THEN employee_data.supervisor_name ELSE appraisals.supervisor_name END"
+)
+
+// splitScoreFactor is the share of a score in the computed score of the employee.
+// When the latest transfer of the employee splits scoring, the scores of the
+// previous supervisor count for their weight and the scores of the new supervisor
+// for the rest. Scores of delegates count as the scores of their delegators.
+const splitScoreFactor = `CASE WHEN tr.appraisal_id IS NULL THEN 1
+	WHEN COALESCE(NULLIF(scores.on_behalf_of, 0), scores.evaluator_id) = tr.from_supervisor_id THEN tr.previous_supervisor_weight / 100
+	WHEN COALESCE(NULLIF(scores.on_behalf_of, 0), scores.evaluator_id) = tr.to_supervisor_id THEN 1 - tr.previous_supervisor_weight / 100
+	ELSE 1 END`
+
+// latestTransfersQuery returns the most recent transfer of every employee of an
+// appraisal. Callers join it on split_scoring to only split the scoring of the
+// employees whose latest transfer asked for it.
+func latestTransfersQuery(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Table("transfers").
+		Select(`DISTINCT ON (appraisal_id, employee_id) appraisal_id, employee_id,
+			from_supervisor_id, to_supervisor_id, split_scoring, previous_supervisor_weight`).
+		Where("deleted_at IS NULL").
+		Order("appraisal_id, employee_id, id DESC")
+}
+
+// TransferEmployee moves the employee of an in-flight appraisal to the supervisor
+// and team of the transfer. The unscored Team and Role kpis of the previous team
+// and designation are withdrawn and the kpis of the new ones are assigned. Kpis
+// already scored stay with the employee.
+func TransferEmployee(db *gorm.DB, transfer *models.Transfer) (*models.Transfer, error) {
+	log.Info("Transferring employee")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var appraisal models.Appraisal
+		if err := tx.Model(&models.Appraisal{}).First(&appraisal, transfer.AppraisalID).Error; err != nil {
+			return err
+		}
+		if appraisal.Status == nil || !*appraisal.Status {
+			return apperrors.Invalid("only employees of active appraisals are transferred")
+		}
+
+		var employee models.EmployeeData
+		err := tx.Model(&models.EmployeeData{}).
+			Where("appraisal_id = ? AND toss_emp_id = ?", transfer.AppraisalID, transfer.EmployeeID).
+			First(&employee).Error
+		if err != nil {
+			return err
+		}
+		if employee.AppraisalStatus == constants.APPRAISAL_STATUS_PUBLISHED {
+			return apperrors.Conflict("results of the employee are already published")
+		}
+
+		transfer.FromSupervisorID, transfer.FromSupervisorName = appraisal.SupervisorID, appraisal.SupervisorName
+		if employee.SupervisorID != 0 {
+			transfer.FromSupervisorID, transfer.FromSupervisorName = employee.SupervisorID, employee.SupervisorName
+		}
+		transfer.FromTeamID, transfer.FromTeamName = employee.TeamID, employee.TeamName
+		transfer.FromDesignation = employee.Designation
+
+		if transfer.ToSupervisorID == transfer.FromSupervisorID && transfer.ToTeamID == transfer.FromTeamID &&
+			transfer.ToDesignation == transfer.FromDesignation {
+			return apperrors.Conflict("employee is already with the supervisor and team")
+		}
+		if transfer.SplitScoring && transfer.ToSupervisorID == transfer.FromSupervisorID {
+			return apperrors.Invalid("scoring is only split between different supervisors")
+		}
+
+		if err := reassignKpis(tx, transfer, constants.ASSIGN_TYPE_TEAM, transfer.FromTeamID, transfer.ToTeamID); err != nil {
+			return err
+		}
+		if err := reassignKpis(tx, transfer, constants.ASSIGN_TYPE_ROLE, transfer.FromDesignation, transfer.ToDesignation); err != nil {
+			return err
+		}
+
+		// The supervisor of the appraisal needs no override
+		supervisorID, supervisorName := transfer.ToSupervisorID, transfer.ToSupervisorName
+		if supervisorID == appraisal.SupervisorID {
+			supervisorID, supervisorName = 0, ""
+		}
+		err = tx.Model(&employee).Updates(map[string]interface{}{
+			"supervisor_id":    supervisorID,
+			"supervisor_name":  supervisorName,
+			"team_id":          transfer.ToTeamID,
+			"team_name":        transfer.ToTeamName,
+			"designation":      transfer.ToDesignation,
+			"designation_name": transfer.ToDesignationName,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+
+		details := fmt.Sprintf("transfer_id %v from supervisor %v team %v to supervisor %v team %v",
+			transfer.ID, transfer.FromSupervisorID, transfer.FromTeamID, transfer.ToSupervisorID, transfer.ToTeamID)
+		if transfer.SplitScoring {
+			details += fmt.Sprintf(", previous supervisor weight %v%%", transfer.PreviousSupervisorWeight)
+		}
+		return RecordHistory(tx, &models.AppraisalHistory{
+			AppraisalID: transfer.AppraisalID,
+			EmployeeID:  transfer.EmployeeID,
+			Action:      constants.HISTORY_ACTION_TRANSFERRED,
+			ActorID:     transfer.TransferredBy,
+			Details:     details,
+		})
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// reassignKpis withdraws the unscored kpis of the employee assigned through the
+// previous team or designation and assigns the kpis of the new one. Questionnaires
+// count as scored once answered.
+func reassignKpis(tx *gorm.DB, transfer *models.Transfer, assignType string, fromID, toID uint16) error {
+	if fromID == toID {
+		return nil
+	}
+
+	var removedIDs []int64
+	err := tx.Model(&models.AppraisalKpi{}).
+		Joins("JOIN kpis ON kpis.id = appraisal_kpis.kpi_id").
+		Where("appraisal_kpis.appraisal_id = ? AND appraisal_kpis.employee_id = ?", transfer.AppraisalID, transfer.EmployeeID).
+		Where("kpis.assign_type_name = ? AND kpis.selected_assign_id = ?", assignType, fromID).
+		Where("NOT EXISTS (SELECT 1 FROM scores WHERE scores.appraisal_kpi_id = appraisal_kpis.id AND scores.deleted_at IS NULL)").
+		Where("NOT EXISTS (SELECT 1 FROM questionnaire_answers WHERE questionnaire_answers.appraisal_kpi_id = appraisal_kpis.id AND questionnaire_answers.deleted_at IS NULL)").
+		Pluck("appraisal_kpis.id", &removedIDs).Error
+	if err != nil {
+		return err
+	}
+	if len(removedIDs) > 0 {
+		if err := tx.Delete(&models.AppraisalKpi{}, removedIDs).Error; err != nil {
+			return err
+		}
+		transfer.RemovedKpiIDs = append(transfer.RemovedKpiIDs, removedIDs...)
+	}
+
+	var kpis []models.Kpi
+	err = tx.Model(&models.Kpi{}).
+		Where("assign_type_name = ? AND selected_assign_id = ?", assignType, toID).
+		Where(`NOT EXISTS (SELECT 1 FROM appraisal_kpis WHERE appraisal_kpis.kpi_id = kpis.id
+			AND appraisal_kpis.appraisal_id = ? AND appraisal_kpis.employee_id = ? AND appraisal_kpis.deleted_at IS NULL)`,
+			transfer.AppraisalID, transfer.EmployeeID).
+		Order("id ASC").
+		Find(&kpis).Error
+	if err != nil {
+		return err
+	}
+
+	for _, kpi := range kpis {
+		appraisalKpi := models.AppraisalKpi{
+			AppraisalID: transfer.AppraisalID,
+			EmployeeID:  transfer.EmployeeID,
+			KpiID:       kpi.ID,
+			Status:      "pending",
+		}
+		if err := tx.Omit("Kpi").Create(&appraisalKpi).Error; err != nil {
+			return err
+		}
+		transfer.AddedKpiIDs = append(transfer.AddedKpiIDs, int64(appraisalKpi.ID))
+	}
+
+	return nil
+}
+
+func GetTransfers(db *gorm.DB, transfers *[]models.Transfer, appraisalID, empID uint64) error {
+	log.Info("Getting transfers of the employee")
+
+	err := db.Model(&models.Transfer{}).
+		Where("appraisal_id = ? AND employee_id = ?", appraisalID, empID).
+		Order("effective_at ASC, id ASC").
+		Find(transfers).Error
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetEffectiveSupervisor returns the supervisor the employee is evaluated by in
+// the appraisal: the one they were transferred to, or else the supervisor of the
+// appraisal
+func GetEffectiveSupervisor(db *gorm.DB, appraisal *models.Appraisal, empID uint16) (uint16, error) {
+	var employee models.EmployeeData
+	err := db.Model(&models.EmployeeData{}).
+		Where("appraisal_id = ? AND toss_emp_id = ?", appraisal.ID, empID).
+		Limit(1).Find(&employee).Error
+	if err != nil {
+		log.Error(err.Error())
+		return 0, err
+	}
+
+	if employee.SupervisorID != 0 {
+		return employee.SupervisorID, nil
+	}
+	return appraisal.SupervisorID, nil
+}
+
+// GetSplitSupervisors returns the previous supervisors the scoring of the
+// employee is still split with
+func GetSplitSupervisors(db *gorm.DB, appraisalID, empID uint16) ([]uint16, error) {
+	var supervisorIDs []uint16
+	err := db.Table("(?) AS tr", latestTransfersQuery(db)).
+		Where("tr.appraisal_id = ? AND tr.employee_id = ? AND tr.split_scoring", appraisalID, empID).
+		Pluck("tr.from_supervisor_id", &supervisorIDs).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return supervisorIDs, nil
+}
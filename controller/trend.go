@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"math"
+	"sort"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// chronologicalOrder sorts the appraisals of an employee oldest first, the
+// mid-year appraisal of a year before its annual appraisal
+const chronologicalOrder = "appraisals.appraisal_year ASC, CASE WHEN appraisals.appraisal_type_str = 'Mid-Year' THEN 0 ELSE 1 END ASC, employee_data.appraisal_id ASC"
+
+// GetPerformanceHistory aggregates the results of the employee across all their
+// appraisals, linked by the TOSS employee id, into overall and per kpi trends.
+// With publishedOnly only the appraisals with published results are included.
+func GetPerformanceHistory(db *gorm.DB, empID uint16, publishedOnly bool) (models.PerformanceHistory, error) {
+	log.Info("Getting performance history of the employee")
+
+	history := models.PerformanceHistory{
+		EmployeeID:         empID,
+		Appraisals:         make([]models.AppraisalPoint, 0),
+		KpiTrends:          make([]models.KpiTrend, 0),
+		DesignationChanges: make([]models.AssignmentChange, 0),
+		TeamChanges:        make([]models.AssignmentChange, 0),
+		YearOverYear:       make([]models.YearDelta, 0),
+	}
+
+	query := EmployeeResultsQuery(db).Where("employee_data.toss_emp_id = ?", empID)
+	if publishedOnly {
+		query = query.Where("employee_data.appraisal_status = ?", constants.APPRAISAL_STATUS_PUBLISHED)
+	}
+
+	var results []models.EmployeeResult
+	if err := query.Order(chronologicalOrder).Scan(&results).Error; err != nil {
+		log.Error(err.Error())
+		return history, err
+	}
+	if len(results) == 0 {
+		return history, gorm.ErrRecordNotFound
+	}
+
+	appraisalIDs := make([]uint16, 0, len(results))
+	for k, result := range results {
+		appraisalIDs = append(appraisalIDs, result.AppraisalID)
+		history.EmployeeName = result.EmployeeName
+
+		point := models.AppraisalPoint{
+			AppraisalID:     result.AppraisalID,
+			AppraisalName:   result.AppraisalName,
+			AppraisalYear:   result.AppraisalYear,
+			AppraisalType:   result.AppraisalType,
+			TeamID:          result.TeamID,
+			TeamName:        result.TeamName,
+			Designation:     result.Designation,
+			DesignationName: result.DesignationName,
+			SupervisorID:    result.SupervisorID,
+			SupervisorName:  result.SupervisorName,
+			ComputedScore:   result.ComputedScore,
+			FinalScore:      result.FinalScore,
+		}
+		if k == 0 {
+			history.Appraisals = append(history.Appraisals, point)
+			continue
+		}
+
+		previous := results[k-1]
+		point.Delta = scoreDelta(result.FinalScore, previous.FinalScore)
+		history.Appraisals = append(history.Appraisals, point)
+
+		if result.Designation != previous.Designation {
+			history.DesignationChanges = append(history.DesignationChanges, models.AssignmentChange{
+				AppraisalID:   result.AppraisalID,
+				AppraisalYear: result.AppraisalYear,
+				AppraisalType: result.AppraisalType,
+				FromID:        previous.Designation,
+				FromName:      previous.DesignationName,
+				ToID:          result.Designation,
+				ToName:        result.DesignationName,
+			})
+		}
+		if result.TeamID != previous.TeamID {
+			history.TeamChanges = append(history.TeamChanges, models.AssignmentChange{
+				AppraisalID:   result.AppraisalID,
+				AppraisalYear: result.AppraisalYear,
+				AppraisalType: result.AppraisalType,
+				FromID:        previous.TeamID,
+				FromName:      previous.TeamName,
+				ToID:          result.TeamID,
+				ToName:        result.TeamName,
+			})
+		}
+	}
+
+	history.YearOverYear = yearOverYearDeltas(results)
+
+	trends, err := getKpiTrends(db, empID, appraisalIDs)
+	if err != nil {
+		return history, err
+	}
+	history.KpiTrends = trends
+
+	return history, nil
+}
+
+// getKpiTrends returns the score percentages of every kpi the employee was
+// appraised on, in the order of the appraisals
+func getKpiTrends(db *gorm.DB, empID uint16, appraisalIDs []uint16) ([]models.KpiTrend, error) {
+	var rows []struct {
+		KpiID         uint16
+		KpiName       string
+		AppraisalID   uint16
+		AppraisalYear uint16
+		AppraisalType string
+		Percentage    *float64
+	}
+
+	err := db.Session(&gorm.Session{NewDB: true}).Table("appraisal_kpis").
+		Select(`kpis.id AS kpi_id, kpis.kpi_name, appraisal_kpis.appraisal_id, appraisals.appraisal_year,
+			appraisals.appraisal_type_str AS appraisal_type, AVG(scores.percentage) AS percentage`).
+		Joins("JOIN kpis ON kpis.id = appraisal_kpis.kpi_id").
+		Joins("JOIN appraisals ON appraisals.id = appraisal_kpis.appraisal_id").
+		Joins("LEFT JOIN scores ON scores.appraisal_kpi_id = appraisal_kpis.id AND scores.deleted_at IS NULL").
+		Where("appraisal_kpis.deleted_at IS NULL AND appraisal_kpis.employee_id = ? AND appraisal_kpis.appraisal_id IN ?", empID, appraisalIDs).
+		Group("kpis.id, kpis.kpi_name, appraisal_kpis.appraisal_id, appraisals.appraisal_year, appraisals.appraisal_type_str").
+		Scan(&rows).Error
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	position := make(map[uint16]int, len(appraisalIDs))
+	for k, appraisalID := range appraisalIDs {
+		position[appraisalID] = k
+	}
+
+	trends := make([]models.KpiTrend, 0)
+	trendIndex := make(map[uint16]int)
+	for _, row := range rows {
+		k, ok := trendIndex[row.KpiID]
+		if !ok {
+			k = len(trends)
+			trendIndex[row.KpiID] = k
+			trends = append(trends, models.KpiTrend{KpiID: row.KpiID, KpiName: row.KpiName, Points: make([]models.KpiPoint, 0)})
+		}
+		trends[k].Points = append(trends[k].Points, models.KpiPoint{
+			AppraisalID:   row.AppraisalID,
+			AppraisalYear: row.AppraisalYear,
+			AppraisalType: row.AppraisalType,
+			Percentage:    roundedScore(row.Percentage),
+		})
+	}
+
+	for k := range trends {
+		points := trends[k].Points
+		sort.SliceStable(points, func(i, j int) bool {
+			return position[points[i].AppraisalID] < position[points[j].AppraisalID]
+		})
+	}
+
+	return trends, nil
+}
+
+// yearOverYearDeltas compares the final score of every year with the closest
+// earlier year of the same appraisal type. With several appraisals of a type in
+// a year, the last one counts.
+func yearOverYearDeltas(results []models.EmployeeResult) []models.YearDelta {
+	deltas := make([]models.YearDelta, 0)
+	last := make(map[string]models.EmployeeResult)
+
+	for k, result := range results {
+		if k+1 < len(results) && results[k+1].AppraisalYear == result.AppraisalYear && results[k+1].AppraisalType == result.AppraisalType {
+			continue
+		}
+
+		if previous, ok := last[result.AppraisalType]; ok {
+			deltas = append(deltas, models.YearDelta{
+				AppraisalYear: result.AppraisalYear,
+				AppraisalType: result.AppraisalType,
+				FinalScore:    result.FinalScore,
+				PreviousYear:  previous.AppraisalYear,
+				PreviousScore: previous.FinalScore,
+				Delta:         scoreDelta(result.FinalScore, previous.FinalScore),
+			})
+		}
+		last[result.AppraisalType] = result
+	}
+
+	return deltas
+}
+
+// scoreDelta returns the difference of two scores, nil if any is missing
+func scoreDelta(score, previous *float64) *float64 {
+	if score == nil || previous == nil {
+		return nil
+	}
+
+	delta := *score - *previous
+	return roundedScore(&delta)
+}
+
+// roundedScore rounds the score to two decimals
+func roundedScore(score *float64) *float64 {
+	if score == nil {
+		return nil
+	}
+
+	rounded := math.Round(*score*100) / 100
+	return &rounded
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+func score(value float64) *float64 {
+	return &value
+}
+
+func TestScoreDelta(t *testing.T) {
+	tests := []struct {
+		name            string
+		score, previous *float64
+		want            *float64
+	}{
+		{name: "improvement", score: score(82.5), previous: score(70), want: score(12.5)},
+		{name: "decline", score: score(60), previous: score(75.25), want: score(-15.25)},
+		{name: "rounded to two decimals", score: score(80.123), previous: score(70.001), want: score(10.12)},
+		{name: "missing score", previous: score(70)},
+		{name: "missing previous score", score: score(70)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreDelta(tt.score, tt.previous); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", formatDelta(got), formatDelta(tt.want))
+			}
+		})
+	}
+}
+
+func TestYearOverYearDeltas(t *testing.T) {
+	midYear, annual := constants.MID_YEAR_APPRAISAL, constants.ANNUAL_APPRAISAL
+
+	tests := []struct {
+		name    string
+		results []models.EmployeeResult
+		want    []models.YearDelta
+	}{
+		{
+			name: "consecutive years",
+			results: []models.EmployeeResult{
+				{AppraisalYear: 2023, AppraisalType: annual, FinalScore: score(70)},
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(80)},
+			},
+			want: []models.YearDelta{
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(80), PreviousYear: 2023, PreviousScore: score(70), Delta: score(10)},
+			},
+		},
+		{
+			name: "closest earlier year after a gap",
+			results: []models.EmployeeResult{
+				{AppraisalYear: 2021, AppraisalType: annual, FinalScore: score(90)},
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(85)},
+			},
+			want: []models.YearDelta{
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(85), PreviousYear: 2021, PreviousScore: score(90), Delta: score(-5)},
+			},
+		},
+		{
+			name: "types are compared separately",
+			results: []models.EmployeeResult{
+				{AppraisalYear: 2023, AppraisalType: midYear, FinalScore: score(60)},
+				{AppraisalYear: 2023, AppraisalType: annual, FinalScore: score(65)},
+				{AppraisalYear: 2024, AppraisalType: midYear, FinalScore: score(70)},
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(80)},
+			},
+			want: []models.YearDelta{
+				{AppraisalYear: 2024, AppraisalType: midYear, FinalScore: score(70), PreviousYear: 2023, PreviousScore: score(60), Delta: score(10)},
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(80), PreviousYear: 2023, PreviousScore: score(65), Delta: score(15)},
+			},
+		},
+		{
+			name: "last appraisal of a type in a year counts",
+			results: []models.EmployeeResult{
+				{AppraisalYear: 2023, AppraisalType: annual, FinalScore: score(50)},
+				{AppraisalYear: 2023, AppraisalType: annual, FinalScore: score(60)},
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(75)},
+			},
+			want: []models.YearDelta{
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(75), PreviousYear: 2023, PreviousScore: score(60), Delta: score(15)},
+			},
+		},
+		{
+			name: "missing score has no delta",
+			results: []models.EmployeeResult{
+				{AppraisalYear: 2023, AppraisalType: annual},
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(75)},
+			},
+			want: []models.YearDelta{
+				{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(75), PreviousYear: 2023},
+			},
+		},
+		{
+			name:    "single year",
+			results: []models.EmployeeResult{{AppraisalYear: 2024, AppraisalType: annual, FinalScore: score(75)}},
+			want:    []models.YearDelta{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := yearOverYearDeltas(tt.results); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func formatDelta(delta *float64) interface{} {
+	if delta == nil {
+		return nil
+	}
+	return *delta
+}
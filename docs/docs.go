@@ -0,0 +1,47 @@
+// Package docs serves the OpenAPI 3 specification of the API and a Swagger UI
+// for it. The operations come from the annotations of the handlers of the
+// service package, which openapigen turns into operations_gen.go, and the
+// schemas from the models the annotations name.
+//
+// A handler is annotated with lines of its doc comment:
+//
+//	@summary <text>                   what the operation does
+//	@query <name> <type> <text>       a query param, of type string, integer, number, boolean or date
+//	@form <name> <type> <text>        a multipart form field, of type string, integer or file
+//	@body <type>                      the JSON request body, like models.Kpi or []models.Score
+//	@success <status> [<type>]        a response, without content if the type is left out
+//	@success <status> file <media>    a response streaming a file of the media type
+//	@auth                             the caller is identified by their jwt
+//
+// Failures are documented once for every operation as problem+json responses.
+package docs
+
+import (
+	"reflect"
+)
+
+//go:generate go run ../cmd/openapigen -service ../service -out operations_gen.go
+
+// operation is the documentation of the handler of a route
+type operation struct {
+	Summary   string
+	Auth      bool
+	Query     []param
+	Form      []param
+	Body      reflect.Type
+	Responses []response
+}
+
+type param struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// response is a response of an operation. A response without a type has no
+// content, unless it is a file of the media type.
+type response struct {
+	Status    int
+	Type      reflect.Type
+	MediaType string
+}
@@ -0,0 +1,264 @@
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+type document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       info                            `json:"info"`
+	Tags       []tag                           `json:"tags"`
+	Paths      map[string]map[string]*pathItem `json:"paths"`
+	Components components                      `json:"components"`
+}
+
+type info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type tag struct {
+	Name string `json:"name"`
+}
+
+type pathItem struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary,omitempty"`
+	Tags        []string               `json:"tags"`
+	Parameters  []parameter            `json:"parameters,omitempty"`
+	RequestBody *requestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]apiResponse `json:"responses"`
+	Security    []map[string][]string  `json:"security,omitempty"`
+	Handler     string                 `json:"x-handler"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type apiResponse struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type components struct {
+	Schemas         schemas                   `json:"schemas"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat"`
+}
+
+// Spec is the OpenAPI specification of the routes of the router
+type Spec struct {
+	body []byte
+}
+
+var (
+	pathParam   = regexp.MustCompile(`[:*](\w+)`)
+	handlerName = regexp.MustCompile(`\.\(\*(\w+)\)\.(\w+)-fm$`)
+)
+
+// New documents the routes with the operations of their handlers. It also
+// returns how the routes and the annotations of the handlers drifted apart: the
+// routes whose handler is not documented and the documented handlers that no
+// route uses.
+func New(routes gin.RoutesInfo) (*Spec, []string) {
+	var drift []string
+	doc := document{
+		OpenAPI: "3.0.3",
+		Info:    info{Title: "Appraisal System API", Version: "1.0.0"},
+		Paths:   map[string]map[string]*pathItem{},
+		Components: components{
+			Schemas: schemas{},
+			SecuritySchemes: map[string]securityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	problem := doc.Components.Schemas.schemaOf(reflect.TypeOf(models.Problem{}))
+
+	// Methods of several services are told apart by the service
+	handlers := map[string]string{}
+	methods := map[string]int{}
+	for _, route := range routes {
+		key := handlerKey(route.Handler)
+		if _, ok := handlers[key]; !ok && key != "" {
+			methods[key[strings.Index(key, ".")+1:]]++
+		}
+		handlers[key] = route.Handler
+	}
+
+	tags := map[string]bool{}
+	for _, route := range routes {
+		key := handlerKey(route.Handler)
+		op, ok := operations[key]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%s %s is handled by %s, which has no annotations", route.Method, route.Path, route.Handler))
+			continue
+		}
+
+		service, method := key[:strings.Index(key, ".")], key[strings.Index(key, ".")+1:]
+		operationID := method
+		if methods[method] > 1 {
+			operationID = strings.TrimSuffix(service, "Service") + method
+		}
+
+		segments := strings.Split(strings.TrimPrefix(route.Path, "/v1/"), "/")
+		item := &pathItem{
+			OperationID: operationID,
+			Summary:     op.Summary,
+			Tags:        []string{segments[0]},
+			Responses:   map[string]apiResponse{},
+			Handler:     key,
+		}
+		tags[segments[0]] = true
+
+		for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			item.Parameters = append(item.Parameters, parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &schema{Type: "integer", Format: "int64"},
+			})
+		}
+		for _, query := range op.Query {
+			item.Parameters = append(item.Parameters, parameter{
+				Name:        query.Name,
+				In:          "query",
+				Description: query.Description,
+				Schema:      paramSchema(query.Type),
+			})
+		}
+
+		switch {
+		case len(op.Form) > 0:
+			form := &schema{Type: "object", Properties: map[string]*schema{}}
+			for _, field := range op.Form {
+				form.Properties[field.Name] = paramSchema(field.Type)
+				form.Required = append(form.Required, field.Name)
+			}
+			item.RequestBody = &requestBody{
+				Required: true,
+				Content:  map[string]mediaType{"multipart/form-data": {Schema: form}},
+			}
+		case op.Body != nil:
+			item.RequestBody = &requestBody{
+				Required: true,
+				Content:  map[string]mediaType{"application/json": {Schema: doc.Components.Schemas.schemaOf(op.Body)}},
+			}
+		}
+
+		// The responses of a status in several formats share their entry
+		for _, resp := range op.Responses {
+			status := strconv.Itoa(resp.Status)
+			documented, ok := item.Responses[status]
+			if !ok {
+				documented = apiResponse{Description: http.StatusText(resp.Status)}
+			}
+			if resp.MediaType != "" || resp.Type != nil {
+				if documented.Content == nil {
+					documented.Content = map[string]mediaType{}
+				}
+				if resp.MediaType != "" {
+					documented.Content[resp.MediaType] = mediaType{Schema: &schema{Type: "string", Format: "binary"}}
+				} else {
+					documented.Content["application/json"] = mediaType{Schema: doc.Components.Schemas.schemaOf(resp.Type)}
+				}
+			}
+			item.Responses[status] = documented
+		}
+		item.Responses["default"] = apiResponse{
+			Description: "Problem",
+			Content:     map[string]mediaType{"application/problem+json": {Schema: problem}},
+		}
+
+		if op.Auth {
+			item.Security = []map[string][]string{{"bearerAuth": {}}}
+		}
+
+		docPath := pathParam.ReplaceAllString(route.Path, "{$1}")
+		if doc.Paths[docPath] == nil {
+			doc.Paths[docPath] = map[string]*pathItem{}
+		}
+		doc.Paths[docPath][strings.ToLower(route.Method)] = item
+	}
+
+	for key := range operations {
+		if _, ok := handlers[key]; !ok {
+			drift = append(drift, fmt.Sprintf("%s is annotated, but no route is handled by it", key))
+		}
+	}
+	sort.Strings(drift)
+
+	for name := range tags {
+		doc.Tags = append(doc.Tags, tag{Name: name})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	body, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+
+	return &Spec{body: body}, drift
+}
+
+// Serve writes the specification
+func (s *Spec) Serve(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", s.body)
+}
+
+// handlerKey returns the service and the method of a handler of the service
+// package from its function name, like EmployeeService.GetEmployee
+func handlerKey(name string) string {
+	if !strings.Contains(name, "/service.") {
+		return ""
+	}
+
+	match := handlerName.FindStringSubmatch(name)
+	if match == nil {
+		return ""
+	}
+
+	return match[1] + "." + match[2]
+}
+
+func paramSchema(paramType string) *schema {
+	switch paramType {
+	case "integer":
+		return &schema{Type: "integer", Format: "int64"}
+	case "date":
+		return &schema{Type: "string", Format: "date"}
+	case "file":
+		return &schema{Type: "string", Format: "binary"}
+	}
+
+	return &schema{Type: paramType}
+}
@@ -0,0 +1,1128 @@
+// Code generated by openapigen from the annotations of the handlers. DO NOT EDIT.
+
+package docs
+
+import (
+	"reflect"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+var operations = map[string]operation{
+	"AnalyticsService.CreateDistributionCurve": {
+		Summary: "Create a distribution curve",
+		Body:    reflect.TypeOf((*models.DistributionCurve)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.DistributionCurve)(nil)).Elem()},
+		},
+	},
+	"AnalyticsService.DeleteDistributionCurve": {
+		Summary: "Delete a distribution curve",
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"AnalyticsService.GetAllDistributionCurves": {
+		Summary: "List the distribution curves",
+		Query: []param{
+			{Name: "assign_type", Type: "integer", Description: "Assign type the curves target"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.DistributionCurve)(nil)).Elem()},
+		},
+	},
+	"AnalyticsService.GetDashboard": {
+		Summary: "Get the progress of an appraisal cycle",
+		Query: []param{
+			{Name: "appraisal_year", Type: "integer", Description: "Year of the appraisal cycle"},
+			{Name: "appraisal_type", Type: "string", Description: "Type of the appraisal cycle"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.CycleDashboard)(nil)).Elem()},
+		},
+	},
+	"AnalyticsService.GetDistribution": {
+		Summary: "Compare the score distribution of an appraisal cycle with the target curves",
+		Query: []param{
+			{Name: "appraisal_year", Type: "integer", Description: "Year of the appraisal cycle"},
+			{Name: "appraisal_type", Type: "string", Description: "Type of the appraisal cycle"},
+			{Name: "group_by", Type: "string", Description: "Either team, the default, or designation"},
+			{Name: "flagged", Type: "boolean", Description: "Only the groups off their target curve"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.DistributionReport)(nil)).Elem()},
+		},
+	},
+	"AnalyticsService.GetDistributionCurveByID": {
+		Summary: "Get a distribution curve",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.DistributionCurve)(nil)).Elem()},
+		},
+	},
+	"AnalyticsService.GetPerformanceHistory": {
+		Summary: "Get the performance history of an employee across their appraisals",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.PerformanceHistory)(nil)).Elem()},
+		},
+	},
+	"AnalyticsService.GetSupervisorCompletion": {
+		Summary: "List the scoring completion of an appraisal cycle per supervisor",
+		Query: []param{
+			{Name: "appraisal_year", Type: "integer", Description: "Year of the appraisal cycle"},
+			{Name: "appraisal_type", Type: "string", Description: "Type of the appraisal cycle"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.CompletionStat)(nil)).Elem()},
+		},
+	},
+	"AnalyticsService.GetTeamCompletion": {
+		Summary: "List the scoring completion of an appraisal cycle per team",
+		Query: []param{
+			{Name: "appraisal_year", Type: "integer", Description: "Year of the appraisal cycle"},
+			{Name: "appraisal_type", Type: "string", Description: "Type of the appraisal cycle"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.CompletionStat)(nil)).Elem()},
+		},
+	},
+	"AnalyticsService.UpdateDistributionCurve": {
+		Summary: "Update a distribution curve",
+		Body:    reflect.TypeOf((*models.DistributionCurve)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.DistributionCurve)(nil)).Elem()},
+		},
+	},
+	"AppealService.AddAppealComment": {
+		Summary: "Comment on an appeal",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.AppealComment)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.AppealComment)(nil)).Elem()},
+		},
+	},
+	"AppealService.AddMyAppealComment": {
+		Summary: "Comment on an appeal of the caller",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.AppealComment)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.AppealComment)(nil)).Elem()},
+		},
+	},
+	"AppealService.FileMyAppeal": {
+		Summary: "File an appeal against the ratings of the caller",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.Appeal)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Appeal)(nil)).Elem()},
+		},
+	},
+	"AppealService.GetAllAppeals": {
+		Summary: "List the appeals",
+		Auth:    true,
+		Query: []param{
+			{Name: "status", Type: "string", Description: "Status of the appeals"},
+			{Name: "routed_to", Type: "string", Description: "Either skip_level or hr"},
+			{Name: "reviewer_id", Type: "integer", Description: "Reviewer of the appeals"},
+			{Name: "appraisal_id", Type: "integer", Description: "Appraisal the appeals contest"},
+			{Name: "employee_id", Type: "integer", Description: "Employee who filed the appeals"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Appeal)(nil)).Elem()},
+		},
+	},
+	"AppealService.GetAppealByID": {
+		Summary: "Get an appeal",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Appeal)(nil)).Elem()},
+		},
+	},
+	"AppealService.GetMyAppeal": {
+		Summary: "Get an appeal of the caller",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Appeal)(nil)).Elem()},
+		},
+	},
+	"AppealService.GetMyAppeals": {
+		Summary: "List the appeals of the caller",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Appeal)(nil)).Elem()},
+		},
+	},
+	"AppealService.ResolveAppeal": {
+		Summary: "Uphold the contested ratings or adjust the final score",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.AppealResolution)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Appeal)(nil)).Elem()},
+		},
+	},
+	"AppealService.ReviewAppeal": {
+		Summary: "Take an appeal under review",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.AppealReview)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Appeal)(nil)).Elem()},
+		},
+	},
+	"AppraisalFlowService.CreateAppraisalFlow": {
+		Summary: "Create an appraisal flow",
+		Body:    reflect.TypeOf((*models.AppraisalFlow)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.AppraisalFlow)(nil)).Elem()},
+		},
+	},
+	"AppraisalFlowService.DeleteAppraisalFlow": {
+		Summary: "Delete an appraisal flow",
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"AppraisalFlowService.GetAllAppraisalFlows": {
+		Summary: "List the appraisal flows",
+		Query: []param{
+			{Name: "flow_name", Type: "string", Description: "Name of the flows"},
+			{Name: "is_active", Type: "boolean", Description: "Whether the flows are active"},
+			{Name: "team_id", Type: "integer", Description: "Team the flows are assigned to"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.AppraisalFlow)(nil)).Elem()},
+		},
+	},
+	"AppraisalFlowService.GetAppraisalFlowByID": {
+		Summary: "Get an appraisal flow",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.AppraisalFlow)(nil)).Elem()},
+		},
+	},
+	"AppraisalFlowService.UpdateAppraisalFlow": {
+		Summary: "Update an appraisal flow",
+		Body:    reflect.TypeOf((*models.AppraisalFlow)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.AppraisalFlow)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.AddScore": {
+		Summary: "Score the kpis of an employee in an appraisal",
+		Body:    reflect.TypeOf((*[]models.Score)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*[]models.Score)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.CreateAppraisal": {
+		Summary: "Queue the creation of an appraisal",
+		Body:    reflect.TypeOf((*models.Appraisal)(nil)).Elem(),
+		Responses: []response{
+			{Status: 202, Type: reflect.TypeOf((*models.Job)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.DeleteAppraisal": {
+		Summary: "Delete an appraisal",
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"AppraisalService.ExportEmployeeReport": {
+		Summary: "Export the report of an employee in an appraisal",
+		Auth:    true,
+		Query: []param{
+			{Name: "include_private", Type: "boolean", Description: "Include the comments private to HR"},
+			{Name: "format", Type: "string", Description: "Either csv, the default, or pdf"},
+		},
+		Responses: []response{
+			{Status: 200, MediaType: "text/csv"},
+			{Status: 200, MediaType: "application/pdf"},
+		},
+	},
+	"AppraisalService.GetAcknowledgement": {
+		Summary: "Get the acknowledgement of the results of an employee",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Acknowledgement)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.GetAllAppraisals": {
+		Summary: "List the appraisals",
+		Query: []param{
+			{Name: "appraisal_name", Type: "string", Description: "Name of the appraisals"},
+			{Name: "supervisor_id", Type: "integer", Description: "Supervisor of the appraisals"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Appraisal)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.GetAllProjects": {
+		Summary: "List the active TOSS projects with their members and supervisors",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf(map[string]interface{}{})},
+		},
+	},
+	"AppraisalService.GetAppraisalByID": {
+		Summary: "Get an appraisal",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.AppraisalResponse)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.GetAppraisalKpisByEmpID": {
+		Summary: "List the kpis of an appraisal",
+		Query: []param{
+			{Name: "employee_id", Type: "integer", Description: "Employee the kpis are assigned to"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.AppraisalKpi)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.GetEmployeeDataByAppraisalID": {
+		Summary: "List the employees of an appraisal",
+		Query: []param{
+			{Name: "toss_emp_id", Type: "integer", Description: "TOSS id of an employee"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.EmployeeData)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.GetHistory": {
+		Summary: "List the actions taken on the appraisal of an employee",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.AppraisalHistory)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.GetQuestionnaire": {
+		Summary: "List the questionnaire kpis of an employee in an appraisal",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.AppraisalKpi)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.GetQuestionnaireReview": {
+		Summary: "Review the graded questionnaire answers of an employee",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.QuestionnaireReview)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.GetScores": {
+		Summary: "List the scores of an employee in an appraisal",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Score)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.GetTransfers": {
+		Summary: "List the transfers of the employee of an appraisal",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Transfer)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.PublishResults": {
+		Summary: "Publish the results of an appraisal to its employees",
+		Body:    reflect.TypeOf((*models.PublishRequest)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf(map[string]interface{}{})},
+		},
+	},
+	"AppraisalService.SubmitQuestionnaire": {
+		Summary: "Submit the questionnaire answers of an employee",
+		Auth:    true,
+		Body:    reflect.TypeOf((*[]models.QuestionnaireAnswer)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*[]models.Score)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.TransferEmployee": {
+		Summary: "Transfer the employee of an appraisal to another supervisor",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.Transfer)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Transfer)(nil)).Elem()},
+		},
+	},
+	"AppraisalService.UpdateAppraisal": {
+		Summary: "Update an appraisal",
+		Body:    reflect.TypeOf((*models.Appraisal)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Appraisal)(nil)).Elem()},
+		},
+	},
+	"AttachmentService.DeleteAttachment": {
+		Summary: "Delete an attachment",
+		Auth:    true,
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"AttachmentService.DownloadAttachment": {
+		Summary: "Download the file of an attachment",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, MediaType: "application/octet-stream"},
+		},
+	},
+	"AttachmentService.GetAttachmentByID": {
+		Summary: "Get an attachment",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Attachment)(nil)).Elem()},
+		},
+	},
+	"AttachmentService.GetAttachments": {
+		Summary: "List the attachments of an appraisal kpi",
+		Auth:    true,
+		Query: []param{
+			{Name: "appraisal_kpi_id", Type: "integer", Description: "Appraisal kpi of the attachments"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Attachment)(nil)).Elem()},
+		},
+	},
+	"AttachmentService.UploadAttachment": {
+		Summary: "Attach evidence to an appraisal kpi",
+		Auth:    true,
+		Form: []param{
+			{Name: "appraisal_kpi_id", Type: "integer", Description: "Appraisal kpi the file is evidence for"},
+			{Name: "file", Type: "file", Description: "The evidence"},
+		},
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Attachment)(nil)).Elem()},
+		},
+	},
+	"CalibrationService.AdjustScore": {
+		Summary: "Adjust the final score of an employee in a calibration session",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.ScoreAdjustment)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.ScoreAdjustment)(nil)).Elem()},
+		},
+	},
+	"CalibrationService.CreateCalibrationSession": {
+		Summary: "Create a calibration session",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.CalibrationSession)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.CalibrationSession)(nil)).Elem()},
+		},
+	},
+	"CalibrationService.DeleteCalibrationSession": {
+		Summary: "Delete a calibration session",
+		Auth:    true,
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"CalibrationService.GetAllCalibrationSessions": {
+		Summary: "List the calibration sessions",
+		Query: []param{
+			{Name: "session_name", Type: "string", Description: "Name of the sessions"},
+			{Name: "is_closed", Type: "boolean", Description: "Whether the sessions are closed"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.CalibrationSession)(nil)).Elem()},
+		},
+	},
+	"CalibrationService.GetCalibrationDistribution": {
+		Summary: "Compare the score distributions of the supervisors of a calibration session",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.SupervisorDistribution)(nil)).Elem()},
+		},
+	},
+	"CalibrationService.GetCalibrationEmployees": {
+		Summary: "List the computed and final scores of the employees of a calibration session",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.EmployeeResult)(nil)).Elem()},
+		},
+	},
+	"CalibrationService.GetCalibrationSessionByID": {
+		Summary: "Get a calibration session",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.CalibrationSession)(nil)).Elem()},
+		},
+	},
+	"CalibrationService.UpdateCalibrationSession": {
+		Summary: "Update a calibration session",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.CalibrationSession)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.CalibrationSession)(nil)).Elem()},
+		},
+	},
+	"CampaignService.CreateCampaign": {
+		Summary: "Queue the creation of a team appraisal for every team",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.Campaign)(nil)).Elem(),
+		Responses: []response{
+			{Status: 202, Type: reflect.TypeOf((*models.Job)(nil)).Elem()},
+		},
+	},
+	"CampaignService.GetAllCampaigns": {
+		Summary: "List the campaigns",
+		Query: []param{
+			{Name: "appraisal_year", Type: "integer", Description: "Year of the appraisal cycle"},
+			{Name: "appraisal_type", Type: "string", Description: "Type of the appraisal cycle"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Campaign)(nil)).Elem()},
+		},
+	},
+	"CampaignService.GetCampaignByID": {
+		Summary: "Get a campaign",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Campaign)(nil)).Elem()},
+		},
+	},
+	"CommentService.CreateComment": {
+		Summary: "Comment on a kpi or a score",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.Comment)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Comment)(nil)).Elem()},
+		},
+	},
+	"CommentService.CreateMyComment": {
+		Summary: "Comment on the kpis or scores of the caller",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.Comment)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Comment)(nil)).Elem()},
+		},
+	},
+	"CommentService.DeleteComment": {
+		Summary: "Delete a comment",
+		Auth:    true,
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"CommentService.EditComment": {
+		Summary: "Edit a comment",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.CommentEdit)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Comment)(nil)).Elem()},
+		},
+	},
+	"CommentService.EditMyComment": {
+		Summary: "Edit a comment of the caller",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.CommentEdit)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Comment)(nil)).Elem()},
+		},
+	},
+	"CommentService.GetAllComments": {
+		Summary: "List the comments",
+		Auth:    true,
+		Query: []param{
+			{Name: "appraisal_id", Type: "integer", Description: "Appraisal of the comments"},
+			{Name: "employee_id", Type: "integer", Description: "Employee the comments are about"},
+			{Name: "appraisal_kpi_id", Type: "integer", Description: "Appraisal kpi the comments are on"},
+			{Name: "score_id", Type: "integer", Description: "Score the comments are on"},
+			{Name: "visibility", Type: "string", Description: "Either shared or hr_private"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Comment)(nil)).Elem()},
+		},
+	},
+	"CommentService.GetCommentByID": {
+		Summary: "Get a comment",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Comment)(nil)).Elem()},
+		},
+	},
+	"CommentService.GetMyComments": {
+		Summary: "List the comments shared with the caller on their appraisal",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Comment)(nil)).Elem()},
+		},
+	},
+	"CommentService.GetMyMentions": {
+		Summary: "List the shared comments mentioning the caller",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Comment)(nil)).Elem()},
+		},
+	},
+	"DelegationService.CreateDelegation": {
+		Summary: "Delegate the evaluator rights of the caller",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.Delegation)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Delegation)(nil)).Elem()},
+		},
+	},
+	"DelegationService.GetAllDelegations": {
+		Summary: "List the delegations",
+		Query: []param{
+			{Name: "delegator_id", Type: "integer", Description: "Supervisor who delegated"},
+			{Name: "delegate_id", Type: "integer", Description: "Employee delegated to"},
+			{Name: "active", Type: "boolean", Description: "Only the delegations in effect"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Delegation)(nil)).Elem()},
+		},
+	},
+	"DelegationService.GetDelegationByID": {
+		Summary: "Get a delegation",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Delegation)(nil)).Elem()},
+		},
+	},
+	"DelegationService.RevokeDelegation": {
+		Summary: "Revoke a delegation of the caller before its end date",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Delegation)(nil)).Elem()},
+		},
+	},
+	"DirectoryService.GetChanges": {
+		Summary: "List the detected directory changes",
+		Query: []param{
+			{Name: "entity_type", Type: "string", Description: "Either employee, designation or project"},
+			{Name: "entity_id", Type: "integer", Description: "TOSS id of the entity"},
+			{Name: "change_type", Type: "string", Description: "Type of the changes"},
+			{Name: "sync_run_id", Type: "integer", Description: "Sync run that detected the changes"},
+			{Name: "from", Type: "date", Description: "First day of the changes"},
+			{Name: "to", Type: "date", Description: "Last day of the changes"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.DirectoryChange)(nil)).Elem()},
+		},
+	},
+	"DirectoryService.GetCycleChanges": {
+		Summary: "Report the joiners, leavers and team moves during an appraisal cycle",
+		Query: []param{
+			{Name: "appraisal_year", Type: "integer", Description: "Year of the appraisal cycle"},
+			{Name: "appraisal_type", Type: "string", Description: "Type of the appraisal cycle"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.CycleRosterChanges)(nil)).Elem()},
+		},
+	},
+	"DirectoryService.GetEmployees": {
+		Summary: "List the employees of the directory",
+		Query: []param{
+			{Name: "is_active", Type: "boolean", Description: "Whether the employees are active"},
+			{Name: "designation_id", Type: "integer", Description: "Designation of the employees"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.DirectoryEmployee)(nil)).Elem()},
+		},
+	},
+	"DirectoryService.GetProjects": {
+		Summary: "List the projects of the directory",
+		Query: []param{
+			{Name: "is_active", Type: "boolean", Description: "Whether the projects are active"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.DirectoryProject)(nil)).Elem()},
+		},
+	},
+	"DirectoryService.GetSyncRuns": {
+		Summary: "List the last runs of the directory sync",
+		Query: []param{
+			{Name: "status", Type: "string", Description: "Either running, succeeded or failed"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.DirectorySyncRun)(nil)).Elem()},
+		},
+	},
+	"DirectoryService.SyncDirectory": {
+		Summary: "Queue a sync of the TOSS directory right away",
+		Responses: []response{
+			{Status: 202, Type: reflect.TypeOf((*models.Job)(nil)).Elem()},
+		},
+	},
+	"EmployeeService.CreateEmployee": {
+		Summary: "Create an employee",
+		Body:    reflect.TypeOf((*models.Employee)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Employee)(nil)).Elem()},
+		},
+	},
+	"EmployeeService.DeleteEmployee": {
+		Summary: "Delete an employee",
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"EmployeeService.GetEmployee": {
+		Summary: "Get an employee",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Employee)(nil)).Elem()},
+		},
+	},
+	"EmployeeService.GetEmployees": {
+		Summary: "List the employees",
+		Query: []param{
+			{Name: "name", Type: "string", Description: "Name of the employees"},
+			{Name: "role", Type: "string", Description: "Role of the employees"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Employee)(nil)).Elem()},
+		},
+	},
+	"EmployeeService.UpdateEmployee": {
+		Summary: "Update an employee",
+		Body:    reflect.TypeOf((*models.Employee)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Employee)(nil)).Elem()},
+		},
+	},
+	"IncrementService.ApproveRecommendations": {
+		Summary: "Approve recommendations in bulk",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.RecommendationApproval)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf(map[string]interface{}{})},
+		},
+	},
+	"IncrementService.CreateIncrementPolicy": {
+		Summary: "Create an increment policy",
+		Body:    reflect.TypeOf((*models.IncrementPolicy)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.IncrementPolicy)(nil)).Elem()},
+		},
+	},
+	"IncrementService.DeleteIncrementPolicy": {
+		Summary: "Delete an increment policy",
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"IncrementService.GenerateRecommendations": {
+		Summary: "Queue the generation of the recommendation sheet of an annual appraisal cycle",
+		Query: []param{
+			{Name: "appraisal_year", Type: "integer", Description: "Year of the appraisal cycle"},
+			{Name: "appraisal_type", Type: "string", Description: "Type of the appraisal cycle"},
+		},
+		Responses: []response{
+			{Status: 202, Type: reflect.TypeOf((*models.Job)(nil)).Elem()},
+		},
+	},
+	"IncrementService.GetAllIncrementPolicies": {
+		Summary: "List the increment policies",
+		Query: []param{
+			{Name: "designation_id", Type: "integer", Description: "Designation the policies target"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.IncrementPolicy)(nil)).Elem()},
+		},
+	},
+	"IncrementService.GetIncrementPolicyByID": {
+		Summary: "Get an increment policy",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.IncrementPolicy)(nil)).Elem()},
+		},
+	},
+	"IncrementService.GetRecommendationByID": {
+		Summary: "Get a recommendation",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Recommendation)(nil)).Elem()},
+		},
+	},
+	"IncrementService.GetRecommendations": {
+		Summary: "List the recommendations of an appraisal cycle",
+		Query: []param{
+			{Name: "appraisal_year", Type: "integer", Description: "Year of the appraisal cycle"},
+			{Name: "appraisal_type", Type: "string", Description: "Type of the appraisal cycle"},
+			{Name: "status", Type: "string", Description: "Status of the recommendations"},
+			{Name: "supervisor_id", Type: "integer", Description: "Supervisor of the employees"},
+			{Name: "designation_id", Type: "integer", Description: "Designation of the employees"},
+			{Name: "format", Type: "string", Description: "csv for a CSV sheet"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Recommendation)(nil)).Elem()},
+			{Status: 200, MediaType: "text/csv"},
+		},
+	},
+	"IncrementService.OverrideRecommendation": {
+		Summary: "Override the recommended increment and promotion of an employee",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.RecommendationOverride)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Recommendation)(nil)).Elem()},
+		},
+	},
+	"IncrementService.UpdateIncrementPolicy": {
+		Summary: "Update an increment policy",
+		Body:    reflect.TypeOf((*models.IncrementPolicy)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.IncrementPolicy)(nil)).Elem()},
+		},
+	},
+	"JobService.GetAllJobs": {
+		Summary: "List the background jobs",
+		Query: []param{
+			{Name: "status", Type: "string", Description: "Status of the jobs"},
+			{Name: "job_type", Type: "string", Description: "Type of the jobs"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Job)(nil)).Elem()},
+		},
+	},
+	"JobService.GetJobByID": {
+		Summary: "Get the progress and result of a background job",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Job)(nil)).Elem()},
+		},
+	},
+	"KPIService.CreateKPI": {
+		Summary: "Create a kpi",
+		Body:    reflect.TypeOf((*models.Kpi)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Kpi)(nil)).Elem()},
+		},
+	},
+	"KPIService.DeleteKPI": {
+		Summary: "Delete a kpi",
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"KPIService.GetAllKPIs": {
+		Summary: "List the kpis",
+		Query: []param{
+			{Name: "kpi_name", Type: "string", Description: "Name of the kpis"},
+			{Name: "assign_type", Type: "integer", Description: "Assign type of the kpis"},
+			{Name: "kpi_type", Type: "string", Description: "Type of the kpis"},
+			{Name: "team_id", Type: "integer", Description: "Team the kpis are assigned to"},
+			{Name: "role_id", Type: "integer", Description: "Role the kpis are assigned to"},
+			{Name: "employee_id", Type: "integer", Description: "Employee the kpis are assigned to"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Kpi)(nil)).Elem()},
+		},
+	},
+	"KPIService.GetAllKpiTypes": {
+		Summary: "List the kpi types",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.KpiType)(nil)).Elem()},
+		},
+	},
+	"KPIService.GetKPIByID": {
+		Summary: "Get a kpi",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Kpi)(nil)).Elem()},
+		},
+	},
+	"KPIService.UpdateKPI": {
+		Summary: "Update a kpi",
+		Body:    reflect.TypeOf((*models.Kpi)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Kpi)(nil)).Elem()},
+		},
+	},
+	"KPIService.UpdateKpiType": {
+		Summary: "Attach a rating scale to a kpi type",
+		Body:    reflect.TypeOf((*models.KpiTypeRatingScale)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.KpiType)(nil)).Elem()},
+		},
+	},
+	"MeService.AcknowledgeMyResults": {
+		Summary: "Acknowledge or disagree with the published results of the caller",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.Acknowledgement)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Acknowledgement)(nil)).Elem()},
+		},
+	},
+	"MeService.ExportMyReport": {
+		Summary: "Export the published report of the caller",
+		Auth:    true,
+		Query: []param{
+			{Name: "format", Type: "string", Description: "Either csv, the default, or pdf"},
+		},
+		Responses: []response{
+			{Status: 200, MediaType: "text/csv"},
+			{Status: 200, MediaType: "application/pdf"},
+		},
+	},
+	"MeService.GetMyAcknowledgement": {
+		Summary: "Get the acknowledgement of the results of the caller",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Acknowledgement)(nil)).Elem()},
+		},
+	},
+	"MeService.GetMyAppraisalKpis": {
+		Summary: "List the kpis of the caller in an appraisal",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.AppraisalKpi)(nil)).Elem()},
+		},
+	},
+	"MeService.GetMyAppraisals": {
+		Summary: "List the appraisals of the caller",
+		Auth:    true,
+		Query: []param{
+			{Name: "status", Type: "string", Description: "Either current or past"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.EmployeeAppraisal)(nil)).Elem()},
+		},
+	},
+	"MeService.GetMyInbox": {
+		Summary: "List the employees the caller still owes scores for",
+		Auth:    true,
+		Query: []param{
+			{Name: "sort", Type: "string", Description: "Either urgency, the default, pending or employee"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.InboxTask)(nil)).Elem()},
+		},
+	},
+	"MeService.GetMyPerformanceHistory": {
+		Summary: "Get the performance history of the caller",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.PerformanceHistory)(nil)).Elem()},
+		},
+	},
+	"MeService.GetMyResults": {
+		Summary: "List the published results of the caller",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.EmployeeResult)(nil)).Elem()},
+		},
+	},
+	"MeService.GetMyTasks": {
+		Summary: "List the self reviews the caller still owes",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.SelfReviewTask)(nil)).Elem()},
+		},
+	},
+	"PipService.AddPipObjective": {
+		Summary: "Add an objective to a plan",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.PipObjective)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.PipObjective)(nil)).Elem()},
+		},
+	},
+	"PipService.CreatePip": {
+		Summary: "Create a performance improvement plan",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.Pip)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Pip)(nil)).Elem()},
+		},
+	},
+	"PipService.DecidePipOutcome": {
+		Summary: "Close or extend a plan",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.PipOutcome)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Pip)(nil)).Elem()},
+		},
+	},
+	"PipService.GetAllPips": {
+		Summary: "List the performance improvement plans",
+		Query: []param{
+			{Name: "status", Type: "string", Description: "Status of the plans"},
+			{Name: "owner_id", Type: "integer", Description: "Owner of the plans"},
+			{Name: "appraisal_id", Type: "integer", Description: "Appraisal the plans follow"},
+			{Name: "employee_id", Type: "integer", Description: "Employee of the plans"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Pip)(nil)).Elem()},
+		},
+	},
+	"PipService.GetMyPips": {
+		Summary: "List the performance improvement plans of the caller",
+		Auth:    true,
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Pip)(nil)).Elem()},
+		},
+	},
+	"PipService.GetPipByID": {
+		Summary: "Get a performance improvement plan",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Pip)(nil)).Elem()},
+		},
+	},
+	"PipService.GetPipCandidates": {
+		Summary: "Suggest employees with low final scores for an improvement plan",
+		Query: []param{
+			{Name: "threshold", Type: "number", Description: "Final score under which employees are suggested"},
+			{Name: "appraisal_id", Type: "integer", Description: "Appraisal of the scores"},
+			{Name: "team_id", Type: "integer", Description: "Team of the employees"},
+			{Name: "supervisor_id", Type: "integer", Description: "Supervisor of the employees"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.PipCandidate)(nil)).Elem()},
+		},
+	},
+	"PipService.RecordCheckIn": {
+		Summary: "Record the progress and notes of a check-in meeting",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.PipCheckIn)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.PipCheckIn)(nil)).Elem()},
+		},
+	},
+	"PipService.ScheduleCheckIn": {
+		Summary: "Schedule a check-in meeting of a plan",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.PipCheckIn)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.PipCheckIn)(nil)).Elem()},
+		},
+	},
+	"PipService.UpdatePipObjective": {
+		Summary: "Update an objective of a plan",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.PipObjective)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.PipObjective)(nil)).Elem()},
+		},
+	},
+	"RatingScaleService.CreateRatingScale": {
+		Summary: "Create a rating scale",
+		Body:    reflect.TypeOf((*models.RatingScale)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.RatingScale)(nil)).Elem()},
+		},
+	},
+	"RatingScaleService.DeleteRatingScale": {
+		Summary: "Delete a rating scale",
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"RatingScaleService.GetAllRatingScales": {
+		Summary: "List the rating scales",
+		Query: []param{
+			{Name: "scale_name", Type: "string", Description: "Name of the scales"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.RatingScale)(nil)).Elem()},
+		},
+	},
+	"RatingScaleService.GetRatingScaleByID": {
+		Summary: "Get a rating scale",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.RatingScale)(nil)).Elem()},
+		},
+	},
+	"RatingScaleService.UpdateRatingScale": {
+		Summary: "Update a rating scale",
+		Body:    reflect.TypeOf((*models.RatingScale)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.RatingScale)(nil)).Elem()},
+		},
+	},
+	"RoleService.CreateRole": {
+		Summary: "Create a role",
+		Body:    reflect.TypeOf((*models.Role)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Role)(nil)).Elem()},
+		},
+	},
+	"RoleService.DeleteRole": {
+		Summary: "Delete a role",
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"RoleService.GetAllRoles": {
+		Summary: "List the roles",
+		Query: []param{
+			{Name: "role_name", Type: "string", Description: "Name of the roles"},
+			{Name: "is_active", Type: "boolean", Description: "Whether the roles are active"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Role)(nil)).Elem()},
+		},
+	},
+	"RoleService.GetRoleByID": {
+		Summary: "Get a role",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Role)(nil)).Elem()},
+		},
+	},
+	"RoleService.UpdateRole": {
+		Summary: "Update a role",
+		Body:    reflect.TypeOf((*models.Role)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Role)(nil)).Elem()},
+		},
+	},
+	"RosterService.ApproveReconciliation": {
+		Summary: "Queue the application of the proposed changes of a roster reconciliation",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.RosterReview)(nil)).Elem(),
+		Responses: []response{
+			{Status: 202, Type: reflect.TypeOf((*models.Job)(nil)).Elem()},
+		},
+	},
+	"RosterService.GetReconciliationByID": {
+		Summary: "Get a roster reconciliation",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.RosterReconciliation)(nil)).Elem()},
+		},
+	},
+	"RosterService.GetReconciliations": {
+		Summary: "List the roster reconciliations",
+		Query: []param{
+			{Name: "appraisal_id", Type: "integer", Description: "Appraisal of the reconciliations"},
+			{Name: "status", Type: "string", Description: "Status of the reconciliations"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.RosterReconciliation)(nil)).Elem()},
+		},
+	},
+	"RosterService.ProposeReconciliations": {
+		Summary: "Propose the reconciliation of the open team appraisals with the TOSS rosters",
+		Query: []param{
+			{Name: "appraisal_id", Type: "integer", Description: "Only reconcile this appraisal"},
+		},
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*[]models.RosterReconciliation)(nil)).Elem()},
+		},
+	},
+	"RosterService.RejectReconciliation": {
+		Summary: "Reject a roster reconciliation",
+		Auth:    true,
+		Body:    reflect.TypeOf((*models.RosterReview)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.RosterReconciliation)(nil)).Elem()},
+		},
+	},
+	"SupervisorService.ConvertSupervisorToEmployee": {
+		Summary: "Create a supervisor",
+		Body:    reflect.TypeOf((*models.Supervisor)(nil)).Elem(),
+		Responses: []response{
+			{Status: 201, Type: reflect.TypeOf((*models.Employee)(nil)).Elem()},
+		},
+	},
+	"SupervisorService.DeleteSupervisor": {
+		Summary: "Delete a supervisor",
+		Responses: []response{
+			{Status: 204},
+		},
+	},
+	"SupervisorService.GetSupervisorById": {
+		Summary: "Get a supervisor",
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Employee)(nil)).Elem()},
+		},
+	},
+	"SupervisorService.GetSupervisors": {
+		Summary: "List the supervisors",
+		Query: []param{
+			{Name: "name", Type: "string", Description: "Name of the supervisors"},
+		},
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*[]models.Employee)(nil)).Elem()},
+		},
+	},
+	"SupervisorService.UpdateSupervisor": {
+		Summary: "Update a supervisor",
+		Body:    reflect.TypeOf((*models.Supervisor)(nil)).Elem(),
+		Responses: []response{
+			{Status: 200, Type: reflect.TypeOf((*models.Employee)(nil)).Elem()},
+		},
+	},
+}
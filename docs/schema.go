@@ -0,0 +1,156 @@
+package docs
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schema is an OpenAPI schema object
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemas holds the schemas of the named structs referenced by the operations
+type schemas map[string]*schema
+
+// schemaOf returns the schema of the values of t as encoding/json writes them.
+// Named structs are added to the schemas and referenced.
+func (s schemas) schemaOf(t reflect.Type) *schema {
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t.Kind() != reflect.Ptr && t.Implements(marshalerType):
+		// Whatever the type marshals itself to
+		return &schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return s.schemaOf(t.Elem())
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: s.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: s.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+
+		name := schemaName(t)
+		if _, ok := s[name]; !ok {
+			// Registered before the fields, for the structs referencing themselves
+			s[name] = &schema{}
+			*s[name] = *s.structSchema(t)
+		}
+		return &schema{Ref: "#/components/schemas/" + name}
+	}
+
+	return &schema{}
+}
+
+// structSchema lists the fields of the struct by their json names. Embedded
+// structs without a json name are flattened into it, and the fields required by
+// their binding or validate tag are required.
+func (s schemas) structSchema(t reflect.Type) *schema {
+	object := &schema{Type: "object", Properties: map[string]*schema{}}
+
+	for k := 0; k < t.NumField(); k++ {
+		field := t.Field(k)
+		name, omitEmpty := jsonName(field)
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			embedded := s.structSchema(fieldType)
+			for property, propertySchema := range embedded.Properties {
+				object.Properties[property] = propertySchema
+			}
+			object.Required = append(object.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.schemaOf(field.Type)
+		if field.Type.Kind() == reflect.Ptr && property.Ref == "" {
+			property.Nullable = true
+		}
+
+		// Rules are checked on binding or by the Validate method of the model
+		for _, tag := range []string{"binding", "validate"} {
+			rules := strings.Split(field.Tag.Get(tag), ",")
+			for _, rule := range rules {
+				if values := strings.TrimPrefix(rule, "oneof="); values != rule && property.Ref == "" {
+					property.Enum = strings.Fields(values)
+				}
+			}
+			if rules[0] == "required" && !omitEmpty {
+				object.Required = append(object.Required, name)
+			}
+		}
+
+		object.Properties[name] = property
+	}
+
+	return object
+}
+
+// jsonName returns the name of the field in its json tag, empty if it has none
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := strings.Split(field.Tag.Get("json"), ",")
+	for _, option := range tag[1:] {
+		if option == "omitempty" {
+			return tag[0], true
+		}
+	}
+
+	return tag[0], false
+}
+
+// schemaName names the schema of a model after it, and the one of a struct of
+// another package after its package too
+func schemaName(t reflect.Type) string {
+	pkg := path.Base(t.PkgPath())
+	if pkg == "models" {
+		return t.Name()
+	}
+
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + t.Name()
+}
@@ -0,0 +1,19 @@
+package docs
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The assets of the Swagger UI are loaded from unpkg, and the page reads the
+// specification served next to it
+//
+//go:embed swagger.html
+var swaggerUI []byte
+
+// SwaggerUI writes the Swagger UI page of the specification
+func SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerUI)
+}
@@ -0,0 +1,590 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type AppraisalService struct {
+	Db *gorm.DB
+}
+
+func NewAppraisalService(db *gorm.DB) *AppraisalService {
+	return &AppraisalService{Db: db}
+}
+
+// AppraisalFilter narrows down the appraisals listed. Empty fields match every
+// appraisal.
+type AppraisalFilter struct {
+	AppraisalName string
+	SupervisorID  string
+}
+
+// CheckNew validates a new appraisal before its employees are enrolled, which is
+// the slow part of creating it, and sets the name of whom it is for
+func (s *AppraisalService) CheckNew(ctx context.Context, appraisal *models.Appraisal) error {
+	db := s.Db.WithContext(ctx)
+
+	if appraisal.DueDate != nil && appraisal.DueDate.Before(time.Now()) {
+		return apperrors.Invalid("due_date should be in the future")
+	}
+	appraisal.CampaignID = nil
+
+	if err := checkAppraisalKpis(ctx, appraisal.AppraisalKpis); err != nil {
+		return err
+	}
+
+	_, name, err := CheckAssignType(db, uint16(appraisal.AppraisalFor))
+	if err != nil {
+		return apperrors.Invalid("invalid assign type")
+	}
+	appraisal.AppraisalForName = name
+
+	if err := CheckAppraisalType(db, appraisal.AppraisalTypeStr); err != nil {
+		return apperrors.Invalid("invalid appraisal type")
+	}
+
+	var appraisalFlow models.AppraisalFlow
+	if err := db.Model(&models.AppraisalFlow{}).First(&appraisalFlow, appraisal.AppraisalFlowID).Error; err != nil {
+		return apperrors.Invalid("invalid appraisal flow ID")
+	}
+
+	return nil
+}
+
+// Create enrolls the employees of an appraisal checked by CheckNew and creates
+// it
+func (s *AppraisalService) Create(ctx context.Context, appraisal *models.Appraisal) (*models.Appraisal, error) {
+	if err := s.Build(ctx, appraisal); err != nil {
+		return nil, err
+	}
+
+	supervisorName, err := utils.GetSupervisorName(ctx, appraisal.SupervisorID)
+	if err != nil {
+		return nil, apperrors.Upstream("failed to get supervisor name", err)
+	}
+	appraisal.SupervisorName = supervisorName
+
+	dbAppraisal, err := controller.CreateAppraisal(s.Db.WithContext(ctx), appraisal)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return dbAppraisal, nil
+}
+
+// Build enrolls the employees of the appraisal and assigns them their kpis,
+// depending on whom the appraisal is for
+func (s *AppraisalService) Build(ctx context.Context, appraisal *models.Appraisal) error {
+	switch appraisal.AppraisalForName {
+	case constants.ASSIGN_TYPE_TEAM:
+		return s.buildTeamAppraisal(ctx, appraisal)
+	case constants.ASSIGN_TYPE_INDIVIDUAL:
+		return s.buildIndividualAppraisal(ctx, appraisal)
+	case constants.ASSIGN_TYPE_ROLE:
+		return s.buildRoleAppraisal(ctx, appraisal)
+	}
+
+	return nil
+}
+
+// LookupEmployees looks up the details of the employees enrolled in an
+// appraisal in TOSS
+func LookupEmployees(ctx context.Context, empIDs []uint16) ([]utils.EmployeeDetails, error) {
+	details, err := utils.GetEmployeesDetails(ctx, empIDs)
+	if err != nil {
+		if errors.Is(err, utils.ErrEmployeeNotFound) {
+			log.Error(err.Error())
+			return nil, apperrors.Invalid("Invalid Employee ID")
+		}
+		return nil, apperrors.Upstream("failed to fetch employee details", err)
+	}
+
+	return details, nil
+}
+
+// NewEmployeeData enrolls the employee with their details from TOSS
+func NewEmployeeData(appraisal *models.Appraisal, detail utils.EmployeeDetails) models.EmployeeData {
+	return models.EmployeeData{
+		AppraisalID:     appraisal.ID,
+		TossEmpID:       detail.EmployeeID,
+		EmployeeName:    detail.EmployeeName,
+		TeamID:          detail.TeamID,
+		TeamName:        detail.TeamName,
+		EmployeeImage:   os.Getenv("TOSS_BASE_URL") + "/" + detail.EmployeeImage,
+		Designation:     detail.Designation,
+		DesignationName: detail.DesignationName,
+		AppraisalStatus: constants.APPRAISAL_STATUS_PENDING,
+	}
+}
+
+// buildIndividualAppraisal enrolls the employee of the appraisal from TOSS and
+// assigns them their own kpis
+func (s *AppraisalService) buildIndividualAppraisal(ctx context.Context, appraisal *models.Appraisal) error {
+	errCode, name, err := utils.VerifyIndividualAndSupervisorID(ctx, appraisal.SelectedFieldID, appraisal.SupervisorID)
+	if err != nil {
+		return apperrors.FromStatus(errCode, err)
+	}
+
+	details, err := LookupEmployees(ctx, []uint16{appraisal.SelectedFieldID})
+	if err != nil {
+		return err
+	}
+	employeeData := NewEmployeeData(appraisal, details[0])
+	employeeData.TossEmpID = appraisal.AppraisalFor
+
+	// Append EmployeeData to Appraisal
+	appraisal.EmployeesList = []models.EmployeeData{employeeData}
+
+	appraisal.SelectedFieldNames = name
+	kpis := make([]models.Kpi, 0)
+	if err := s.Db.WithContext(ctx).Where("assign_type_id = ? AND selected_assign_id = ?", appraisal.AppraisalFor, appraisal.SelectedFieldID).Find(&kpis).Error; err != nil {
+		return apperrors.Wrap(apperrors.KindInternal, "An error occurred while retrieving KPIs", err)
+	}
+
+	if len(kpis) == 0 {
+		return apperrors.NotFound("Kpi does not exist for the Individual")
+	}
+
+	for _, kpi := range kpis {
+		appraisalKpi := models.AppraisalKpi{
+			AppraisalID: appraisal.ID,
+			EmployeeID:  appraisal.SelectedFieldID,
+			KpiID:       kpi.ID,
+			Status:      "pending",
+		}
+		appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
+	}
+
+	return nil
+}
+
+// buildRoleAppraisal enrolls the active employees of the designation of the
+// appraisal from TOSS and assigns them the kpis of the designation and their own
+func (s *AppraisalService) buildRoleAppraisal(ctx context.Context, appraisal *models.Appraisal) error {
+	db := s.Db.WithContext(ctx)
+
+	errCode, name, err := utils.CheckRoleExists(ctx, appraisal.SelectedFieldID)
+	if err != nil {
+		return apperrors.FromStatus(errCode, err)
+	}
+	appraisal.SelectedFieldNames = name
+
+	// Get employee IDs for the provided role ID
+	employeeIDs, err := utils.GetEmployeeIDsByDesignation(ctx, uint16(appraisal.SelectedFieldID))
+	if err != nil {
+		return apperrors.Upstream("failed to fetch employee IDs", err)
+	}
+
+	if len(employeeIDs) == 0 {
+		return apperrors.NotFound("No employees found for the provided role")
+	}
+
+	details, err := LookupEmployees(ctx, employeeIDs)
+	if err != nil {
+		return err
+	}
+
+	employeeDataList := make([]models.EmployeeData, 0, len(details))
+	for _, detail := range details {
+		employeeDataList = append(employeeDataList, NewEmployeeData(appraisal, detail))
+	}
+
+	// Append EmployeeData to Appraisal
+	appraisal.EmployeesList = employeeDataList
+
+	kpis := make([]models.Kpi, 0)
+	if err := db.Where("assign_type_id = ? AND selected_assign_id = ?", appraisal.AppraisalFor, appraisal.SelectedFieldID).Find(&kpis).Error; err != nil {
+		return apperrors.Wrap(apperrors.KindInternal, "An error occurred while retrieving KPIs", err)
+	}
+
+	if len(kpis) == 0 {
+		return apperrors.Invalid("KPI does not exist for the Role")
+	}
+
+	for _, kpi := range kpis {
+		for _, empID := range employeeIDs {
+			appraisalKpi := models.AppraisalKpi{
+				AppraisalID: appraisal.ID,
+				EmployeeID:  empID,
+				KpiID:       kpi.ID,
+				Status:      "pending",
+			}
+			appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
+		}
+	}
+
+	// Employees of the designation keep their individual kpis, like in team appraisals
+	individualKpis := make([]models.Kpi, 0)
+	err = db.Model(&models.Kpi{}).
+		Joins("JOIN assign_types ON assign_types.id = kpis.assign_type_id").
+		Where("kpis.selected_assign_id IN ? AND assign_types.assign_type = ?", employeeIDs, constants.ASSIGN_TYPE_INDIVIDUAL).
+		Order("kpis.id ASC").
+		Find(&individualKpis).Error
+	if err != nil {
+		return apperrors.Wrap(apperrors.KindInternal, "An error occurred while retrieving KPIs", err)
+	}
+
+	for _, kpi := range individualKpis {
+		appraisalKpi := models.AppraisalKpi{
+			AppraisalID: appraisal.ID,
+			EmployeeID:  kpi.SelectedAssignID,
+			KpiID:       kpi.ID,
+			Status:      "pending",
+		}
+		appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
+	}
+
+	return nil
+}
+
+// buildTeamAppraisal enrolls the members of the team of the appraisal from TOSS
+// and assigns them the kpis of the team, of their designation and their own
+func (s *AppraisalService) buildTeamAppraisal(ctx context.Context, appraisal *models.Appraisal) error {
+	errCode, name, err := utils.VerifyTeamAndSupervisorID(ctx, appraisal.SelectedFieldID, appraisal.SupervisorID)
+	if err != nil {
+		return apperrors.FromStatus(errCode, err)
+	}
+	appraisal.SelectedFieldNames = name
+	kpis := make([]models.Kpi, 0)
+
+	empIds, err := utils.GetEmployeesId(ctx, uint16(appraisal.SelectedFieldID))
+	if err != nil {
+		return apperrors.Upstream("failed to fetch employee IDs", err)
+	}
+
+	details, err := LookupEmployees(ctx, empIds)
+	if err != nil {
+		return err
+	}
+
+	employeeDataList := make([]models.EmployeeData, 0, len(details))
+	roleIds := make([]uint16, 0, len(details))
+	employeeRoles := make(map[uint16]uint16, len(details))
+	for _, detail := range details {
+		employeeDataList = append(employeeDataList, NewEmployeeData(appraisal, detail))
+		roleIds = append(roleIds, detail.Designation)
+		employeeRoles[detail.EmployeeID] = detail.Designation
+	}
+
+	// Append EmployeeData to Appraisal
+	appraisal.EmployeesList = employeeDataList
+
+	query := s.Db.WithContext(ctx).Model(&models.Kpi{})
+	query = query.Joins("JOIN assign_types ON assign_types.id = kpis.assign_type_id").
+		Where(`(kpis.selected_assign_id = ? AND assign_types.assign_type = ?)
+		OR (kpis.selected_assign_id IN (?) AND assign_types.assign_type = ?)
+		OR (kpis.selected_assign_id IN (?) AND assign_types.assign_type = ?)`,
+			appraisal.SelectedFieldID, constants.ASSIGN_TYPE_TEAM, empIds, constants.ASSIGN_TYPE_INDIVIDUAL, roleIds, constants.ASSIGN_TYPE_ROLE)
+	if err := query.Model(&models.Kpi{}).Order("id ASC").Find(&kpis).Error; err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if len(kpis) == 0 {
+		return apperrors.NotFound("KPI does not exist for the team")
+	}
+
+	for _, kpi := range kpis {
+		if kpi.AssignTypeName == constants.ASSIGN_TYPE_INDIVIDUAL {
+			appraisalKpi := models.AppraisalKpi{
+				AppraisalID: appraisal.ID,
+				EmployeeID:  kpi.SelectedAssignID,
+				KpiID:       kpi.ID,
+				Status:      "pending",
+			}
+			appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
+		}
+
+		if kpi.AssignTypeName == constants.ASSIGN_TYPE_TEAM {
+
+			// Assign the KPI to individual employees of the team
+			for _, employeeID := range empIds {
+				appraisalKpi := models.AppraisalKpi{
+					AppraisalID: appraisal.ID,
+					EmployeeID:  employeeID,
+					KpiID:       kpi.ID,
+					Status:      "pending",
+				}
+				appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
+			}
+		}
+		if kpi.AssignTypeName == constants.ASSIGN_TYPE_ROLE {
+
+			for _, employeeID := range empIds {
+				if employeeRoles[employeeID] == kpi.SelectedAssignID {
+					appraisalKpi := models.AppraisalKpi{
+						AppraisalID: appraisal.ID,
+						EmployeeID:  employeeID,
+						KpiID:       kpi.ID,
+						Status:      "pending",
+					}
+					appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
+				}
+
+			}
+
+		}
+
+	}
+
+	return nil
+}
+
+func (s *AppraisalService) Get(ctx context.Context, id uint64) (*models.Appraisal, error) {
+	var appraisal models.Appraisal
+	if err := controller.GetAppraisalByID(s.Db.WithContext(ctx), &appraisal, id); err != nil {
+		return nil, lookup(err, "Record not found against appraisal id")
+	}
+
+	return &appraisal, nil
+}
+
+func (s *AppraisalService) List(ctx context.Context, filter AppraisalFilter) ([]models.Appraisal, error) {
+	var appraisals []models.Appraisal
+	db := s.Db.WithContext(ctx).Model(&models.Appraisal{})
+
+	if filter.AppraisalName != "" {
+		db = db.Where("appraisal_name LIKE ?", "%"+filter.AppraisalName+"%")
+	}
+
+	if filter.SupervisorID != "" {
+		db = db.Where("supervisor_id = ?", filter.SupervisorID)
+	}
+
+	if err := controller.GetAllAppraisals(db, &appraisals); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return appraisals, nil
+}
+
+// GetEmployees returns the employees of the appraisal, or the one with the TOSS
+// employee ID if given
+func (s *AppraisalService) GetEmployees(ctx context.Context, id uint64, tossEmpID string) ([]models.EmployeeData, error) {
+	var employeeData []models.EmployeeData
+	db := s.Db.WithContext(ctx).Model(&models.EmployeeData{})
+
+	if tossEmpID != "" {
+		db = db.Where("toss_emp_id = ?", tossEmpID)
+	}
+
+	if err := controller.GetEmployeeDataByAppraisalID(db, &employeeData, id); err != nil {
+		return nil, lookup(err, "Record not found against employee data")
+	}
+
+	return employeeData, nil
+}
+
+// GetKpis returns the kpis assigned in the appraisal, to the employee if given
+func (s *AppraisalService) GetKpis(ctx context.Context, id uint64, employeeID string) ([]models.AppraisalKpi, error) {
+	var appraisalKpis []models.AppraisalKpi
+	db := s.Db.WithContext(ctx).Model(&models.AppraisalKpi{})
+
+	if employeeID != "" {
+		db = db.Where("employee_id = ?", employeeID)
+	}
+
+	if err := controller.GetAppraisalKpisByEmpID(db, &appraisalKpis, id); err != nil {
+		return nil, lookup(err, "Record not found against appraisal kpi id")
+	}
+
+	return appraisalKpis, nil
+}
+
+// Update replaces the appraisal after checking its employees and whom it is
+// for against TOSS
+func (s *AppraisalService) Update(ctx context.Context, id uint16, appraisal *models.Appraisal) (*models.Appraisal, error) {
+	db := s.Db.WithContext(ctx)
+
+	if err := checkAppraisalKpis(ctx, appraisal.AppraisalKpis); err != nil {
+		return nil, err
+	}
+
+	// Fetch all employee IDs from the AppraisalKpis table
+	existingEmployeeIDs := make([]int, 0)
+	if err := db.Model(&models.AppraisalKpi{}).Pluck("employee_id", &existingEmployeeIDs).Error; err != nil {
+		return nil, apperrors.Wrap(apperrors.KindInternal, "Failed to retrieve existing employee IDs", err)
+	}
+
+	// Check if the provided employee IDs exist in the AppraisalKpis table
+	for _, ed := range appraisal.EmployeesList {
+		errCode, _, err := utils.CheckRoleExists(ctx, ed.Designation)
+		if err != nil {
+			return nil, apperrors.FromStatus(errCode, err)
+		}
+
+		employeeIDExists := false
+		for _, existingID := range existingEmployeeIDs {
+			if existingID == int(ed.TossEmpID) {
+				employeeIDExists = true
+				break
+			}
+		}
+
+		if !employeeIDExists {
+			return nil, apperrors.NotFound("No KPI found against this Employee")
+		}
+
+		// Check employee ID in the Toss API
+		errCode, err = utils.CheckIndividualAgainstToss(ctx, ed.TossEmpID)
+		if err != nil {
+			return nil, apperrors.FromStatus(errCode, err)
+		}
+	}
+
+	_, name, err := CheckAssignType(db, uint16(appraisal.AppraisalFor))
+	if err != nil {
+		return nil, apperrors.Invalid("invalid assign type")
+	}
+	appraisal.AppraisalForName = name
+
+	var errCode int
+	switch appraisal.AppraisalForName {
+	case constants.ASSIGN_TYPE_TEAM:
+		errCode, name, err = utils.VerifyTeamAndSupervisorID(ctx, appraisal.SelectedFieldID, appraisal.SupervisorID)
+	case constants.ASSIGN_TYPE_INDIVIDUAL:
+		errCode, name, err = utils.VerifyIndividualAndSupervisorID(ctx, appraisal.SelectedFieldID, appraisal.SupervisorID)
+	case constants.ASSIGN_TYPE_ROLE:
+		errCode, name, err = utils.CheckRoleExists(ctx, appraisal.SelectedFieldID)
+	}
+	if err != nil {
+		return nil, apperrors.FromStatus(errCode, err)
+	}
+	appraisal.SelectedFieldNames = name
+
+	if err := CheckAppraisalType(db, appraisal.AppraisalTypeStr); err != nil {
+		return nil, apperrors.Invalid("invalid appraisal type")
+	}
+	appraisal.ID = id
+
+	// checking appraisal flow id exists in db
+	var appraisalFlow models.AppraisalFlow
+	if err := db.Model(&models.AppraisalFlow{}).First(&appraisalFlow, appraisal.AppraisalFlowID).Error; err != nil {
+		return nil, apperrors.Invalid("invalid appraisal flow id")
+	}
+
+	supervisorName, err := utils.GetSupervisorName(ctx, appraisal.SupervisorID)
+	if err != nil {
+		return nil, apperrors.Upstream("failed to get supervisor name", err)
+	}
+	appraisal.SupervisorName = supervisorName
+
+	dbAppraisal, err := controller.UpdateAppraisal(db, appraisal)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return dbAppraisal, nil
+}
+
+func (s *AppraisalService) Delete(ctx context.Context, id uint64) error {
+	appraisal, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := controller.DeleteAppraisal(s.Db.WithContext(ctx), appraisal, id); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
+
+// PublishResults makes the final scores of an appraisal visible to the
+// employees, only to the listed employees if any, and returns how many were
+// published
+func (s *AppraisalService) PublishResults(ctx context.Context, id uint64, employeeIDs []uint16) (int64, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return 0, err
+	}
+
+	count, err := controller.PublishAppraisalResults(s.Db.WithContext(ctx), id, employeeIDs)
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	if count == 0 {
+		return 0, apperrors.Invalid("no employees of the appraisal to publish")
+	}
+
+	return count, nil
+}
+
+// GetHistory returns the actions taken on the appraisal of an employee,
+// including the ones taken by delegates on behalf of the evaluators
+func (s *AppraisalService) GetHistory(ctx context.Context, appraisalID, employeeID uint64) ([]models.AppraisalHistory, error) {
+	history := make([]models.AppraisalHistory, 0)
+	if err := controller.GetAppraisalHistory(s.Db.WithContext(ctx), &history, appraisalID, employeeID); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return history, nil
+}
+
+func (s *AppraisalService) GetAcknowledgement(ctx context.Context, appraisalID, employeeID uint64) (*models.Acknowledgement, error) {
+	var acknowledgement models.Acknowledgement
+	if err := controller.GetAcknowledgement(s.Db.WithContext(ctx), &acknowledgement, appraisalID, employeeID); err != nil {
+		return nil, lookup(err, "No acknowledgement found against the employee")
+	}
+
+	return &acknowledgement, nil
+}
+
+// checkAppraisalKpis makes sure the kpis assigned by hand are complete and
+// assigned to employees of TOSS
+func checkAppraisalKpis(ctx context.Context, appraisalKpis []models.AppraisalKpi) error {
+	for _, ak := range appraisalKpis {
+		if ak.EmployeeID == 0 {
+			return apperrors.Invalid("employee_id field is required")
+		}
+
+		if ak.KpiID == 0 {
+			return apperrors.Invalid("kpi_id field is required ")
+		}
+
+		if ak.Status == "" {
+			return apperrors.Invalid("status field is required ")
+		}
+
+		errCode, err := utils.CheckIndividualAgainstToss(ctx, ak.EmployeeID)
+		if err != nil {
+			return apperrors.FromStatus(errCode, err)
+		}
+	}
+
+	return nil
+}
+
+// CheckAppraisalType makes sure the appraisal type exists
+func CheckAppraisalType(db *gorm.DB, appraisal_type string) error {
+	log.Info("Checking Appraisal type")
+	var appraisalTypeModel models.AppraisalType
+	err := db.Model(&models.AppraisalType{}).Where("appraisal_type = ?", appraisal_type).First(&appraisalTypeModel).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CheckAssignType returns the assign type with its name
+func CheckAssignType(db *gorm.DB, assignType uint16) (models.AssignType, string, error) {
+	log.Info("Checking assign type")
+	var assignTypeModel models.AssignType
+	err := db.Where("assign_type_id = ?", assignType).First(&assignTypeModel).Error
+	if err != nil {
+		return assignTypeModel, "", err
+	}
+	assigntypename := string(assignTypeModel.AssignType)
+	return assignTypeModel, assigntypename, nil
+}
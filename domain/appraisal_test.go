@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+)
+
+func TestAppraisalServiceCheckNew(t *testing.T) {
+	yesterday := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		appraisal models.Appraisal
+		wantErr   string
+	}{
+		{
+			name:      "due date in the past",
+			appraisal: models.Appraisal{DueDate: &yesterday},
+			wantErr:   "due_date should be in the future",
+		},
+		{
+			name:      "kpi without employee",
+			appraisal: models.Appraisal{AppraisalKpis: []models.AppraisalKpi{{KpiID: 1, Status: "active"}}},
+			wantErr:   "employee_id field is required",
+		},
+		{
+			name:      "kpi without kpi id",
+			appraisal: models.Appraisal{AppraisalKpis: []models.AppraisalKpi{{EmployeeID: 1, Status: "active"}}},
+			wantErr:   "kpi_id field is required",
+		},
+		{
+			name:      "kpi without status",
+			appraisal: models.Appraisal{AppraisalKpis: []models.AppraisalKpi{{EmployeeID: 1, KpiID: 1}}},
+			wantErr:   "status field is required",
+		},
+	}
+
+	appraisals := NewAppraisalService(unreachableDB(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := appraisals.CheckNew(context.Background(), &tt.appraisal)
+			if apperrors.KindOf(err) != apperrors.KindValidation || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want a validation error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewEmployeeData(t *testing.T) {
+	t.Setenv("TOSS_BASE_URL", "https://toss.example.com")
+
+	appraisal := &models.Appraisal{CommonModel: models.CommonModel{ID: 5}}
+	detail := utils.EmployeeDetails{
+		EmployeeID:      12,
+		EmployeeName:    "Employee 12",
+		Designation:     3,
+		DesignationName: "Engineer",
+		EmployeeImage:   "images/12.png",
+		TeamID:          4,
+		TeamName:        "Platform",
+	}
+
+	want := models.EmployeeData{
+		AppraisalID:     5,
+		TossEmpID:       12,
+		EmployeeName:    "Employee 12",
+		EmployeeImage:   "https://toss.example.com/images/12.png",
+		TeamID:          4,
+		TeamName:        "Platform",
+		Designation:     3,
+		DesignationName: "Engineer",
+		AppraisalStatus: constants.APPRAISAL_STATUS_PENDING,
+	}
+	if got := NewEmployeeData(appraisal, detail); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
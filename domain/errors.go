@@ -0,0 +1,21 @@
+// Package domain holds the business rules of appraisals, KPIs, appraisal flows
+// and scoring, independent of the transport they are used from. Operations take
+// typed inputs and fail with the typed errors of apperrors, whose kind the
+// transport maps to its own status codes.
+package domain
+
+import (
+	"errors"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"gorm.io/gorm"
+)
+
+// lookup fails the operation with the error of fetching a record, telling a
+// missing record apart from a failing database
+func lookup(err error, notFoundMessage string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.Wrap(apperrors.KindNotFound, notFoundMessage, err)
+	}
+	return apperrors.Internal(err)
+}
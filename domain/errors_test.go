@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"gorm.io/gorm"
+)
+
+func TestLookup(t *testing.T) {
+	failure := errors.New("connection refused")
+
+	tests := []struct {
+		name        string
+		err         error
+		wantKind    apperrors.Kind
+		wantMessage string
+	}{
+		{name: "missing record", err: gorm.ErrRecordNotFound, wantKind: apperrors.KindNotFound, wantMessage: "kpi not found"},
+		{name: "wrapped missing record", err: fmt.Errorf("get kpi: %w", gorm.ErrRecordNotFound), wantKind: apperrors.KindNotFound, wantMessage: "kpi not found"},
+		{name: "failing database", err: failure, wantKind: apperrors.KindInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := lookup(tt.err, "kpi not found")
+			if kind := apperrors.KindOf(err); kind != tt.wantKind {
+				t.Errorf("got kind %v, want %v", kind, tt.wantKind)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%v does not wrap %v", err, tt.err)
+			}
+
+			var appErr *apperrors.Error
+			if tt.wantMessage != "" && (!errors.As(err, &appErr) || appErr.Message != tt.wantMessage) {
+				t.Errorf("got %v, want the message %q", err, tt.wantMessage)
+			}
+		})
+	}
+}
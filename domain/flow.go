@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type FlowService struct {
+	Db *gorm.DB
+}
+
+func NewFlowService(db *gorm.DB) *FlowService {
+	return &FlowService{Db: db}
+}
+
+// FlowFilter narrows down the appraisal flows listed. Empty fields match every
+// flow.
+type FlowFilter struct {
+	FlowName string
+	IsActive string
+	TeamID   string
+}
+
+func (s *FlowService) Create(ctx context.Context, appraisalFlow *models.AppraisalFlow) (*models.AppraisalFlow, error) {
+	appraisalFlow.ID = 0
+	if err := s.check(ctx, appraisalFlow); err != nil {
+		return nil, err
+	}
+
+	dbAppraisalFlow, err := controller.CreateAppraisalFlow(s.Db.WithContext(ctx), appraisalFlow)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return dbAppraisalFlow, nil
+}
+
+func (s *FlowService) Update(ctx context.Context, id uint16, appraisalFlow *models.AppraisalFlow) (*models.AppraisalFlow, error) {
+	appraisalFlow.ID = id
+	if err := s.check(ctx, appraisalFlow); err != nil {
+		return nil, err
+	}
+
+	if err := controller.UpdateAppraisalFlow(s.Db.WithContext(ctx), appraisalFlow); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return appraisalFlow, nil
+}
+
+// check validates an appraisal flow being created or updated against TOSS and
+// sets the names of its assign type and of whom it is assigned to
+func (s *FlowService) check(ctx context.Context, appraisalFlow *models.AppraisalFlow) error {
+	if err := appraisalFlow.Validate(); err != nil {
+		return apperrors.Validation(err)
+	}
+
+	// Validate each FlowStep struct
+	for _, flowStep := range appraisalFlow.FlowSteps {
+		errCode, err := utils.CheckIndividualAgainstToss(ctx, uint16(flowStep.UserId))
+		if err != nil {
+			return apperrors.FromStatus(errCode, err)
+		}
+		if err := flowStep.Validate(); err != nil {
+			return apperrors.Validation(err)
+		}
+	}
+
+	assignType, name, err := CheckAssignType(s.Db.WithContext(ctx), uint16(appraisalFlow.AssignTypeID))
+	if err != nil {
+		return apperrors.Invalid("invalid assign type")
+	}
+	appraisalFlow.AssignTypeName = name
+
+	//Check team role and individual
+	errCode, name, err := utils.VerifyIdAgainstTossApis(ctx, appraisalFlow.SelectedAssignID, string(assignType.AssignType))
+	if err != nil {
+		return apperrors.FromStatus(errCode, err)
+	}
+	appraisalFlow.SelectedAssignName = name
+
+	return nil
+}
+
+func (s *FlowService) Get(ctx context.Context, id uint64) (*models.AppraisalFlow, error) {
+	var appraisalFlow models.AppraisalFlow
+	if err := controller.GetAppraisalFlowByID(s.Db.WithContext(ctx), &appraisalFlow, id); err != nil {
+		return nil, lookup(err, "Record not found against appraisal flow id")
+	}
+
+	return &appraisalFlow, nil
+}
+
+func (s *FlowService) List(ctx context.Context, filter FlowFilter) ([]models.AppraisalFlow, error) {
+	var appraisalFlows []models.AppraisalFlow
+	if err := controller.GetAllAppraisalFlow(filter.FlowName, filter.IsActive, filter.TeamID, s.Db.WithContext(ctx), &appraisalFlows); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return appraisalFlows, nil
+}
+
+func (s *FlowService) Delete(ctx context.Context, id uint64) error {
+	appraisalFlow, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := controller.DeleteAppraisalFlow(s.Db.WithContext(ctx), appraisalFlow, id); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+func TestFlowServiceRejectsInvalidFlows(t *testing.T) {
+	valid := func() *models.AppraisalFlow {
+		active := true
+		return &models.AppraisalFlow{
+			FlowName:         "Engineering",
+			AssignTypeID:     1,
+			SelectedAssignID: 2,
+			IsActive:         &active,
+			AppraisalTypeStr: "Annual",
+			FlowSteps:        []models.FlowStep{{StepName: "Supervisor", StepOrder: 1, UserId: 7}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(flow *models.AppraisalFlow)
+		wantField string
+	}{
+		{name: "missing name", modify: func(flow *models.AppraisalFlow) { flow.FlowName = "" }, wantField: "FlowName"},
+		{name: "long name", modify: func(flow *models.AppraisalFlow) { flow.FlowName = strings.Repeat("a", 31) }, wantField: "FlowName"},
+		{name: "missing assign type", modify: func(flow *models.AppraisalFlow) { flow.AssignTypeID = 0 }, wantField: "AssignTypeID"},
+		{name: "missing is active", modify: func(flow *models.AppraisalFlow) { flow.IsActive = nil }, wantField: "IsActive"},
+		{name: "missing steps", modify: func(flow *models.AppraisalFlow) { flow.FlowSteps = nil }, wantField: "FlowSteps"},
+	}
+
+	flows := NewFlowService(unreachableDB(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := valid()
+			tt.modify(flow)
+
+			_, err := flows.Create(context.Background(), flow)
+			if apperrors.KindOf(err) != apperrors.KindValidation || !strings.Contains(err.Error(), "'"+tt.wantField+"'") {
+				t.Fatalf("Create returned %v, want a validation error of %s", err, tt.wantField)
+			}
+			_, err = flows.Update(context.Background(), 3, flow)
+			if apperrors.KindOf(err) != apperrors.KindValidation || !strings.Contains(err.Error(), "'"+tt.wantField+"'") {
+				t.Fatalf("Update returned %v, want a validation error of %s", err, tt.wantField)
+			}
+		})
+	}
+}
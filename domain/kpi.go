@@ -0,0 +1,295 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type KPIService struct {
+	Db *gorm.DB
+}
+
+func NewKPIService(db *gorm.DB) *KPIService {
+	return &KPIService{Db: db}
+}
+
+// KPIFilter narrows down the KPIs listed. Empty fields match every KPI, and a
+// team matches the KPIs of the team, of its members and of their designations.
+type KPIFilter struct {
+	KpiName    string
+	AssignType string
+	KpiType    string
+	TeamID     string
+	RoleID     string
+	EmployeeID string
+}
+
+func (s *KPIService) Create(ctx context.Context, kpi *models.Kpi) (*models.Kpi, error) {
+	kpi.ID = 0
+	if _, err := s.check(ctx, kpi); err != nil {
+		return nil, err
+	}
+
+	dbKpi, err := controller.CreateKPI(s.Db.WithContext(ctx), kpi)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return dbKpi, nil
+}
+
+func (s *KPIService) Update(ctx context.Context, id uint16, kpi *models.Kpi) (*models.Kpi, error) {
+	db := s.Db.WithContext(ctx)
+
+	kpi.ID = id
+	kpiType, err := s.check(ctx, kpi)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the Kpi is being updated from a MultiStatementKpi to a SingleStatementKpi,
+	// delete all existing MultiStatementKpiData records for the given KpiID.
+	if kpiType.BasicKpiType == constants.SINGLE_KPI_TYPE {
+		if err := db.Where("kpi_id = ?", kpi.ID).Delete(&models.MultiStatementKpiData{}).Error; err != nil {
+			return nil, apperrors.Internal(err)
+		}
+	}
+
+	dbKpi, err := controller.UpdateKPI(db, kpi)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return dbKpi, nil
+}
+
+// check validates a KPI being created or updated and sets the names of its
+// assign type and of whom it is assigned to. It returns the type of the KPI.
+func (s *KPIService) check(ctx context.Context, kpi *models.Kpi) (models.KpiType, error) {
+	db := s.Db.WithContext(ctx)
+
+	if err := kpi.Validate(); err != nil {
+		return models.KpiType{}, apperrors.Validation(err)
+	}
+
+	kpiType, err := checkKpiType(db, kpi.KpiTypeStr)
+	if err != nil {
+		return kpiType, apperrors.Invalid("invalid KPI type")
+	}
+
+	assignType, name, err := CheckAssignType(db, uint16(kpi.AssignTypeID))
+	if err != nil {
+		return models.KpiType{}, apperrors.Invalid("invalid assign type")
+	}
+	kpi.AssignTypeName = name
+
+	switch kpiType.BasicKpiType {
+	case constants.SINGLE_KPI_TYPE:
+		if kpi.Statement == "" {
+			return models.KpiType{}, apperrors.Invalid("statement is nil")
+		}
+
+		kpi.Statements = nil
+	case constants.MULTI_KPI_TYPE:
+		if len(kpi.Statements) == 0 {
+			return models.KpiType{}, apperrors.Invalid("statements field is nil")
+		}
+
+		kpi.Statement = ""
+	}
+
+	// Validate MultiStatementKpiData fields
+	for _, mskd := range kpi.Statements {
+		if err := mskd.Validate(); err != nil {
+			return models.KpiType{}, apperrors.Validation(err)
+		}
+	}
+
+	// Validate questionnaire options and answer keys
+	if err := checkQuestionnaireStatements(kpi); err != nil {
+		return models.KpiType{}, apperrors.Invalid(err.Error())
+	}
+
+	// check rating scale exists
+	kpi.RatingScale = nil
+	if kpi.RatingScaleID != nil {
+		if _, err := controller.GetRatingScaleByID(db, uint64(*kpi.RatingScaleID)); err != nil {
+			return models.KpiType{}, apperrors.Invalid("invalid rating scale id")
+		}
+	}
+
+	errCode, name, err := utils.VerifyIdAgainstTossApis(ctx, kpi.SelectedAssignID, string(assignType.AssignType))
+	if err != nil {
+		return models.KpiType{}, apperrors.FromStatus(errCode, err)
+	}
+	kpi.SelectedAssignName = name
+
+	return kpiType, nil
+}
+
+func (s *KPIService) Get(ctx context.Context, id uint64) (*models.Kpi, error) {
+	kpi, err := controller.GetKPIByID(s.Db.WithContext(ctx), id)
+	if err != nil {
+		return nil, lookup(err, "Record not found against kpi id")
+	}
+
+	return &kpi, nil
+}
+
+func (s *KPIService) List(ctx context.Context, filter KPIFilter) ([]models.Kpi, error) {
+	var kpis []models.Kpi
+	db := s.Db.WithContext(ctx).Model(&models.Kpi{})
+
+	if filter.KpiName != "" {
+		db = db.Where("kpi_name LIKE ?", "%"+filter.KpiName+"%")
+	}
+
+	if filter.AssignType != "" {
+		db = db.Where("assign_type_id = ?", filter.AssignType)
+	}
+
+	if filter.KpiType != "" {
+		db = db.Where("kpi_type_str = ?", filter.KpiType)
+	}
+	if filter.RoleID != "" {
+		db = db.Where("assign_type_name = ? AND selected_assign_id = ?", constants.ASSIGN_TYPE_ROLE, filter.RoleID)
+	}
+	if filter.EmployeeID != "" {
+		db = db.Where("assign_type_name = ? AND selected_assign_id = ?", constants.ASSIGN_TYPE_INDIVIDUAL, filter.EmployeeID)
+	}
+
+	if filter.TeamID != "" {
+		teamID, err := strconv.ParseUint(filter.TeamID, 10, 16)
+		if err != nil {
+			return nil, apperrors.Invalid("unable to parse team id")
+		}
+
+		empIds, err := utils.GetEmployeesId(ctx, uint16(teamID))
+		if err != nil {
+			return nil, apperrors.Upstream("failed to fetch employee ids", err)
+		}
+		roleIds, err := utils.GetRolesID(ctx, empIds)
+		if err != nil {
+			return nil, apperrors.Upstream("failed to fetch roles ids", err)
+		}
+
+		db = db.Joins("JOIN assign_types ON assign_types.id = kpis.assign_type_id").
+			Where(`(kpis.selected_assign_id = ? AND assign_types.assign_type = ?)
+        OR (kpis.selected_assign_id IN (?) AND assign_types.assign_type = ?)
+        OR (kpis.selected_assign_id IN (?) AND assign_types.assign_type = ?)`,
+				filter.TeamID, constants.ASSIGN_TYPE_TEAM, empIds, constants.ASSIGN_TYPE_INDIVIDUAL, roleIds, constants.ASSIGN_TYPE_ROLE)
+	}
+
+	if err := controller.GetAllKPI(db, &kpis); err != nil {
+		return nil, apperrors.Wrap(apperrors.KindInternal, "failed to fetch kpis", err)
+	}
+
+	return kpis, nil
+}
+
+func (s *KPIService) Delete(ctx context.Context, id uint64) error {
+	if err := controller.DeleteKPI(s.Db.WithContext(ctx), id); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
+
+func (s *KPIService) GetKpiTypes(ctx context.Context) ([]models.KpiType, error) {
+	var kpiTypes []models.KpiType
+	if err := s.Db.WithContext(ctx).Model(&models.KpiType{}).Preload("RatingScale.Levels").Order("id ASC").Find(&kpiTypes).Error; err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return kpiTypes, nil
+}
+
+// SetKpiTypeRatingScale attaches a rating scale to a KPI type. A nil rating
+// scale detaches the current one.
+func (s *KPIService) SetKpiTypeRatingScale(ctx context.Context, id uint64, ratingScaleID *uint16) (*models.KpiType, error) {
+	db := s.Db.WithContext(ctx)
+
+	var kpiType models.KpiType
+	if err := db.Model(&models.KpiType{}).First(&kpiType, id).Error; err != nil {
+		return nil, lookup(err, "Record not found against kpi type id")
+	}
+
+	if ratingScaleID != nil {
+		if _, err := controller.GetRatingScaleByID(db, uint64(*ratingScaleID)); err != nil {
+			return nil, apperrors.Invalid("invalid rating scale id")
+		}
+	}
+
+	if err := db.Model(&kpiType).Update("rating_scale_id", ratingScaleID).Error; err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	if err := db.Model(&models.KpiType{}).Preload("RatingScale.Levels").First(&kpiType, id).Error; err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return &kpiType, nil
+}
+
+func checkKpiType(db *gorm.DB, kpiType string) (models.KpiType, error) {
+	log.Info("Checking KPI type")
+	var kpiTypeModel models.KpiType
+	err := db.Where("kpi_type = ?", kpiType).First(&kpiTypeModel).Error
+	if err != nil {
+		return kpiTypeModel, err
+	}
+	return kpiTypeModel, nil
+}
+
+// checkQuestionnaireStatements makes sure every statement of a questionnaire KPI
+// has at least two distinct options and a correct answer picked from them.
+// Answer keys are meaningless for other KPI types, so they are cleared.
+func checkQuestionnaireStatements(kpi *models.Kpi) error {
+	log.Info("Checking questionnaire statements")
+	for i := range kpi.Statements {
+		statement := &kpi.Statements[i]
+
+		if kpi.KpiTypeStr != constants.QUESTIONNAIRE_KPI_TYPE {
+			statement.Options = nil
+			statement.CorrectAnswer = ""
+			continue
+		}
+
+		if len(statement.Options) < 2 {
+			return fmt.Errorf("at least 2 options are required for statement '%s'", statement.Statement)
+		}
+
+		seen := make(map[string]bool)
+		for k, option := range statement.Options {
+			option = strings.TrimSpace(option)
+			if option == "" {
+				return fmt.Errorf("empty option in statement '%s'", statement.Statement)
+			}
+			if seen[option] {
+				return fmt.Errorf("duplicate option '%s' in statement '%s'", option, statement.Statement)
+			}
+			seen[option] = true
+			statement.Options[k] = option
+		}
+
+		statement.CorrectAnswer = strings.TrimSpace(statement.CorrectAnswer)
+		if statement.CorrectAnswer == "" {
+			return fmt.Errorf("correct_answer is required for statement '%s'", statement.Statement)
+		}
+		if !seen[statement.CorrectAnswer] {
+			return fmt.Errorf("correct_answer of statement '%s' is not one of its options", statement.Statement)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+func TestCheckQuestionnaireStatements(t *testing.T) {
+	tests := []struct {
+		name        string
+		kpiType     string
+		statement   models.MultiStatementKpiData
+		wantErr     string
+		wantOptions []string
+		wantAnswer  string
+	}{
+		{
+			name:        "options and answer are trimmed",
+			kpiType:     constants.QUESTIONNAIRE_KPI_TYPE,
+			statement:   models.MultiStatementKpiData{Statement: "Language", Options: []string{" Go", "Rust "}, CorrectAnswer: " Go "},
+			wantOptions: []string{"Go", "Rust"},
+			wantAnswer:  "Go",
+		},
+		{
+			name:      "single option",
+			kpiType:   constants.QUESTIONNAIRE_KPI_TYPE,
+			statement: models.MultiStatementKpiData{Statement: "Language", Options: []string{"Go"}, CorrectAnswer: "Go"},
+			wantErr:   "at least 2 options are required",
+		},
+		{
+			name:      "empty option",
+			kpiType:   constants.QUESTIONNAIRE_KPI_TYPE,
+			statement: models.MultiStatementKpiData{Statement: "Language", Options: []string{"Go", "  "}, CorrectAnswer: "Go"},
+			wantErr:   "empty option",
+		},
+		{
+			name:      "duplicate option",
+			kpiType:   constants.QUESTIONNAIRE_KPI_TYPE,
+			statement: models.MultiStatementKpiData{Statement: "Language", Options: []string{"Go", " Go"}, CorrectAnswer: "Go"},
+			wantErr:   "duplicate option 'Go'",
+		},
+		{
+			name:      "missing answer",
+			kpiType:   constants.QUESTIONNAIRE_KPI_TYPE,
+			statement: models.MultiStatementKpiData{Statement: "Language", Options: []string{"Go", "Rust"}},
+			wantErr:   "correct_answer is required",
+		},
+		{
+			name:      "answer out of the options",
+			kpiType:   constants.QUESTIONNAIRE_KPI_TYPE,
+			statement: models.MultiStatementKpiData{Statement: "Language", Options: []string{"Go", "Rust"}, CorrectAnswer: "Zig"},
+			wantErr:   "is not one of its options",
+		},
+		{
+			name:      "answer keys of other types are cleared",
+			kpiType:   constants.MEASURED_KPI_TYPE,
+			statement: models.MultiStatementKpiData{Statement: "Delivery", Options: []string{"Go"}, CorrectAnswer: "Go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kpi := &models.Kpi{KpiTypeStr: tt.kpiType, Statements: []models.MultiStatementKpiData{tt.statement}}
+			err := checkQuestionnaireStatements(kpi)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkQuestionnaireStatements: %v", err)
+			}
+
+			statement := kpi.Statements[0]
+			if len(statement.Options) != len(tt.wantOptions) || (len(tt.wantOptions) > 0 && !reflect.DeepEqual([]string(statement.Options), tt.wantOptions)) {
+				t.Errorf("got options %q, want %q", statement.Options, tt.wantOptions)
+			}
+			if statement.CorrectAnswer != tt.wantAnswer {
+				t.Errorf("got correct answer %q, want %q", statement.CorrectAnswer, tt.wantAnswer)
+			}
+		})
+	}
+}
+
+func TestKPIServiceRejectsInvalidKpis(t *testing.T) {
+	valid := func() *models.Kpi {
+		return &models.Kpi{
+			KpiName:          "Delivery",
+			KpiDescription:   "Delivers on time",
+			AssignTypeID:     1,
+			SelectedAssignID: 2,
+			KpiTypeStr:       constants.MEASURED_KPI_TYPE,
+			KpiWeight:        10,
+			ApplicableFor:    []string{"Engineer"},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(kpi *models.Kpi)
+		wantField string
+	}{
+		{name: "missing name", modify: func(kpi *models.Kpi) { kpi.KpiName = "" }, wantField: "kpi_name"},
+		{name: "short name", modify: func(kpi *models.Kpi) { kpi.KpiName = "Do" }, wantField: "kpi_name"},
+		{name: "missing type", modify: func(kpi *models.Kpi) { kpi.KpiTypeStr = "" }, wantField: "kpi_type"},
+		{name: "missing weight", modify: func(kpi *models.Kpi) { kpi.KpiWeight = 0 }, wantField: "kpi_weight"},
+		{name: "missing applicable for", modify: func(kpi *models.Kpi) { kpi.ApplicableFor = nil }, wantField: "applicable_for"},
+	}
+
+	kpis := NewKPIService(unreachableDB(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kpi := valid()
+			tt.modify(kpi)
+
+			_, err := kpis.Create(context.Background(), kpi)
+			if apperrors.KindOf(err) != apperrors.KindValidation || !strings.Contains(err.Error(), "'"+tt.wantField+"'") {
+				t.Fatalf("Create returned %v, want a validation error of %s", err, tt.wantField)
+			}
+			_, err = kpis.Update(context.Background(), 3, kpi)
+			if apperrors.KindOf(err) != apperrors.KindValidation || !strings.Contains(err.Error(), "'"+tt.wantField+"'") {
+				t.Fatalf("Update returned %v, want a validation error of %s", err, tt.wantField)
+			}
+		})
+	}
+}
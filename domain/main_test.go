@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	_ = os.Setenv("LOG_LEVEL", "error")
+	logger.TextLogInit()
+	os.Exit(m.Run())
+}
+
+// unreachableDB returns a database nothing listens on, for the operations that
+// fail before querying it, or to check how they report a failing database
+func unreachableDB(t testing.TB) *gorm.DB {
+	db, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1 user=test dbname=test sslmode=disable connect_timeout=1"), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
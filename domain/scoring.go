@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScoringService struct {
+	Db *gorm.DB
+}
+
+func NewScoringService(db *gorm.DB) *ScoringService {
+	return &ScoringService{Db: db}
+}
+
+// AddScores scores the kpis of an employee in an appraisal. Every kpi other
+// than a questionnaire needs a score, measured kpis within their rating scale,
+// and delegates score on behalf of the evaluator only while the delegation is
+// active.
+func (s *ScoringService) AddScores(ctx context.Context, appraisalID, employeeID uint64, score []models.Score) ([]models.Score, error) {
+	db := s.Db.WithContext(ctx)
+
+	// Get all the appraisalKpi IDs for the given appraisalID and employeeID
+	var existingKpis []models.AppraisalKpi
+	if err := db.Model(&models.AppraisalKpi{}).Preload(clause.Associations).Where("appraisal_id = ? AND employee_id = ?", appraisalID, employeeID).Find(&existingKpis).Error; err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	if len(existingKpis) == 0 {
+		return nil, apperrors.NotFound("Record not found against appraisal id")
+	}
+	appraisalKpi := existingKpis[0]
+
+	// Create a map of existing appraisal_kpi_id for faster lookup. Questionnaires are
+	// graded from the employee's answers, so they are not scored by the evaluator.
+	existingKpiMap := make(map[uint16]models.AppraisalKpi)
+	for _, kpi := range existingKpis {
+		if kpi.Kpi.KpiTypeStr != constants.QUESTIONNAIRE_KPI_TYPE {
+			existingKpiMap[kpi.ID] = kpi
+		}
+	}
+
+	if len(existingKpiMap) == 0 {
+		return nil, apperrors.Invalid("questionnaire kpis are graded from the submitted answers")
+	}
+
+	if len(score) != len(existingKpiMap) {
+		return nil, apperrors.Invalid("number of scores does not match the number of appraisal_kpi records")
+	}
+
+	// Check if the appraisal_kpi_id exists in the database and matches with the existing appraisal_kpi records
+	for k := range score {
+		existingKpi, ok := existingKpiMap[score[k].AppraisalKpiID]
+		if !ok {
+			return nil, apperrors.Invalid(fmt.Sprintf("invalid appraisal_kpi_id :%v", score[k].AppraisalKpiID))
+		}
+
+		kpiType := existingKpi.Kpi.KpiTypeStr
+		score[k].Percentage = nil
+
+		switch kpiType {
+		case constants.FEEDBACK_KPI_TYPE, constants.OBSERVATORY_KPI_TYPE:
+			score[k].Score = nil
+
+		case constants.MEASURED_KPI_TYPE:
+			score[k].TextAnswer = ""
+
+			// Validate the score against the rating scale of the kpi
+			ratingScale, err := controller.GetKpiRatingScale(db, existingKpi.Kpi)
+			if err != nil {
+				return nil, apperrors.Internal(err)
+			}
+			if ratingScale == nil {
+				continue
+			}
+
+			if score[k].Score == nil {
+				return nil, apperrors.Invalid(fmt.Sprintf("score is required for appraisal_kpi_id :%v", score[k].AppraisalKpiID))
+			}
+			if _, ok := ratingScale.Level(*score[k].Score); !ok {
+				return nil, apperrors.Invalid(fmt.Sprintf("score %v is not a level of rating scale '%s'", *score[k].Score, ratingScale.ScaleName))
+			}
+
+			percentage := float64(*score[k].Score) * 100 / float64(ratingScale.MaxValue())
+			score[k].Percentage = &percentage
+		}
+	}
+
+	// A delegate scores on behalf of the delegator only while the delegation is active
+	for k := range score {
+		if score[k].OnBehalfOf == 0 {
+			continue
+		}
+		if score[k].OnBehalfOf == score[k].EvaluatorID {
+			score[k].OnBehalfOf = 0
+			continue
+		}
+
+		isDelegate, err := controller.IsActiveDelegate(db, score[k].OnBehalfOf, score[k].EvaluatorID)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		if !isDelegate {
+			return nil, apperrors.Forbidden(fmt.Sprintf("evaluator %v has no active delegation from %v", score[k].EvaluatorID, score[k].OnBehalfOf))
+		}
+	}
+
+	scores, err := controller.AddScore(db, score, appraisalKpi.AppraisalID, appraisalKpi.EmployeeID)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	if err := controller.AttachScoreLabels(db, scores); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return scores, nil
+}
+
+// GetScores returns the scores of an employee in an appraisal with the labels
+// of their rating scales
+func (s *ScoringService) GetScores(ctx context.Context, appraisalID, employeeID uint64) ([]models.Score, error) {
+	db := s.Db.WithContext(ctx)
+
+	var scores []models.Score
+	if err := controller.GetScoresByEmpID(db, &scores, appraisalID, employeeID); err != nil {
+		return nil, lookup(err, "No score found against the employee")
+	}
+
+	if err := controller.AttachScoreLabels(db, scores); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+func TestScoringServiceFailingDatabase(t *testing.T) {
+	scoring := NewScoringService(unreachableDB(t))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "add scores", call: func() error {
+			_, err := scoring.AddScores(ctx, 1, 2, []models.Score{{AppraisalKpiID: 3}})
+			return err
+		}},
+		{name: "get scores", call: func() error {
+			_, err := scoring.GetScores(ctx, 1, 2)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); apperrors.KindOf(err) != apperrors.KindInternal {
+				t.Fatalf("got %v of kind %v, want an internal error", err, apperrors.KindOf(err))
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/mrehanabbasi/appraisal-system-backend/database"
 	"github.com/mrehanabbasi/appraisal-system-backend/logger"
 	"github.com/mrehanabbasi/appraisal-system-backend/routes"
+	"github.com/mrehanabbasi/appraisal-system-backend/storage"
 )
 
 func main() {
@@ -34,6 +35,9 @@ func main() {
 	// Connect to the database
 	database.Connect()
 
+	// Configure the storage of attachments
+	storage.Connect()
+
 	// Register all the routes
 	server := routes.NewRouter()
 
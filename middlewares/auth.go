@@ -12,6 +12,7 @@ import (
 	"github.com/auth0/go-jwt-middleware/v2/jwks"
 	"github.com/auth0/go-jwt-middleware/v2/validator"
 	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	"github.com/mrehanabbasi/appraisal-system-backend/constants"
 	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
 	"github.com/mrehanabbasi/appraisal-system-backend/models"
@@ -23,7 +24,8 @@ func VerifyToken() gin.HandlerFunc {
 	if err != nil {
 		log.Error("failed to parse the issuer url: ", err.Error())
 		return func(c *gin.Context) {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to parse the issuer url"})
+			_ = c.Error(apperrors.Internal(errors.New("failed to parse the issuer url")))
+			c.Abort()
 		}
 	}
 
@@ -44,7 +46,8 @@ func VerifyToken() gin.HandlerFunc {
 	if err != nil {
 		log.Error("failed to set up the jwt validator: ", err.Error())
 		return func(c *gin.Context) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "failed to set up the jwt validator"})
+			_ = c.Error(apperrors.Unauthorized("failed to set up the jwt validator"))
+			c.Abort()
 		}
 	}
 
@@ -68,7 +71,8 @@ func VerifyToken() gin.HandlerFunc {
 		middleware.CheckJWT(handler).ServeHTTP(c.Writer, c.Request)
 
 		if encounteredError {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid jwt token"})
+			_ = c.Error(apperrors.Unauthorized("invalid jwt token"))
+			c.Abort()
 			return
 		}
 	}
@@ -78,17 +82,15 @@ func ValidateJWTClaims(c *gin.Context) {
 	// Getting claims
 	claims, ok := c.Request.Context().Value(jwtmiddleware.ContextKey{}).(*validator.ValidatedClaims)
 	if !ok {
-		err := errors.New("failed to validate jwt claims")
-		log.Error(err.Error())
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		_ = c.Error(apperrors.Unauthorized("failed to validate jwt claims"))
+		c.Abort()
 		return
 	}
 
 	tossClaims, ok := claims.CustomClaims.(*models.TossClaims)
 	if !ok {
-		err := errors.New("failed to cast custom jwt claims to the desired type")
-		log.Error(err.Error())
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		_ = c.Error(apperrors.Unauthorized("failed to cast custom jwt claims to the desired type"))
+		c.Abort()
 		return
 	}
 
@@ -96,10 +98,10 @@ func ValidateJWTClaims(c *gin.Context) {
 	designationID, _ := strconv.ParseUint(tossClaims.Designation, 10, 16)
 	supID, _ := strconv.ParseUint(tossClaims.Supervisor, 10, 16)
 	roleID, _ := strconv.ParseUint(tossClaims.Role, 10, 16)
-	supName, err := utils.GetSupervisorName(uint16(supID))
+	supName, err := utils.GetSupervisorName(c.Request.Context(), uint16(supID))
 	if err != nil {
-		log.Error(err.Error())
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(apperrors.Upstream("", err))
+		c.Abort()
 		return
 	}
 
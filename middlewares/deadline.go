@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deadline bounds the context of every request by the deadline of its route, so
+// the database queries and TOSS requests made for it are cancelled once it
+// passes or the client goes away. The deadlines are keyed by the method and the
+// path of the route, and other routes get the fallback.
+func Deadline(deadlines map[string]time.Duration, fallback time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := deadlines[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = fallback
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+// problemKind is how a kind of failure is rendered
+type problemKind struct {
+	status int
+	code   string
+}
+
+var problemKinds = map[apperrors.Kind]problemKind{
+	apperrors.KindInternal:         {http.StatusInternalServerError, "internal"},
+	apperrors.KindValidation:       {http.StatusBadRequest, "validation_failed"},
+	apperrors.KindNotFound:         {http.StatusNotFound, "not_found"},
+	apperrors.KindConflict:         {http.StatusConflict, "conflict"},
+	apperrors.KindForbidden:        {http.StatusForbidden, "forbidden"},
+	apperrors.KindUnauthorized:     {http.StatusUnauthorized, "unauthorized"},
+	apperrors.KindUpstream:         {http.StatusBadGateway, "upstream_failed"},
+	apperrors.KindTooLarge:         {http.StatusRequestEntityTooLarge, "too_large"},
+	apperrors.KindUnsupportedMedia: {http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	apperrors.KindUnprocessable:    {http.StatusUnprocessableEntity, "unprocessable"},
+}
+
+// Problems renders the error a handler failed with as an RFC 7807
+// application/problem+json response. Handlers record the error with c.Error and
+// return without writing a response; the kind of a typed error picks the status
+// and the code, and validation errors list their fields. The causes of internal
+// errors are only logged, since they can tell the schema or the queries.
+func Problems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		errKind := apperrors.KindOf(err)
+		kind, ok := problemKinds[errKind]
+		if !ok {
+			errKind = apperrors.KindInternal
+			kind = problemKinds[errKind]
+		}
+
+		problem := models.Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(kind.status),
+			Status:   kind.status,
+			Detail:   err.Error(),
+			Instance: c.Request.URL.Path,
+			Code:     kind.code,
+		}
+		if fields, ok := controller.ErrValidationFields(err); ok {
+			problem.Errors = fields
+			if len(fields) > 0 {
+				problem.Detail = fields[0].Message
+			}
+		}
+
+		if errKind == apperrors.KindInternal {
+			log.Error(err.Error())
+			problem.Detail = "an unexpected error occurred"
+		} else {
+			log.Error(problem.Detail)
+		}
+		c.Header("Content-Type", "application/problem+json")
+		c.JSON(problem.Status, problem)
+	}
+}
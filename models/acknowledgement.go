@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Acknowledgement is the sign-off of an employee on their published results. The
+// hash of the results at the time of signing makes later changes detectable.
+type Acknowledgement struct {
+	CommonModel
+	AppraisalID    uint16    `gorm:"not null;default:0;uniqueIndex:idx_acknowledgement_employee" json:"appraisal_id"`
+	EmployeeID     uint16    `gorm:"not null;default:0;uniqueIndex:idx_acknowledgement_employee" json:"employee_id"`
+	Decision       string    `gorm:"not null;default:''" json:"decision" validate:"required,oneof=acknowledged disagreed"`
+	Comments       string    `gorm:"not null;default:''" json:"comments,omitempty" validate:"max=1000"`
+	ResultsHash    string    `gorm:"not null;default:''" json:"results_hash"`
+	AcknowledgedAt time.Time `gorm:"not null" json:"acknowledged_at"`
+	ResultsChanged bool      `gorm:"-" json:"results_changed"`
+}
+
+func (a *Acknowledgement) Validate() error {
+	return validateJSONNames(a)
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Appeal is the dispute of an employee against the ratings of some of their kpis
+type Appeal struct {
+	CommonModel
+	AppraisalID     uint16           `gorm:"not null;default:0;index" json:"appraisal_id" validate:"required"`
+	EmployeeID      uint16           `gorm:"not null;default:0;index" json:"employee_id"`
+	EmployeeName    string           `gorm:"default:''" json:"employee_name,omitempty"`
+	AppraisalKpiIDs pq.Int64Array    `gorm:"type:integer[]" json:"appraisal_kpi_ids" validate:"required,min=1"`
+	Reason          string           `gorm:"not null;default:''" json:"reason" validate:"required,min=10,max=1000"`
+	RoutedTo        string           `gorm:"not null;default:''" json:"routed_to"`
+	ReviewerID      uint16           `gorm:"not null;default:0" json:"reviewer_id,omitempty"`
+	ReviewerName    string           `gorm:"default:''" json:"reviewer_name,omitempty"`
+	Status          string           `gorm:"not null;default:''" json:"status"`
+	DueDate         time.Time        `gorm:"not null" json:"due_date"`
+	Overdue         bool             `gorm:"-" json:"overdue"`
+	Resolution      string           `gorm:"not null;default:''" json:"resolution,omitempty"`
+	ResolvedBy      uint16           `gorm:"not null;default:0" json:"resolved_by,omitempty"`
+	ResolvedAt      *time.Time       `json:"resolved_at,omitempty"`
+	AdjustmentID    *uint16          `json:"adjustment_id,omitempty"`
+	Adjustment      *ScoreAdjustment `json:"adjustment,omitempty"`
+	Comments        []AppealComment  `gorm:"foreignKey:AppealID;constraint:OnDelete:CASCADE" json:"comments,omitempty"`
+}
+
+type AppealComment struct {
+	CommonModel
+	AppealID    uint16    `gorm:"not null;default:0;index" json:"appeal_id"`
+	AuthorID    uint16    `gorm:"not null;default:0" json:"author_id"`
+	Comment     string    `gorm:"not null;default:''" json:"comment" validate:"required,max=1000"`
+	CommentedAt time.Time `gorm:"not null" json:"commented_at"`
+}
+
+// Request bodies of the appeal workflow
+
+type AppealReview struct {
+	ReviewerID uint16 `json:"reviewer_id" validate:"required"`
+}
+
+type AppealResolution struct {
+	ResolvedBy    uint16   `json:"resolved_by" validate:"required"`
+	Decision      string   `json:"decision" validate:"required,oneof=upheld adjusted"`
+	Resolution    string   `json:"resolution" validate:"required,min=10,max=1000"`
+	AdjustedScore *float64 `json:"adjusted_score" validate:"omitempty,gte=0,lte=100"`
+}
+
+func (a *Appeal) Validate() error {
+	return validateJSONNames(a)
+}
+
+func (a *AppealComment) Validate() error {
+	return validateJSONNames(a)
+}
+
+func (a *AppealReview) Validate() error {
+	return validateJSONNames(a)
+}
+
+func (a *AppealResolution) Validate() error {
+	return validateJSONNames(a)
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Appraisal struct {
 	CommonModel
 	AppraisalName      string         `gorm:"not null;default:''" json:"appraisal_name" binding:"required,min=3,max=20"`
@@ -16,6 +18,8 @@ type Appraisal struct {
 	SelectedFieldNames string         `json:"appraisal_for_name,omitempty"`
 	AssignType         AssignType     `gorm:"references:AssignTypeId;foreignKey:AppraisalFor" json:"-"`
 	Status             *bool          `gorm:"not null;default:false" json:"status" binding:"required"`
+	DueDate            *time.Time     `json:"due_date,omitempty"`
+	CampaignID         *uint16        `gorm:"index" json:"campaign_id,omitempty"`
 	AppraisalKpis      []AppraisalKpi `gorm:"foreignKey:AppraisalID;not null" json:"appraisal_kpis"`
 	EmployeesList      []EmployeeData `gorm:"foreignKey:AppraisalID" json:"employee_data,omitempty"`
 }
@@ -31,6 +35,9 @@ type EmployeeData struct {
 	Designation     uint16 `gorm:"not null;default:0" json:"designation_id"`
 	DesignationName string `gorm:"default:''" json:"designation_name,omitempty"`
 	AppraisalStatus string `gorm:"not null;default:false" json:"appraisal_status"`
+	// Supervisor the employee was transferred to, the supervisor of the appraisal when 0
+	SupervisorID   uint16 `gorm:"not null;default:0" json:"supervisor_id,omitempty"`
+	SupervisorName string `gorm:"default:''" json:"supervisor_name,omitempty"`
 }
 
 type AppraisalKpi struct {
@@ -47,6 +54,11 @@ type AppraisalType struct {
 	AppraisalType string `gorm:"not null;unique;default:''" json:"appraisal_type"`
 }
 
+// AppraisalResponse is the response of the GetAppraisalByID endpoint
+type AppraisalResponse struct {
+	Appraisal Appraisal `json:"appraisal"`
+}
+
 // Model for GetAllAppraisal Endpoint
 
 type Employees struct {
@@ -0,0 +1,16 @@
+package models
+
+// Attachment is a file of evidence on an appraisal kpi. The file itself is kept
+// in the storage under the storage key.
+type Attachment struct {
+	CommonModel
+	AppraisalKpiID uint16 `gorm:"not null;default:0;index" json:"appraisal_kpi_id"`
+	AppraisalID    uint16 `gorm:"not null;default:0" json:"appraisal_id"`
+	EmployeeID     uint16 `gorm:"not null;default:0" json:"employee_id"`
+	FileName       string `gorm:"not null;default:''" json:"file_name"`
+	ContentType    string `gorm:"not null;default:''" json:"content_type"`
+	Size           int64  `gorm:"not null;default:0" json:"size"`
+	Checksum       string `gorm:"not null;default:''" json:"checksum"`
+	StorageKey     string `gorm:"not null;default:''" json:"-"`
+	UploadedBy     uint16 `gorm:"not null;default:0" json:"uploaded_by"`
+}
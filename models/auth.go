@@ -3,6 +3,8 @@ package models
 import (
 	"context"
 	"errors"
+	"os"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -19,6 +21,13 @@ type TokenInfo struct {
 	EmpRoleID     uint16
 }
 
+// IsHR reports whether the caller belongs to the department named by the
+// HR_DEPARTMENT environment variable. Nobody is HR while it is unset.
+func (t TokenInfo) IsHR() bool {
+	hrDepartment := os.Getenv("HR_DEPARTMENT")
+	return hrDepartment != "" && strings.EqualFold(t.Department, hrDepartment)
+}
+
 type TossClaims struct {
 	jwt.RegisteredClaims
 	ClientID         string           `json:"client_id"`
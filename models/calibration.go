@@ -0,0 +1,36 @@
+package models
+
+import "github.com/lib/pq"
+
+type CalibrationSession struct {
+	CommonModel
+	SessionName  string            `gorm:"size:100;not null;unique;default:''" json:"session_name" validate:"required,min=3,max=50"`
+	AppraisalIDs pq.Int64Array     `gorm:"type:integer[];not null" json:"appraisal_ids" validate:"required,min=1"`
+	TeamIDs      pq.Int64Array     `gorm:"type:integer[]" json:"team_ids,omitempty"`
+	CreatedBy    uint16            `gorm:"not null;default:0" json:"created_by"`
+	IsClosed     *bool             `gorm:"not null;default:false" json:"is_closed"`
+	Adjustments  []ScoreAdjustment `gorm:"foreignKey:CalibrationSessionID" json:"adjustments,omitempty"`
+}
+
+// Model for the score distribution of a calibration session
+
+type SupervisorDistribution struct {
+	SupervisorID   uint16   `json:"supervisor_id"`
+	SupervisorName string   `json:"supervisor_name"`
+	EmployeeCount  int64    `json:"employee_count"`
+	ScoredCount    int64    `json:"scored_count"`
+	AverageScore   *float64 `json:"average_score"`
+	MinScore       *float64 `json:"min_score"`
+	MaxScore       *float64 `json:"max_score"`
+	StdDevScore    *float64 `json:"stddev_score"`
+	AverageFinal   *float64 `json:"average_final_score"`
+	AdjustedCount  int64    `json:"adjusted_count"`
+}
+
+func (c *CalibrationSession) Validate() error {
+	return validateJSONNames(c)
+}
+
+func (s *ScoreAdjustment) Validate() error {
+	return validateJSONNames(s)
+}
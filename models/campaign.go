@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Campaign creates a team appraisal for every team of the organization, or for
+// the listed teams, in one request. Each appraisal is supervised by the
+// supervisor of its team in TOSS. Teams that cannot be appraised are skipped.
+type Campaign struct {
+	CommonModel
+	CampaignName     string                `gorm:"not null;unique;default:''" json:"campaign_name" validate:"required,min=3,max=50"`
+	AppraisalYear    uint16                `gorm:"not null;default:0" json:"appraisal_year" validate:"required,gte=2023"`
+	AppraisalTypeStr string                `gorm:"not null;default:''" json:"appraisal_type" validate:"required"`
+	AppraisalFlowID  uint16                `gorm:"not null;default:0" json:"appraisal_flow_id" validate:"required"`
+	DueDate          *time.Time            `json:"due_date,omitempty"`
+	TeamIDs          pq.Int64Array         `gorm:"type:integer[]" json:"team_ids,omitempty"`
+	CreatedBy        uint16                `gorm:"not null;default:0" json:"created_by"`
+	Appraisals       []Appraisal           `gorm:"foreignKey:CampaignID" json:"appraisals,omitempty"`
+	SkippedTeams     []CampaignSkippedTeam `gorm:"foreignKey:CampaignID;constraint:OnDelete:CASCADE" json:"skipped_teams,omitempty"`
+}
+
+type CampaignSkippedTeam struct {
+	CommonModel
+	CampaignID uint16 `gorm:"not null;default:0;index" json:"campaign_id"`
+	TeamID     uint16 `gorm:"not null;default:0" json:"team_id"`
+	TeamName   string `gorm:"not null;default:''" json:"team_name"`
+	Reason     string `gorm:"not null;default:''" json:"reason"`
+}
+
+func (c *Campaign) Validate() error {
+	return validateJSONNames(c)
+}
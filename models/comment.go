@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Comment is a threaded comment on an appraisal kpi or on a score. Comments
+// private to HR are never shown to the employee.
+type Comment struct {
+	CommonModel
+	AppraisalID    uint16            `gorm:"not null;default:0;index:idx_comment_employee" json:"appraisal_id"`
+	EmployeeID     uint16            `gorm:"not null;default:0;index:idx_comment_employee" json:"employee_id"`
+	AppraisalKpiID *uint16           `gorm:"index" json:"appraisal_kpi_id,omitempty"`
+	ScoreID        *uint16           `gorm:"index" json:"score_id,omitempty"`
+	ParentID       *uint16           `gorm:"index" json:"parent_id,omitempty"`
+	AuthorID       uint16            `gorm:"not null;default:0" json:"author_id" validate:"required"`
+	AuthorName     string            `gorm:"default:''" json:"author_name,omitempty"`
+	AuthorRole     string            `gorm:"not null;default:''" json:"author_role" validate:"required,oneof=employee supervisor hr"`
+	Visibility     string            `gorm:"not null;default:''" json:"visibility" validate:"omitempty,oneof=shared hr_private"`
+	Body           string            `gorm:"not null;default:''" json:"body" validate:"required,max=2000"`
+	Mentions       pq.Int64Array     `gorm:"type:integer[]" json:"mentions,omitempty"`
+	Edited         bool              `gorm:"not null;default:false" json:"edited"`
+	Revisions      []CommentRevision `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE" json:"revisions,omitempty"`
+	Replies        []Comment         `gorm:"-" json:"replies,omitempty"`
+}
+
+// CommentRevision keeps the body of a comment as it was before an edit
+type CommentRevision struct {
+	CommonModel
+	CommentID uint16    `gorm:"not null;default:0;index" json:"comment_id"`
+	Body      string    `gorm:"not null;default:''" json:"body"`
+	EditedAt  time.Time `gorm:"not null" json:"edited_at"`
+}
+
+type CommentEdit struct {
+	AuthorID uint16  `json:"author_id" validate:"required"`
+	Body     string  `json:"body" validate:"required,max=2000"`
+	Mentions []int64 `json:"mentions"`
+}
+
+func (c *Comment) Validate() error {
+	return validateJSONNames(c)
+}
+
+func (c *CommentEdit) Validate() error {
+	return validateJSONNames(c)
+}
+
+// EmployeeReport gathers the results of an employee with the kpis, scores and
+// comments they were based on, for the exports
+type EmployeeReport struct {
+	Result   EmployeeResult
+	Kpis     []AppraisalKpi
+	Scores   []Score
+	Comments []Comment
+}
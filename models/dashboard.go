@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Models for the HR dashboard endpoints
+
+type CycleDashboard struct {
+	AppraisalYear        uint16             `json:"appraisal_year"`
+	AppraisalType        string             `json:"appraisal_type"`
+	AppraisalCount       int64              `json:"appraisal_count"`
+	EmployeeCount        int64              `json:"employee_count"`
+	StatusCounts         []StatusCount      `json:"status_counts"`
+	TotalKpis            int64              `json:"total_kpis"`
+	ScoredKpis           int64              `json:"scored_kpis"`
+	OutstandingKpis      int64              `json:"outstanding_kpis"`
+	CompletionPercentage float64            `json:"completion_percentage"`
+	OverdueEvaluators    []OverdueEvaluator `json:"overdue_evaluators"`
+}
+
+type StatusCount struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+type OverdueEvaluator struct {
+	EvaluatorID     uint16    `json:"evaluator_id"`
+	EvaluatorName   string    `json:"evaluator_name"`
+	AppraisalCount  int64     `json:"appraisal_count"`
+	OutstandingKpis int64     `json:"outstanding_kpis"`
+	EarliestDueDate time.Time `json:"earliest_due_date"`
+}
+
+type CompletionStat struct {
+	GroupID              uint16  `json:"group_id"`
+	GroupName            string  `json:"group_name"`
+	EmployeeCount        int64   `json:"employee_count"`
+	TotalKpis            int64   `json:"total_kpis"`
+	ScoredKpis           int64   `json:"scored_kpis"`
+	CompletionPercentage float64 `json:"completion_percentage"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Delegation hands the evaluator rights of the delegator over to the delegate
+// from the start date until the end date. It expires by itself after the end date.
+// The delegator is always the employee creating it.
+type Delegation struct {
+	CommonModel
+	DelegatorID   uint16    `gorm:"not null;default:0;index" json:"delegator_id"`
+	DelegatorName string    `gorm:"default:''" json:"delegator_name,omitempty"`
+	DelegateID    uint16    `gorm:"not null;default:0;index" json:"delegate_id" validate:"required"`
+	DelegateName  string    `gorm:"default:''" json:"delegate_name,omitempty"`
+	StartDate     time.Time `gorm:"not null" json:"start_date" validate:"required"`
+	EndDate       time.Time `gorm:"not null" json:"end_date" validate:"required"`
+	Reason        string    `gorm:"not null;default:''" json:"reason" validate:"max=255"`
+	IsRevoked     *bool     `gorm:"not null;default:false" json:"is_revoked"`
+	IsActive      bool      `gorm:"-" json:"is_active"`
+}
+
+// AppraisalHistory records the actions taken on the appraisal of an employee
+type AppraisalHistory struct {
+	CommonModel
+	AppraisalID uint16    `gorm:"not null;default:0;index:idx_history_employee" json:"appraisal_id"`
+	EmployeeID  uint16    `gorm:"not null;default:0;index:idx_history_employee" json:"employee_id"`
+	Action      string    `gorm:"not null;default:''" json:"action"`
+	ActorID     uint16    `gorm:"not null;default:0" json:"actor_id"`
+	OnBehalfOf  uint16    `gorm:"not null;default:0" json:"on_behalf_of,omitempty"`
+	Details     string    `gorm:"not null;default:''" json:"details,omitempty"`
+	ActedAt     time.Time `gorm:"not null" json:"acted_at"`
+}
+
+func (d *Delegation) Validate() error {
+	return validateJSONNames(d)
+}
+
+// Active reports whether the delegation is in effect at the given time
+func (d *Delegation) Active(at time.Time) bool {
+	if d.IsRevoked != nil && *d.IsRevoked {
+		return false
+	}
+	return !d.StartDate.After(at) && d.EndDate.After(at)
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Local mirror of the TOSS directory, kept up to date by the sync job
+
+type DirectoryEmployee struct {
+	CommonModel
+	EmployeeID      uint16     `gorm:"not null;default:0;uniqueIndex" json:"employee_id"`
+	EmployeeName    string     `gorm:"not null;default:''" json:"employee_name"`
+	Designation     uint16     `gorm:"not null;default:0" json:"designation_id"`
+	DesignationName string     `gorm:"not null;default:''" json:"designation_name"`
+	IsActive        bool       `gorm:"not null;default:false" json:"is_active"`
+	LeftAt          *time.Time `json:"left_at,omitempty"`
+	SyncedAt        time.Time  `gorm:"not null" json:"synced_at"`
+}
+
+type DirectoryDesignation struct {
+	CommonModel
+	DesignationID   uint16    `gorm:"not null;default:0;uniqueIndex" json:"designation_id"`
+	DesignationName string    `gorm:"not null;default:''" json:"designation_name"`
+	IsActive        bool      `gorm:"not null;default:false" json:"is_active"`
+	SyncedAt        time.Time `gorm:"not null" json:"synced_at"`
+}
+
+type DirectoryProject struct {
+	CommonModel
+	ProjectID   uint16                   `gorm:"not null;default:0;uniqueIndex" json:"project_id"`
+	ProjectName string                   `gorm:"not null;default:''" json:"project_name"`
+	IsActive    bool                     `gorm:"not null;default:false" json:"is_active"`
+	SyncedAt    time.Time                `gorm:"not null" json:"synced_at"`
+	Members     []DirectoryProjectMember `gorm:"foreignKey:ProjectID;references:ProjectID" json:"members,omitempty"`
+}
+
+type DirectoryProjectMember struct {
+	CommonModel
+	ProjectID          uint16 `gorm:"not null;default:0;uniqueIndex:idx_directory_member" json:"project_id"`
+	EmployeeID         uint16 `gorm:"not null;default:0;uniqueIndex:idx_directory_member" json:"employee_id"`
+	EmployeeName       string `gorm:"not null;default:''" json:"employee_name"`
+	SupervisorName     string `gorm:"not null;default:''" json:"supervisor_name"`
+	ProjectStartedDate string `gorm:"not null;default:''" json:"project_started_date"`
+}
+
+// DirectorySyncRun is one run of the sync job with the number of changes it
+// detected
+type DirectorySyncRun struct {
+	CommonModel
+	StartedAt   time.Time  `gorm:"not null" json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	Status      string     `gorm:"not null;default:''" json:"status"`
+	Error       string     `gorm:"not null;default:''" json:"error,omitempty"`
+	Employees   int        `gorm:"not null;default:0" json:"employees"`
+	Projects    int        `gorm:"not null;default:0" json:"projects"`
+	ChangeCount int        `gorm:"not null;default:0" json:"change_count"`
+}
+
+// DirectoryChange is a change of the TOSS directory detected by a sync run
+type DirectoryChange struct {
+	CommonModel
+	SyncRunID  uint16    `gorm:"not null;default:0;index" json:"sync_run_id"`
+	EntityType string    `gorm:"not null;default:''" json:"entity_type"`
+	EntityID   uint16    `gorm:"not null;default:0;index" json:"entity_id"`
+	EntityName string    `gorm:"not null;default:''" json:"entity_name"`
+	ChangeType string    `gorm:"not null;default:'';index" json:"change_type"`
+	OldValue   string    `gorm:"not null;default:''" json:"old_value,omitempty"`
+	NewValue   string    `gorm:"not null;default:''" json:"new_value,omitempty"`
+	DetectedAt time.Time `gorm:"not null;index" json:"detected_at"`
+}
+
+// Model for the roster changes of the employees of an appraisal cycle
+
+type CycleRosterChanges struct {
+	AppraisalYear uint16            `json:"appraisal_year"`
+	AppraisalType string            `json:"appraisal_type"`
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	Joiners       []DirectoryChange `json:"joiners"`
+	Leavers       []DirectoryChange `json:"leavers"`
+	TeamMoves     []DirectoryChange `json:"team_moves"`
+}
@@ -0,0 +1,57 @@
+package models
+
+type DistributionCurve struct {
+	CommonModel
+	CurveName          string             `gorm:"size:100;not null;unique;default:''" json:"curve_name" validate:"required,min=3,max=30"`
+	AssignTypeID       uint16             `gorm:"not null;default:0" json:"assign_type_id"`
+	AssignTypeName     string             `json:"assign_type_name,omitempty"`
+	SelectedAssignID   uint16             `gorm:"not null;default:0" json:"selected_assign_id"`
+	SelectedAssignName string             `json:"selected_assign_name,omitempty"`
+	Tolerance          float64            `gorm:"not null;default:0" json:"tolerance" validate:"gte=0,lte=100"`
+	Bands              []DistributionBand `gorm:"foreignKey:CurveID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"bands" validate:"required,min=1,dive"`
+}
+
+type DistributionBand struct {
+	CommonModel
+	CurveID          uint16  `gorm:"not null;default:0" json:"-"`
+	BandName         string  `gorm:"not null;default:''" json:"band_name" validate:"required,max=50"`
+	MinScore         float64 `gorm:"not null;default:0" json:"min_score" validate:"gte=0,lte=100"`
+	MaxScore         float64 `gorm:"not null;default:0" json:"max_score" validate:"gte=0,lte=100"`
+	TargetPercentage float64 `gorm:"not null;default:0" json:"target_percentage" validate:"gte=0,lte=100"`
+}
+
+// Model for the distribution analytics endpoint
+
+type DistributionReport struct {
+	AppraisalYear uint16              `json:"appraisal_year"`
+	AppraisalType string              `json:"appraisal_type"`
+	GroupBy       string              `json:"group_by"`
+	Groups        []GroupDistribution `json:"groups"`
+}
+
+type GroupDistribution struct {
+	GroupID       uint16       `json:"group_id"`
+	GroupName     string       `json:"group_name"`
+	CurveID       uint16       `json:"curve_id"`
+	CurveName     string       `json:"curve_name"`
+	Tolerance     float64      `json:"tolerance"`
+	EmployeeCount int64        `json:"employee_count"`
+	ScoredCount   int64        `json:"scored_count"`
+	Bands         []BandResult `json:"bands"`
+	MaxDeviation  float64      `json:"max_deviation"`
+	Flagged       bool         `json:"flagged"`
+}
+
+type BandResult struct {
+	BandName         string  `json:"band_name"`
+	MinScore         float64 `json:"min_score"`
+	MaxScore         float64 `json:"max_score"`
+	TargetPercentage float64 `json:"target_percentage"`
+	ActualCount      int64   `json:"actual_count"`
+	ActualPercentage float64 `json:"actual_percentage"`
+	Deviation        float64 `json:"deviation"`
+}
+
+func (d *DistributionCurve) Validate() error {
+	return validateJSONNames(d)
+}
@@ -0,0 +1,67 @@
+package models
+
+// Models for the multi-year performance history of an employee
+
+type PerformanceHistory struct {
+	EmployeeID         uint16             `json:"employee_id"`
+	EmployeeName       string             `json:"employee_name"`
+	Appraisals         []AppraisalPoint   `json:"appraisals"`
+	KpiTrends          []KpiTrend         `json:"kpi_trends"`
+	DesignationChanges []AssignmentChange `json:"designation_changes"`
+	TeamChanges        []AssignmentChange `json:"team_changes"`
+	YearOverYear       []YearDelta        `json:"year_over_year"`
+}
+
+// AppraisalPoint is the result of the employee in one appraisal. The delta is
+// against the previous appraisal of the employee, of any type.
+type AppraisalPoint struct {
+	AppraisalID     uint16   `json:"appraisal_id"`
+	AppraisalName   string   `json:"appraisal_name"`
+	AppraisalYear   uint16   `json:"appraisal_year"`
+	AppraisalType   string   `json:"appraisal_type"`
+	TeamID          uint16   `json:"team_id"`
+	TeamName        string   `json:"team_name"`
+	Designation     uint16   `json:"designation_id"`
+	DesignationName string   `json:"designation_name"`
+	SupervisorID    uint16   `json:"supervisor_id"`
+	SupervisorName  string   `json:"supervisor_name"`
+	ComputedScore   *float64 `json:"computed_score"`
+	FinalScore      *float64 `json:"final_score"`
+	Delta           *float64 `json:"delta"`
+}
+
+type KpiTrend struct {
+	KpiID   uint16     `json:"kpi_id"`
+	KpiName string     `json:"kpi_name"`
+	Points  []KpiPoint `json:"points"`
+}
+
+// KpiPoint is the average score percentage of a kpi over its evaluators in one
+// appraisal
+type KpiPoint struct {
+	AppraisalID   uint16   `json:"appraisal_id"`
+	AppraisalYear uint16   `json:"appraisal_year"`
+	AppraisalType string   `json:"appraisal_type"`
+	Percentage    *float64 `json:"percentage"`
+}
+
+type AssignmentChange struct {
+	AppraisalID   uint16 `json:"appraisal_id"`
+	AppraisalYear uint16 `json:"appraisal_year"`
+	AppraisalType string `json:"appraisal_type"`
+	FromID        uint16 `json:"from_id"`
+	FromName      string `json:"from_name"`
+	ToID          uint16 `json:"to_id"`
+	ToName        string `json:"to_name"`
+}
+
+// YearDelta compares the final score of a year with the one of the previous year
+// for the same appraisal type
+type YearDelta struct {
+	AppraisalYear uint16   `json:"appraisal_year"`
+	AppraisalType string   `json:"appraisal_type"`
+	FinalScore    *float64 `json:"final_score"`
+	PreviousYear  uint16   `json:"previous_year"`
+	PreviousScore *float64 `json:"previous_score"`
+	Delta         *float64 `json:"delta"`
+}
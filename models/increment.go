@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// IncrementPolicy maps the final score bands of annual appraisals to increments
+// and promotion eligibility. A policy without a designation applies to every
+// designation without a policy of its own.
+type IncrementPolicy struct {
+	CommonModel
+	PolicyName       string          `gorm:"size:100;not null;unique;default:''" json:"policy_name" validate:"required,min=3,max=30"`
+	AppraisalTypeStr string          `gorm:"not null;default:''" json:"appraisal_type" validate:"required"`
+	Designation      uint16          `gorm:"not null;default:0" json:"designation_id"`
+	DesignationName  string          `json:"designation_name,omitempty"`
+	Bands            []IncrementBand `gorm:"foreignKey:PolicyID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"bands" validate:"required,min=1,dive"`
+}
+
+type IncrementBand struct {
+	CommonModel
+	PolicyID            uint16  `gorm:"not null;default:0" json:"-"`
+	BandName            string  `gorm:"not null;default:''" json:"band_name" validate:"required,max=50"`
+	MinScore            float64 `gorm:"not null;default:0" json:"min_score" validate:"gte=0,lte=100"`
+	MaxScore            float64 `gorm:"not null;default:0" json:"max_score" validate:"gte=0,lte=100"`
+	IncrementPercentage float64 `gorm:"not null;default:0" json:"increment_percentage" validate:"gte=0,lte=100"`
+	PromotionEligible   bool    `gorm:"not null;default:false" json:"promotion_eligible"`
+}
+
+// Recommendation is a row of the increment recommendation sheet of a cycle.
+// Supervisors override the recommended values with a justification and HR
+// approves them.
+type Recommendation struct {
+	CommonModel
+	AppraisalID           uint16     `gorm:"not null;default:0;uniqueIndex:idx_recommendation_employee" json:"appraisal_id"`
+	AppraisalYear         uint16     `gorm:"not null;default:0;index:idx_recommendation_cycle" json:"appraisal_year"`
+	AppraisalType         string     `gorm:"not null;default:'';index:idx_recommendation_cycle" json:"appraisal_type"`
+	EmployeeID            uint16     `gorm:"not null;default:0;uniqueIndex:idx_recommendation_employee" json:"employee_id"`
+	EmployeeName          string     `gorm:"default:''" json:"employee_name"`
+	Designation           uint16     `gorm:"not null;default:0" json:"designation_id"`
+	DesignationName       string     `gorm:"default:''" json:"designation_name"`
+	TeamName              string     `gorm:"default:''" json:"team_name"`
+	SupervisorID          uint16     `gorm:"not null;default:0" json:"supervisor_id"`
+	FinalScore            *float64   `json:"final_score"`
+	PolicyID              *uint16    `json:"policy_id"`
+	BandName              string     `gorm:"not null;default:''" json:"band_name"`
+	RecommendedIncrement  float64    `gorm:"not null;default:0" json:"recommended_increment"`
+	RecommendedPromotion  bool       `gorm:"not null;default:false" json:"recommended_promotion"`
+	OverrideIncrement     *float64   `json:"override_increment,omitempty"`
+	OverridePromotion     *bool      `json:"override_promotion,omitempty"`
+	OverrideJustification string     `gorm:"not null;default:''" json:"override_justification,omitempty"`
+	OverriddenBy          uint16     `gorm:"not null;default:0" json:"overridden_by,omitempty"`
+	OverriddenAt          *time.Time `json:"overridden_at,omitempty"`
+	Status                string     `gorm:"not null;default:''" json:"status"`
+	ApprovedBy            uint16     `gorm:"not null;default:0" json:"approved_by,omitempty"`
+	ApprovedAt            *time.Time `json:"approved_at,omitempty"`
+	FinalIncrement        float64    `gorm:"-" json:"final_increment"`
+	FinalPromotion        bool       `gorm:"-" json:"final_promotion"`
+}
+
+// Request bodies of the recommendation workflow. The supervisor overriding and
+// the approver are the caller.
+
+type RecommendationOverride struct {
+	SupervisorID        uint16   `json:"-"`
+	IncrementPercentage *float64 `json:"increment_percentage" validate:"required,gte=0,lte=100"`
+	PromotionEligible   *bool    `json:"promotion_eligible" validate:"required"`
+	Justification       string   `json:"justification" validate:"required,min=10,max=1000"`
+}
+
+type RecommendationApproval struct {
+	ApprovedBy        uint16  `json:"-"`
+	RecommendationIDs []int64 `json:"recommendation_ids" validate:"required,min=1"`
+}
+
+// Model for the outcome of generating the sheet of a cycle
+
+type RecommendationSheet struct {
+	AppraisalYear   uint16           `json:"appraisal_year"`
+	AppraisalType   string           `json:"appraisal_type"`
+	Generated       int              `json:"generated"`
+	Unmatched       int              `json:"unmatched"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+func (p *IncrementPolicy) Validate() error {
+	return validateJSONNames(p)
+}
+
+func (r *RecommendationOverride) Validate() error {
+	return validateJSONNames(r)
+}
+
+func (r *RecommendationApproval) Validate() error {
+	return validateJSONNames(r)
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Job is a long-running operation executed in the background. Bulk jobs work
+// through items and record the ones that failed without failing the whole job.
+type Job struct {
+	CommonModel
+	JobType        string     `gorm:"not null;default:'';index" json:"job_type"`
+	Status         string     `gorm:"not null;default:'';index" json:"status"`
+	Payload        string     `gorm:"type:text;not null;default:''" json:"-"`
+	TotalItems     int        `gorm:"not null;default:0" json:"total_items"`
+	CompletedItems int        `gorm:"not null;default:0" json:"completed_items"`
+	FailedItems    int        `gorm:"not null;default:0" json:"failed_items"`
+	ResultID       uint16     `gorm:"not null;default:0" json:"result_id,omitempty"`
+	ResultURL      string     `gorm:"not null;default:''" json:"result_url,omitempty"`
+	Error          string     `gorm:"not null;default:''" json:"error,omitempty"`
+	QueuedAt       time.Time  `gorm:"not null" json:"queued_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	Errors         []JobError `gorm:"foreignKey:JobID;constraint:OnDelete:CASCADE" json:"errors"`
+	StatusURL      string     `gorm:"-" json:"status_url,omitempty"`
+}
+
+// JobError is an item a bulk job failed to process
+type JobError struct {
+	CommonModel
+	JobID uint16 `gorm:"not null;default:0;index" json:"job_id"`
+	Item  string `gorm:"not null;default:''" json:"item"`
+	Error string `gorm:"not null;default:''" json:"error"`
+}
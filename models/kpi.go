@@ -13,8 +13,16 @@ type AssignTypeStr string
 
 type KpiType struct {
 	CommonModel
-	KpiType      string       `gorm:"not null;unique" json:"kpi_type"`
-	BasicKpiType BasicKpiType `gorm:"not null" json:"basic_kpi_type"`
+	KpiType       string       `gorm:"not null;unique" json:"kpi_type"`
+	BasicKpiType  BasicKpiType `gorm:"not null" json:"basic_kpi_type"`
+	RatingScaleID *uint16      `json:"rating_scale_id"`
+	RatingScale   *RatingScale `json:"rating_scale,omitempty"`
+}
+
+// KpiTypeRatingScale attaches a rating scale to a kpi type, or detaches the
+// current one when null
+type KpiTypeRatingScale struct {
+	RatingScaleID *uint16 `json:"rating_scale_id"`
 }
 
 type AssignType struct {
@@ -38,14 +46,17 @@ type Kpi struct {
 	ApplicableFor      pq.StringArray          `gorm:"type:text[];not null" json:"applicable_for" validate:"required"`
 	Statement          string                  `json:"statement,omitempty"`
 	Statements         []MultiStatementKpiData `gorm:"foreignKey:KpiID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"statements,omitempty"`
+	RatingScaleID      *uint16                 `json:"rating_scale_id,omitempty"`
+	RatingScale        *RatingScale            `json:"rating_scale,omitempty"`
 }
 
 type MultiStatementKpiData struct {
 	CommonModel
-	KpiID     uint16 `gorm:"not null;default:0" json:"-"`
-	Statement string `gorm:"not null;default:''" json:"statement" validate:"required"`
-	Weightage uint8  `gorm:"not null;default:0" json:"weightage" validate:"required"`
-	// CorrectAnswer string `gorm:"not null" json:"correct_answer" validate:"required"`
+	KpiID         uint16         `gorm:"not null;default:0" json:"-"`
+	Statement     string         `gorm:"not null;default:''" json:"statement" validate:"required"`
+	Weightage     uint8          `gorm:"not null;default:0" json:"weightage" validate:"required"`
+	Options       pq.StringArray `gorm:"type:text[]" json:"options,omitempty"`
+	CorrectAnswer string         `gorm:"not null;default:''" json:"correct_answer,omitempty"`
 }
 
 func (a *Kpi) Validate() error {
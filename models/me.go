@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Models for the endpoints of the authenticated employee
+
+type EmployeeAppraisal struct {
+	AppraisalID     uint16     `json:"appraisal_id"`
+	AppraisalName   string     `json:"appraisal_name"`
+	AppraisalYear   uint16     `json:"appraisal_year"`
+	AppraisalType   string     `json:"appraisal_type"`
+	SupervisorID    uint16     `json:"supervisor_id"`
+	SupervisorName  string     `json:"supervisor_name"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+	IsActive        bool       `json:"is_active"`
+	AppraisalStatus string     `json:"appraisal_status"`
+}
+
+type SelfReviewTask struct {
+	AppraisalID    uint16     `json:"appraisal_id"`
+	AppraisalName  string     `json:"appraisal_name"`
+	AppraisalKpiID uint16     `json:"appraisal_kpi_id"`
+	KpiID          uint16     `json:"kpi_id"`
+	KpiName        string     `json:"kpi_name"`
+	KpiType        string     `json:"kpi_type"`
+	DueDate        *time.Time `json:"due_date,omitempty"`
+}
+
+type PublishRequest struct {
+	EmployeeIDs []uint16 `json:"employee_ids"`
+}
+
+type InboxTask struct {
+	AppraisalID     uint16     `json:"appraisal_id"`
+	AppraisalName   string     `json:"appraisal_name"`
+	EmployeeID      uint16     `json:"employee_id"`
+	EmployeeName    string     `json:"employee_name"`
+	TeamName        string     `json:"team_name"`
+	DesignationName string     `json:"designation_name"`
+	AppraisalStatus string     `json:"appraisal_status"`
+	AssignedAs      string     `json:"assigned_as"`
+	OnBehalfOf      uint16     `json:"on_behalf_of,omitempty"`
+	CurrentStep     string     `json:"current_step,omitempty"`
+	TotalKpis       int64      `json:"total_kpis"`
+	PendingKpis     int64      `json:"pending_kpis"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+	Overdue         bool       `json:"overdue"`
+	ScoreURL        string     `json:"score_url"`
+}
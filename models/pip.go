@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// Pip is the performance improvement plan of an employee after an appraisal. Its
+// owner is the supervisor of the appraisal.
+type Pip struct {
+	CommonModel
+	AppraisalID     uint16         `gorm:"not null;default:0;index" json:"appraisal_id" validate:"required"`
+	EmployeeID      uint16         `gorm:"not null;default:0;index" json:"employee_id" validate:"required"`
+	EmployeeName    string         `gorm:"default:''" json:"employee_name,omitempty"`
+	OwnerID         uint16         `gorm:"not null;default:0;index" json:"owner_id"`
+	OwnerName       string         `gorm:"default:''" json:"owner_name,omitempty"`
+	OpenedBy        uint16         `gorm:"not null;default:0" json:"opened_by"`
+	Reason          string         `gorm:"not null;default:''" json:"reason" validate:"required,min=10,max=1000"`
+	TriggerScore    *float64       `json:"trigger_score"`
+	StartDate       time.Time      `gorm:"not null" json:"start_date" validate:"required"`
+	MidReviewDate   *time.Time     `json:"mid_review_date,omitempty"`
+	FinalReviewDate time.Time      `gorm:"not null" json:"final_review_date" validate:"required"`
+	Status          string         `gorm:"not null;default:''" json:"status"`
+	Outcome         string         `gorm:"not null;default:''" json:"outcome,omitempty"`
+	OutcomeNotes    string         `gorm:"not null;default:''" json:"outcome_notes,omitempty"`
+	ClosedBy        uint16         `gorm:"not null;default:0" json:"closed_by,omitempty"`
+	ClosedAt        *time.Time     `json:"closed_at,omitempty"`
+	Objectives      []PipObjective `gorm:"foreignKey:PipID;constraint:OnDelete:CASCADE" json:"objectives" validate:"required,min=1,dive"`
+	CheckIns        []PipCheckIn   `gorm:"foreignKey:PipID;constraint:OnDelete:CASCADE" json:"check_ins,omitempty" validate:"dive"`
+}
+
+type PipObjective struct {
+	CommonModel
+	PipID           uint16     `gorm:"not null;default:0;index" json:"pip_id"`
+	Description     string     `gorm:"not null;default:''" json:"description" validate:"required,max=1000"`
+	SuccessCriteria string     `gorm:"not null;default:''" json:"success_criteria" validate:"required,max=1000"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+	Status          string     `gorm:"not null;default:''" json:"status" validate:"omitempty,oneof=pending met not_met"`
+}
+
+// PipCheckIn is a meeting of the owner with the employee during the plan. It is
+// scheduled first and recorded once held.
+type PipCheckIn struct {
+	CommonModel
+	PipID       uint16     `gorm:"not null;default:0;index" json:"pip_id"`
+	ScheduledAt time.Time  `gorm:"not null" json:"scheduled_at" validate:"required"`
+	HeldAt      *time.Time `json:"held_at,omitempty"`
+	Progress    string     `gorm:"not null;default:''" json:"progress,omitempty" validate:"omitempty,oneof=on_track at_risk off_track"`
+	Notes       string     `gorm:"not null;default:''" json:"notes,omitempty" validate:"max=2000"`
+	RecordedBy  uint16     `gorm:"not null;default:0" json:"recorded_by,omitempty"`
+}
+
+// Request body closing or extending a plan. The plan is decided by the caller.
+
+type PipOutcome struct {
+	DecidedBy       uint16     `json:"-"`
+	Outcome         string     `json:"outcome" validate:"required,oneof=successful unsuccessful extended"`
+	Notes           string     `json:"notes" validate:"required,min=10,max=1000"`
+	FinalReviewDate *time.Time `json:"final_review_date"`
+}
+
+// Model for the employees suggested for a plan
+
+type PipCandidate struct {
+	AppraisalID     uint16   `json:"appraisal_id"`
+	AppraisalName   string   `json:"appraisal_name"`
+	EmployeeID      uint16   `json:"employee_id"`
+	EmployeeName    string   `json:"employee_name"`
+	TeamID          uint16   `json:"team_id"`
+	TeamName        string   `json:"team_name"`
+	DesignationName string   `json:"designation_name"`
+	SupervisorID    uint16   `json:"supervisor_id"`
+	SupervisorName  string   `json:"supervisor_name"`
+	ComputedScore   *float64 `json:"computed_score"`
+	FinalScore      *float64 `json:"final_score"`
+}
+
+func (p *Pip) Validate() error {
+	return validateJSONNames(p)
+}
+
+func (p *PipObjective) Validate() error {
+	return validateJSONNames(p)
+}
+
+func (p *PipCheckIn) Validate() error {
+	return validateJSONNames(p)
+}
+
+func (p *PipOutcome) Validate() error {
+	return validateJSONNames(p)
+}
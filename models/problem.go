@@ -0,0 +1,22 @@
+package models
+
+// Problem is the RFC 7807 problem+json body of every error response. Code is a
+// machine-readable code of the kind of failure, and Errors lists the fields
+// that failed validation.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a field of the request that failed validation, with the
+// validation tag it failed
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+type QuestionnaireAnswer struct {
+	CommonModel
+	AppraisalKpiID uint16 `gorm:"not null;default:0" json:"appraisal_kpi_id" validate:"required"`
+	StatementID    uint16 `gorm:"not null;default:0" json:"statement_id" validate:"required"`
+	Answer         string `gorm:"not null;default:''" json:"answer" validate:"required"`
+	IsCorrect      bool   `gorm:"not null;default:false" json:"-"`
+}
+
+// Model for the questionnaire review endpoint
+
+type QuestionnaireReview struct {
+	AppraisalKpiID uint16                    `json:"appraisal_kpi_id"`
+	KpiID          uint16                    `json:"kpi_id"`
+	KpiName        string                    `json:"kpi_name"`
+	Score          *uint16                   `json:"score"`
+	MaxScore       uint16                    `json:"max_score"`
+	Statements     []QuestionnaireReviewItem `json:"statements"`
+}
+
+type QuestionnaireReviewItem struct {
+	StatementID   uint16         `json:"statement_id"`
+	Statement     string         `json:"statement"`
+	Options       pq.StringArray `json:"options"`
+	Weightage     uint8          `json:"weightage"`
+	CorrectAnswer string         `json:"correct_answer,omitempty"`
+	Answer        string         `json:"answer"`
+	IsCorrect     bool           `json:"is_correct"`
+}
+
+// HideAnswerKeys leaves the answer keys out of the questionnaire statements of
+// the appraisal kpis, before they are sent to the employee answering them
+func HideAnswerKeys(appraisalKpis []AppraisalKpi) {
+	for i := range appraisalKpis {
+		for k := range appraisalKpis[i].Kpi.Statements {
+			appraisalKpis[i].Kpi.Statements[k].CorrectAnswer = ""
+		}
+	}
+}
+
+func (q *QuestionnaireAnswer) Validate() error {
+	validate := validator.New()
+	return validate.Struct(q)
+}
@@ -0,0 +1,60 @@
+package models
+
+import "math"
+
+type RatingScale struct {
+	CommonModel
+	ScaleName   string        `gorm:"size:100;not null;unique;default:''" json:"scale_name" validate:"required,min=3,max=30"`
+	Description string        `gorm:"not null;default:''" json:"description"`
+	Levels      []RatingLevel `gorm:"foreignKey:RatingScaleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"levels" validate:"required,min=2,dive"`
+}
+
+type RatingLevel struct {
+	CommonModel
+	RatingScaleID uint16 `gorm:"not null;default:0" json:"-"`
+	Value         uint16 `gorm:"not null;default:0" json:"value" validate:"gte=0"`
+	Label         string `gorm:"not null;default:''" json:"label" validate:"required,max=50"`
+	Descriptor    string `gorm:"not null;default:''" json:"descriptor,omitempty"`
+}
+
+// MaxValue returns the highest value of the scale
+func (r *RatingScale) MaxValue() uint16 {
+	var max uint16
+	for _, level := range r.Levels {
+		if level.Value > max {
+			max = level.Value
+		}
+	}
+	return max
+}
+
+// Level returns the level of the scale with the given value
+func (r *RatingScale) Level(value uint16) (RatingLevel, bool) {
+	for _, level := range r.Levels {
+		if level.Value == value {
+			return level, true
+		}
+	}
+	return RatingLevel{}, false
+}
+
+// LevelForPercentage returns the level of the scale closest to the percentage
+// of its highest value, to label computed scores which are percentages
+func (r *RatingScale) LevelForPercentage(percentage float64) (RatingLevel, bool) {
+	maxValue := float64(r.MaxValue())
+	if len(r.Levels) == 0 || maxValue == 0 {
+		return RatingLevel{}, false
+	}
+
+	closest := r.Levels[0]
+	for _, level := range r.Levels[1:] {
+		if math.Abs(float64(level.Value)*100/maxValue-percentage) < math.Abs(float64(closest.Value)*100/maxValue-percentage) {
+			closest = level
+		}
+	}
+	return closest, true
+}
+
+func (r *RatingScale) Validate() error {
+	return validateJSONNames(r)
+}
@@ -0,0 +1,66 @@
+package models
+
+import "testing"
+
+func fivePointScale() *RatingScale {
+	return &RatingScale{
+		ScaleName: "Five point",
+		Levels: []RatingLevel{
+			{Value: 1, Label: "Needs improvement"},
+			{Value: 2, Label: "Developing"},
+			{Value: 3, Label: "Meets expectations"},
+			{Value: 4, Label: "Exceeds expectations"},
+			{Value: 5, Label: "Outstanding"},
+		},
+	}
+}
+
+func TestRatingScaleLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		scale     *RatingScale
+		value     uint16
+		wantLabel string
+		wantOk    bool
+	}{
+		{name: "lowest level", scale: fivePointScale(), value: 1, wantLabel: "Needs improvement", wantOk: true},
+		{name: "highest level", scale: fivePointScale(), value: 5, wantLabel: "Outstanding", wantOk: true},
+		{name: "value out of the scale", scale: fivePointScale(), value: 6},
+		{name: "level of value 0", scale: &RatingScale{Levels: []RatingLevel{{Value: 0, Label: "None"}, {Value: 1, Label: "Some"}}}, value: 0, wantLabel: "None", wantOk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, ok := tt.scale.Level(tt.value)
+			if ok != tt.wantOk || level.Label != tt.wantLabel {
+				t.Errorf("got %q, %v, want %q, %v", level.Label, ok, tt.wantLabel, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestRatingScaleLevelForPercentage(t *testing.T) {
+	tests := []struct {
+		name       string
+		scale      *RatingScale
+		percentage float64
+		wantLabel  string
+		wantOk     bool
+	}{
+		{name: "exact level", scale: fivePointScale(), percentage: 60, wantLabel: "Meets expectations", wantOk: true},
+		{name: "closest level below", scale: fivePointScale(), percentage: 68, wantLabel: "Meets expectations", wantOk: true},
+		{name: "closest level above", scale: fivePointScale(), percentage: 72, wantLabel: "Exceeds expectations", wantOk: true},
+		{name: "full score", scale: fivePointScale(), percentage: 100, wantLabel: "Outstanding", wantOk: true},
+		{name: "below the lowest level", scale: fivePointScale(), percentage: 0, wantLabel: "Needs improvement", wantOk: true},
+		{name: "scale without levels", scale: &RatingScale{}, percentage: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, ok := tt.scale.LevelForPercentage(tt.percentage)
+			if ok != tt.wantOk || level.Label != tt.wantLabel {
+				t.Errorf("got %q, %v, want %q, %v", level.Label, ok, tt.wantLabel, tt.wantOk)
+			}
+		})
+	}
+}
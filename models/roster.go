@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RosterReconciliation proposes the changes bringing the employees of an open
+// team appraisal in line with the team roster in TOSS. The changes are applied
+// once approved by HR.
+type RosterReconciliation struct {
+	CommonModel
+	AppraisalID   uint16         `gorm:"not null;default:0;index" json:"appraisal_id"`
+	AppraisalName string         `gorm:"not null;default:''" json:"appraisal_name"`
+	TeamID        uint16         `gorm:"not null;default:0" json:"team_id"`
+	TeamName      string         `gorm:"not null;default:''" json:"team_name"`
+	Status        string         `gorm:"not null;default:''" json:"status"`
+	ProposedAt    time.Time      `gorm:"not null" json:"proposed_at"`
+	ReviewedBy    uint16         `gorm:"not null;default:0" json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time     `json:"reviewed_at,omitempty"`
+	Notes         string         `gorm:"not null;default:''" json:"notes,omitempty"`
+	Changes       []RosterChange `gorm:"foreignKey:ReconciliationID;constraint:OnDelete:CASCADE" json:"changes"`
+}
+
+// RosterChange enrolls a new joiner of the team with the kpis resolved for them,
+// or withdraws a leaver along with their kpis
+type RosterChange struct {
+	CommonModel
+	ReconciliationID uint16        `gorm:"not null;default:0;index" json:"reconciliation_id"`
+	Action           string        `gorm:"not null;default:''" json:"action"`
+	EmployeeID       uint16        `gorm:"not null;default:0" json:"employee_id"`
+	EmployeeName     string        `gorm:"not null;default:''" json:"employee_name"`
+	EmployeeImage    string        `gorm:"not null;default:''" json:"employee_image,omitempty"`
+	Designation      uint16        `gorm:"not null;default:0" json:"designation_id,omitempty"`
+	DesignationName  string        `gorm:"not null;default:''" json:"designation_name,omitempty"`
+	KpiIDs           pq.Int64Array `gorm:"type:integer[]" json:"kpi_ids,omitempty"`
+	ScoredKpis       int64         `gorm:"not null;default:0" json:"scored_kpis,omitempty"`
+	Applied          bool          `gorm:"not null;default:false" json:"applied"`
+}
+
+// Request body approving or rejecting a reconciliation. Only the listed changes
+// are applied on approval if any. The reconciliation is reviewed by the caller.
+
+type RosterReview struct {
+	ReviewedBy uint16        `json:"-"`
+	ChangeIDs  pq.Int64Array `json:"change_ids"`
+	Notes      string        `json:"notes" validate:"max=1000"`
+}
+
+func (r *RosterReview) Validate() error {
+	return validateJSONNames(r)
+}
@@ -1,10 +1,52 @@
 package models
 
+import "time"
+
 type Score struct {
 	CommonModel
 	AppraisalKpiID uint16       `gorm:"not null;default:0" json:"appraisal_kpi_id" validate:"required"`
 	AppraisalKpi   AppraisalKpi `json:"appraisal_kpi"`
 	EvaluatorID    uint16       `gorm:"not null;default:0" json:"evaluator_id"`
+	OnBehalfOf     uint16       `gorm:"not null;default:0" json:"on_behalf_of,omitempty"`
 	Score          *uint16      `json:"score,omitempty"`
+	ScoreLabel     string       `gorm:"-" json:"score_label,omitempty"`
+	Percentage     *float64     `json:"percentage,omitempty"`
 	TextAnswer     string       `gorm:";default:''"  json:"text_answer,omitempty"`
 }
+
+type ScoreAdjustment struct {
+	CommonModel
+	CalibrationSessionID *uint16   `json:"calibration_session_id,omitempty"`
+	AppraisalID          uint16    `gorm:"not null;default:0;index:idx_adjustment_employee" json:"appraisal_id" validate:"required"`
+	EmployeeID           uint16    `gorm:"not null;default:0;index:idx_adjustment_employee" json:"employee_id" validate:"required"`
+	PreviousScore        *float64  `json:"previous_score"`
+	AdjustedScore        *float64  `gorm:"not null" json:"adjusted_score" validate:"required,gte=0,lte=100"`
+	Justification        string    `gorm:"not null;default:''" json:"justification" validate:"required,min=10"`
+	AdjustedBy           uint16    `gorm:"not null;default:0" json:"adjusted_by"`
+	Source               string    `gorm:"not null;default:''" json:"source"`
+	AdjustedAt           time.Time `gorm:"not null" json:"adjusted_at"`
+}
+
+// Model for the computed and final scores of an employee in an appraisal
+
+type EmployeeResult struct {
+	AppraisalID        uint16     `json:"appraisal_id"`
+	AppraisalName      string     `json:"appraisal_name"`
+	AppraisalYear      uint16     `json:"appraisal_year"`
+	AppraisalType      string     `json:"appraisal_type"`
+	EmployeeID         uint16     `json:"employee_id"`
+	EmployeeName       string     `json:"employee_name"`
+	TeamID             uint16     `json:"team_id"`
+	TeamName           string     `json:"team_name"`
+	Designation        uint16     `json:"designation_id"`
+	DesignationName    string     `json:"designation_name"`
+	SupervisorID       uint16     `json:"supervisor_id"`
+	SupervisorName     string     `json:"supervisor_name"`
+	ComputedScore      *float64   `json:"computed_score"`
+	ComputedScoreLabel string     `gorm:"-" json:"computed_score_label,omitempty"`
+	FinalScore         *float64   `json:"final_score"`
+	FinalScoreLabel    string     `gorm:"-" json:"final_score_label,omitempty"`
+	Adjusted           bool       `json:"adjusted"`
+	Acknowledgement    string     `json:"acknowledgement,omitempty"`
+	AcknowledgedAt     *time.Time `json:"acknowledged_at,omitempty"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Transfer moves an employee of an in-flight appraisal to another supervisor
+// and team. With split scoring, the scores of the previous supervisor count for
+// PreviousSupervisorWeight percent of the computed score of the employee and the
+// scores of the new supervisor for the rest.
+type Transfer struct {
+	CommonModel
+	AppraisalID              uint16        `gorm:"not null;default:0;index:idx_transfer_employee" json:"appraisal_id"`
+	EmployeeID               uint16        `gorm:"not null;default:0;index:idx_transfer_employee" json:"employee_id"`
+	FromSupervisorID         uint16        `gorm:"not null;default:0" json:"from_supervisor_id"`
+	FromSupervisorName       string        `gorm:"not null;default:''" json:"from_supervisor_name"`
+	ToSupervisorID           uint16        `gorm:"not null;default:0" json:"to_supervisor_id" validate:"required"`
+	ToSupervisorName         string        `gorm:"not null;default:''" json:"to_supervisor_name"`
+	FromTeamID               uint16        `gorm:"not null;default:0" json:"from_team_id"`
+	FromTeamName             string        `gorm:"not null;default:''" json:"from_team_name"`
+	ToTeamID                 uint16        `gorm:"not null;default:0" json:"to_team_id" validate:"required"`
+	ToTeamName               string        `gorm:"not null;default:''" json:"to_team_name"`
+	FromDesignation          uint16        `gorm:"not null;default:0" json:"from_designation_id"`
+	ToDesignation            uint16        `gorm:"not null;default:0" json:"to_designation_id"`
+	ToDesignationName        string        `gorm:"not null;default:''" json:"to_designation_name"`
+	SplitScoring             bool          `gorm:"not null;default:false" json:"split_scoring"`
+	PreviousSupervisorWeight float64       `gorm:"not null;default:0" json:"previous_supervisor_weight" validate:"gte=0,lte=100"`
+	RemovedKpiIDs            pq.Int64Array `gorm:"type:integer[]" json:"removed_appraisal_kpi_ids"`
+	AddedKpiIDs              pq.Int64Array `gorm:"type:integer[]" json:"added_appraisal_kpi_ids"`
+	Reason                   string        `gorm:"not null;default:''" json:"reason" validate:"required,min=10,max=1000"`
+	TransferredBy            uint16        `gorm:"not null;default:0" json:"transferred_by"`
+	EffectiveAt              time.Time     `gorm:"not null" json:"effective_at"`
+}
+
+func (t *Transfer) Validate() error {
+	return validateJSONNames(t)
+}
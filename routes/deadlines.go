@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+)
+
+// routeDeadlines are the deadlines of the routes needing more time than the
+// default one, keyed by the method and the path of the route
+var routeDeadlines = map[string]time.Duration{
+	"POST /v1/roster_reconciliations":                 2 * time.Minute,
+	"GET /v1/appraisals/:id/employees/:emp_id/report": time.Minute,
+	"GET /v1/me/results/:id/report":                   time.Minute,
+	"POST /v1/attachments":                            2 * time.Minute,
+	"GET /v1/attachments/:id/download":                5 * time.Minute,
+}
+
+// requestTimeout is constants.REQUEST_TIMEOUT unless overridden by
+// REQUEST_TIMEOUT_SECONDS
+func requestTimeout() time.Duration {
+	value := os.Getenv("REQUEST_TIMEOUT_SECONDS")
+	if value == "" {
+		return constants.REQUEST_TIMEOUT
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		log.Error("invalid REQUEST_TIMEOUT_SECONDS, using the default")
+		return constants.REQUEST_TIMEOUT
+	}
+
+	return time.Duration(seconds) * time.Second
+}
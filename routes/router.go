@@ -7,9 +7,37 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/gin-contrib/cors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/docs"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/middlewares"
 	"github.com/mrehanabbasi/appraisal-system-backend/service"
 )
 
+// services are the services whose handlers the routes are registered with
+type services struct {
+	employees      *service.EmployeeService
+	roles          *service.RoleService
+	supervisors    *service.SupervisorService
+	kpis           *service.KPIService
+	appraisalFlows *service.AppraisalFlowService
+	appraisals     *service.AppraisalService
+	ratingScales   *service.RatingScaleService
+	calibrations   *service.CalibrationService
+	analytics      *service.AnalyticsService
+	me             *service.MeService
+	delegations    *service.DelegationService
+	appeals        *service.AppealService
+	comments       *service.CommentService
+	attachments    *service.AttachmentService
+	pips           *service.PipService
+	increments     *service.IncrementService
+	directory      *service.DirectoryService
+	rosters        *service.RosterService
+	campaigns      *service.CampaignService
+	jobs           *service.JobService
+}
+
 func NewRouter() *gin.Engine {
 	router := gin.Default()
 	router.RedirectTrailingSlash = true
@@ -36,74 +64,316 @@ func NewRouter() *gin.Engine {
 		}))
 	}
 
+	router.Use(middlewares.Problems())
+	router.Use(middlewares.Deadline(routeDeadlines, requestTimeout()))
+
 	// Authorization middlewares
 	// router.Use(middlewares.VerifyToken(), middlewares.ValidateJWTClaims)
 
-	ec := service.NewEmployeeService()
-	roleController := service.NewRoleService()
-	sc := service.NewSupervisorService()
-	kc := service.NewKPIService()
-	af := service.NewAppraisalFlowService()
-	a := service.NewAppraisalService()
+	s := services{
+		employees:      service.NewEmployeeService(),
+		roles:          service.NewRoleService(),
+		supervisors:    service.NewSupervisorService(),
+		kpis:           service.NewKPIService(),
+		appraisalFlows: service.NewAppraisalFlowService(),
+		appraisals:     service.NewAppraisalService(),
+		ratingScales:   service.NewRatingScaleService(),
+		calibrations:   service.NewCalibrationService(),
+		analytics:      service.NewAnalyticsService(),
+		me:             service.NewMeService(),
+		delegations:    service.NewDelegationService(),
+		appeals:        service.NewAppealService(),
+		comments:       service.NewCommentService(),
+		attachments:    service.NewAttachmentService(),
+		pips:           service.NewPipService(),
+		increments:     service.NewIncrementService(),
+		directory:      service.NewDirectoryService(),
+		rosters:        service.NewRosterService(),
+		campaigns:      service.NewCampaignService(),
+		jobs:           service.NewJobService(),
+	}
+
+	s.jobs.Register(constants.JOB_TYPE_CREATE_APPRAISAL, s.appraisals.RunCreateAppraisal)
+	s.jobs.Register(constants.JOB_TYPE_CREATE_CAMPAIGN, s.campaigns.RunCreateCampaign)
+	s.jobs.Register(constants.JOB_TYPE_SYNC_DIRECTORY, s.directory.RunSyncDirectory)
+	s.jobs.Register(constants.JOB_TYPE_APPLY_ROSTER_RECONCILIATION, s.rosters.RunApplyReconciliation)
+	s.jobs.Register(constants.JOB_TYPE_GENERATE_RECOMMENDATIONS, s.increments.RunGenerateRecommendations)
 
+	s.directory.StartPeriodicSync()
+	s.jobs.Start()
+
+	registerRoutes(router, s)
+
+	// Document the routes registered above. The tests of the package fail if the
+	// routes and the annotations of their handlers drift apart.
+	spec, drift := docs.New(router.Routes())
+	for _, problem := range drift {
+		log.Warn("OpenAPI specification drift: " + problem)
+	}
+	v1 := router.Group("/v1")
+	v1.GET("/openapi.json", spec.Serve)
+	v1.GET("/docs", docs.SwaggerUI)
+
+	return router
+}
+
+// registerRoutes registers the routes of the API with the handlers of the
+// services. It does not use the services otherwise, so that the routes can be
+// listed without a database.
+func registerRoutes(router *gin.Engine, s services) {
 	v1 := router.Group("/v1")
 
 	employee := v1.Group("/employees")
 	{
-		employee.POST("", ec.CreateEmployee)
-		employee.GET("", ec.GetEmployees)
-		employee.GET("/:id", ec.GetEmployee)
-		employee.PUT("/:id", ec.UpdateEmployee)
-		employee.DELETE("/:id", ec.DeleteEmployee)
+		employee.POST("", s.employees.CreateEmployee)
+		employee.GET("", s.employees.GetEmployees)
+		employee.GET("/:id", s.employees.GetEmployee)
+		employee.PUT("/:id", s.employees.UpdateEmployee)
+		employee.DELETE("/:id", s.employees.DeleteEmployee)
 	}
 
 	roles := v1.Group("/roles")
 	{
-		roles.GET("", roleController.GetAllRoles)
-		roles.GET(":id", roleController.GetRoleByID)
-		roles.POST("", roleController.CreateRole)
-		roles.PUT(":id", roleController.UpdateRole)
-		roles.DELETE(":id", roleController.DeleteRole)
+		roles.GET("", s.roles.GetAllRoles)
+		roles.GET(":id", s.roles.GetRoleByID)
+		roles.POST("", s.roles.CreateRole)
+		roles.PUT(":id", s.roles.UpdateRole)
+		roles.DELETE(":id", s.roles.DeleteRole)
 	}
 
 	supervisors := v1.Group("/supervisors")
 	{
-		supervisors.POST("", sc.ConvertSupervisorToEmployee)
-		supervisors.GET("", sc.GetSupervisors)
-		supervisors.GET("/:id", sc.GetSupervisorById)
-		supervisors.PUT("/:id", sc.UpdateSupervisor)
-		supervisors.DELETE("/:id", sc.DeleteSupervisor)
+		supervisors.POST("", s.supervisors.ConvertSupervisorToEmployee)
+		supervisors.GET("", s.supervisors.GetSupervisors)
+		supervisors.GET("/:id", s.supervisors.GetSupervisorById)
+		supervisors.PUT("/:id", s.supervisors.UpdateSupervisor)
+		supervisors.DELETE("/:id", s.supervisors.DeleteSupervisor)
 	}
 
 	kpis := v1.Group("/kpis")
 	{
-		kpis.POST("", kc.CreateKPI)
-		kpis.GET("", kc.GetAllKPIs)
-		kpis.GET("/:id", kc.GetKPIByID)
-		kpis.PUT("/:id", kc.UpdateKPI)
-		kpis.DELETE("/:id", kc.DeleteKPI)
+		kpis.POST("", s.kpis.CreateKPI)
+		kpis.GET("", s.kpis.GetAllKPIs)
+		kpis.GET("/:id", s.kpis.GetKPIByID)
+		kpis.PUT("/:id", s.kpis.UpdateKPI)
+		kpis.DELETE("/:id", s.kpis.DeleteKPI)
+	}
+
+	kpiTypes := v1.Group("/kpi_types")
+	{
+		kpiTypes.GET("", s.kpis.GetAllKpiTypes)
+		kpiTypes.PUT("/:id", s.kpis.UpdateKpiType)
+	}
+
+	ratingScales := v1.Group("/rating_scales")
+	{
+		ratingScales.POST("", s.ratingScales.CreateRatingScale)
+		ratingScales.GET("", s.ratingScales.GetAllRatingScales)
+		ratingScales.GET("/:id", s.ratingScales.GetRatingScaleByID)
+		ratingScales.PUT("/:id", s.ratingScales.UpdateRatingScale)
+		ratingScales.DELETE("/:id", s.ratingScales.DeleteRatingScale)
 	}
 
 	appraisalFlows := v1.Group("/appraisal_flows")
 	{
-		appraisalFlows.POST("", af.CreateAppraisalFlow)
-		appraisalFlows.GET("", af.GetAllAppraisalFlows)
-		appraisalFlows.GET("/:id", af.GetAppraisalFlowByID)
-		appraisalFlows.PUT("/:id", af.UpdateAppraisalFlow)
-		appraisalFlows.DELETE("/:id", af.DeleteAppraisalFlow)
+		appraisalFlows.POST("", s.appraisalFlows.CreateAppraisalFlow)
+		appraisalFlows.GET("", s.appraisalFlows.GetAllAppraisalFlows)
+		appraisalFlows.GET("/:id", s.appraisalFlows.GetAppraisalFlowByID)
+		appraisalFlows.PUT("/:id", s.appraisalFlows.UpdateAppraisalFlow)
+		appraisalFlows.DELETE("/:id", s.appraisalFlows.DeleteAppraisalFlow)
 	}
 
 	appraisals := v1.Group("/appraisals")
 	{
-		appraisals.POST("", a.CreateAppraisal)
-		appraisals.POST("/:id/employees/:emp_id/score", a.AddScore)
-		appraisals.GET("", a.GetAllAppraisals)
-		appraisals.GET("/:id", a.GetAppraisalByID)
-		appraisals.PUT("/:id", a.UpdateAppraisal)
-		appraisals.DELETE("/:id", a.DeleteAppraisal)
-		appraisals.GET("/employees/:emp_id/appraisal_kpis", a.GetAppraisalKpisByEmpID)
-		appraisals.GET("/:id/employee_data", a.GetEmployeeDataByAppraisalID)
-		appraisals.GET("/getallprojects", a.GetAllProjects)
+		appraisals.POST("", s.appraisals.CreateAppraisal)
+		appraisals.POST("/:id/employees/:emp_id/score", s.appraisals.AddScore)
+		appraisals.GET("/:id/employees/:emp_id/scores", s.appraisals.GetScores)
+		appraisals.GET("/:id/employees/:emp_id/questionnaire", s.appraisals.GetQuestionnaire)
+		// Answers are submitted by the employee and reviewed by their evaluators
+		appraisals.POST("/:id/employees/:emp_id/questionnaire", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.appraisals.SubmitQuestionnaire)
+		appraisals.GET("/:id/employees/:emp_id/questionnaire/review", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.appraisals.GetQuestionnaireReview)
+		appraisals.GET("/:id/employees/:emp_id/history", s.appraisals.GetHistory)
+		// Transfers are hr actions taken as the caller
+		appraisals.POST("/:id/employees/:emp_id/transfer", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.appraisals.TransferEmployee)
+		appraisals.GET("/:id/employees/:emp_id/transfers", s.appraisals.GetTransfers)
+		appraisals.GET("/:id/employees/:emp_id/acknowledgement", s.appraisals.GetAcknowledgement)
+		// Reports can carry the comments private to HR
+		appraisals.GET("/:id/employees/:emp_id/report", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.appraisals.ExportEmployeeReport)
+		appraisals.GET("", s.appraisals.GetAllAppraisals)
+		appraisals.GET("/:id", s.appraisals.GetAppraisalByID)
+		appraisals.PUT("/:id", s.appraisals.UpdateAppraisal)
+		appraisals.DELETE("/:id", s.appraisals.DeleteAppraisal)
+		appraisals.GET("/employees/:emp_id/appraisal_kpis", s.appraisals.GetAppraisalKpisByEmpID)
+		appraisals.GET("/:id/employee_data", s.appraisals.GetEmployeeDataByAppraisalID)
+		appraisals.GET("/getallprojects", s.appraisals.GetAllProjects)
+		appraisals.POST("/:id/publish", s.appraisals.PublishResults)
+	}
+
+	campaigns := v1.Group("/campaigns")
+	{
+		// Campaigns are created by hr as the caller
+		campaigns.POST("", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.campaigns.CreateCampaign)
+		campaigns.GET("", s.campaigns.GetAllCampaigns)
+		campaigns.GET("/:id", s.campaigns.GetCampaignByID)
+	}
+
+	// Sessions are run by hr, who is identified by the jwt
+	calibrationSessions := v1.Group("/calibration_sessions")
+	{
+		calibrationSessions.POST("", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.calibrations.CreateCalibrationSession)
+		calibrationSessions.GET("", s.calibrations.GetAllCalibrationSessions)
+		calibrationSessions.GET("/:id", s.calibrations.GetCalibrationSessionByID)
+		calibrationSessions.PUT("/:id", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.calibrations.UpdateCalibrationSession)
+		calibrationSessions.DELETE("/:id", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.calibrations.DeleteCalibrationSession)
+		calibrationSessions.GET("/:id/employees", s.calibrations.GetCalibrationEmployees)
+		calibrationSessions.GET("/:id/distribution", s.calibrations.GetCalibrationDistribution)
+		calibrationSessions.POST("/:id/adjustments", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.calibrations.AdjustScore)
+	}
+
+	distributionCurves := v1.Group("/distribution_curves")
+	{
+		distributionCurves.POST("", s.analytics.CreateDistributionCurve)
+		distributionCurves.GET("", s.analytics.GetAllDistributionCurves)
+		distributionCurves.GET("/:id", s.analytics.GetDistributionCurveByID)
+		distributionCurves.PUT("/:id", s.analytics.UpdateDistributionCurve)
+		distributionCurves.DELETE("/:id", s.analytics.DeleteDistributionCurve)
+	}
+
+	analytics := v1.Group("/analytics")
+	{
+		analytics.GET("/distribution", s.analytics.GetDistribution)
+		analytics.GET("/employees/:emp_id/history", s.analytics.GetPerformanceHistory)
+	}
+
+	dashboard := v1.Group("/dashboard")
+	{
+		dashboard.GET("", s.analytics.GetDashboard)
+		dashboard.GET("/teams", s.analytics.GetTeamCompletion)
+		dashboard.GET("/supervisors", s.analytics.GetSupervisorCompletion)
+	}
+
+	delegations := v1.Group("/delegations")
+	{
+		delegations.GET("", s.delegations.GetAllDelegations)
+		delegations.GET("/:id", s.delegations.GetDelegationByID)
+	}
+
+	// Appeals are only shown to the employee who filed them, their reviewer and
+	// hr, who are identified by the jwt
+	appeals := v1.Group("/appeals")
+	appeals.Use(middlewares.VerifyToken(), middlewares.ValidateJWTClaims)
+	{
+		appeals.GET("", s.appeals.GetAllAppeals)
+		appeals.GET("/:id", s.appeals.GetAppealByID)
+		appeals.POST("/:id/review", s.appeals.ReviewAppeal)
+		appeals.POST("/:id/resolve", s.appeals.ResolveAppeal)
+		appeals.POST("/:id/comments", s.appeals.AddAppealComment)
+	}
+
+	// The author and the visibility of comments are taken from the jwt
+	comments := v1.Group("/comments")
+	comments.Use(middlewares.VerifyToken(), middlewares.ValidateJWTClaims)
+	{
+		comments.POST("", s.comments.CreateComment)
+		comments.GET("", s.comments.GetAllComments)
+		comments.GET("/:id", s.comments.GetCommentByID)
+		comments.PUT("/:id", s.comments.EditComment)
+		comments.DELETE("/:id", s.comments.DeleteComment)
+	}
+
+	// Plans are changed by their owner or hr, who are identified by the jwt
+	pips := v1.Group("/pips")
+	{
+		pips.POST("", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.pips.CreatePip)
+		pips.GET("", s.pips.GetAllPips)
+		pips.GET("/candidates", s.pips.GetPipCandidates)
+		pips.GET("/:id", s.pips.GetPipByID)
+		pips.POST("/:id/objectives", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.pips.AddPipObjective)
+		pips.PUT("/:id/objectives/:objective_id", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.pips.UpdatePipObjective)
+		pips.POST("/:id/check_ins", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.pips.ScheduleCheckIn)
+		pips.PUT("/:id/check_ins/:check_in_id", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.pips.RecordCheckIn)
+		pips.POST("/:id/outcome", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.pips.DecidePipOutcome)
+	}
+
+	incrementPolicies := v1.Group("/increment_policies")
+	{
+		incrementPolicies.POST("", s.increments.CreateIncrementPolicy)
+		incrementPolicies.GET("", s.increments.GetAllIncrementPolicies)
+		incrementPolicies.GET("/:id", s.increments.GetIncrementPolicyByID)
+		incrementPolicies.PUT("/:id", s.increments.UpdateIncrementPolicy)
+		incrementPolicies.DELETE("/:id", s.increments.DeleteIncrementPolicy)
+	}
+
+	recommendations := v1.Group("/recommendations")
+	{
+		recommendations.POST("/generate", s.increments.GenerateRecommendations)
+		recommendations.GET("", s.increments.GetRecommendations)
+		// Approvers and supervisors overriding are taken from the jwt
+		recommendations.POST("/approve", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.increments.ApproveRecommendations)
+		recommendations.GET("/:id", s.increments.GetRecommendationByID)
+		recommendations.PUT("/:id/override", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.increments.OverrideRecommendation)
+	}
+
+	directory := v1.Group("/directory")
+	{
+		directory.POST("/sync", s.directory.SyncDirectory)
+		directory.GET("/sync_runs", s.directory.GetSyncRuns)
+		directory.GET("/changes", s.directory.GetChanges)
+		directory.GET("/changes/cycle", s.directory.GetCycleChanges)
+		directory.GET("/employees", s.directory.GetEmployees)
+		directory.GET("/projects", s.directory.GetProjects)
+	}
+
+	rosterReconciliations := v1.Group("/roster_reconciliations")
+	{
+		rosterReconciliations.POST("", s.rosters.ProposeReconciliations)
+		rosterReconciliations.GET("", s.rosters.GetReconciliations)
+		rosterReconciliations.GET("/:id", s.rosters.GetReconciliationByID)
+		// Reviews are hr actions taken as the caller
+		rosterReconciliations.POST("/:id/approve", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.rosters.ApproveReconciliation)
+		rosterReconciliations.POST("/:id/reject", middlewares.VerifyToken(), middlewares.ValidateJWTClaims, s.rosters.RejectReconciliation)
+	}
+
+	jobs := v1.Group("/jobs")
+	{
+		jobs.GET("", s.jobs.GetAllJobs)
+		jobs.GET("/:id", s.jobs.GetJobByID)
+	}
+
+	// Downloads are authorized by the membership of the caller in the appraisal
+	attachments := v1.Group("/attachments")
+	attachments.Use(middlewares.VerifyToken(), middlewares.ValidateJWTClaims)
+	{
+		attachments.POST("", s.attachments.UploadAttachment)
+		attachments.GET("", s.attachments.GetAttachments)
+		attachments.GET("/:id", s.attachments.GetAttachmentByID)
+		attachments.GET("/:id/download", s.attachments.DownloadAttachment)
+		attachments.DELETE("/:id", s.attachments.DeleteAttachment)
+	}
+
+	// The caller is identified by the jwt, so these routes always require it
+	me := v1.Group("/me")
+	me.Use(middlewares.VerifyToken(), middlewares.ValidateJWTClaims)
+	{
+		me.GET("/appraisals", s.me.GetMyAppraisals)
+		me.GET("/appraisals/:id/kpis", s.me.GetMyAppraisalKpis)
+		me.GET("/tasks", s.me.GetMyTasks)
+		me.GET("/results", s.me.GetMyResults)
+		me.GET("/history", s.me.GetMyPerformanceHistory)
+		me.GET("/results/:id/acknowledgement", s.me.GetMyAcknowledgement)
+		me.POST("/results/:id/acknowledgement", s.me.AcknowledgeMyResults)
+		me.GET("/results/:id/report", s.me.ExportMyReport)
+		me.GET("/inbox", s.me.GetMyInbox)
+		me.POST("/appeals", s.appeals.FileMyAppeal)
+		me.GET("/appeals", s.appeals.GetMyAppeals)
+		me.GET("/appeals/:id", s.appeals.GetMyAppeal)
+		me.POST("/appeals/:id/comments", s.appeals.AddMyAppealComment)
+		me.GET("/appraisals/:id/comments", s.comments.GetMyComments)
+		me.POST("/comments", s.comments.CreateMyComment)
+		me.PUT("/comments/:id", s.comments.EditMyComment)
+		me.GET("/mentions", s.comments.GetMyMentions)
+		me.GET("/pips", s.pips.GetMyPips)
+		me.POST("/delegations", s.delegations.CreateDelegation)
+		me.PUT("/delegations/:id/revoke", s.delegations.RevokeDelegation)
 	}
-	return router
 }
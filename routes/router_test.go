@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/docs"
+)
+
+// The handlers of the routes are only named, so the services need no database
+func TestRoutesAreDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerRoutes(router, services{})
+
+	routes := router.Routes()
+	if len(routes) == 0 {
+		t.Fatal("no routes are registered")
+	}
+
+	_, drift := docs.New(routes)
+	for _, problem := range drift {
+		t.Error(problem)
+	}
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	"github.com/mrehanabbasi/appraisal-system-backend/domain"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type AnalyticsService struct {
+	Db *gorm.DB
+}
+
+func NewAnalyticsService() *AnalyticsService {
+	db := database.DB
+	err := db.AutoMigrate(&models.DistributionCurve{}, &models.DistributionBand{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &AnalyticsService{Db: db}
+}
+
+// @summary Create a distribution curve
+// @body models.DistributionCurve
+// @success 201 models.DistributionCurve
+func (s *AnalyticsService) CreateDistributionCurve(c *gin.Context) {
+	log.Info("Initializing CreateDistributionCurve handler function...")
+
+	ctx := c.Request.Context()
+
+	var curve models.DistributionCurve
+	if err := c.ShouldBindJSON(&curve); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := s.validateDistributionCurve(c, &curve); !ok {
+		return
+	}
+	curve.ID = 0
+
+	dbCurve, err := controller.CreateDistributionCurve(s.Db.WithContext(ctx), &curve)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbCurve)
+}
+
+// @summary List the distribution curves
+// @query assign_type integer Assign type the curves target
+// @success 200 []models.DistributionCurve
+func (s *AnalyticsService) GetAllDistributionCurves(c *gin.Context) {
+	log.Info("Initializing GetAllDistributionCurves handler function...")
+
+	ctx := c.Request.Context()
+
+	var curves []models.DistributionCurve
+	db := s.Db.WithContext(ctx).Model(&models.DistributionCurve{})
+
+	assignType := c.Query("assign_type")
+	if assignType != "" {
+		db = db.Where("assign_type_id = ?", assignType)
+	}
+
+	if err := controller.GetAllDistributionCurves(db, &curves); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, curves)
+}
+
+// @summary Get a distribution curve
+// @success 200 models.DistributionCurve
+func (s *AnalyticsService) GetDistributionCurveByID(c *gin.Context) {
+	log.Info("Initializing GetDistributionCurveByID handler function...")
+
+	ctx := c.Request.Context()
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var curve models.DistributionCurve
+	err := controller.GetDistributionCurveByID(s.Db.WithContext(ctx), &curve, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against distribution curve id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, curve)
+}
+
+// @summary Update a distribution curve
+// @body models.DistributionCurve
+// @success 200 models.DistributionCurve
+func (s *AnalyticsService) UpdateDistributionCurve(c *gin.Context) {
+	log.Info("Initializing UpdateDistributionCurve handler function...")
+
+	ctx := c.Request.Context()
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var curve models.DistributionCurve
+	if err := c.ShouldBindJSON(&curve); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := s.validateDistributionCurve(c, &curve); !ok {
+		return
+	}
+	curve.ID = uint16(id)
+
+	dbCurve, err := controller.UpdateDistributionCurve(s.Db.WithContext(ctx), &curve)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbCurve)
+}
+
+// @summary Delete a distribution curve
+// @success 204
+func (s *AnalyticsService) DeleteDistributionCurve(c *gin.Context) {
+	log.Info("Initializing DeleteDistributionCurve handler function...")
+
+	ctx := c.Request.Context()
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var curve models.DistributionCurve
+	err := controller.GetDistributionCurveByID(s.Db.WithContext(ctx), &curve, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against distribution curve id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	if err := controller.DeleteDistributionCurve(s.Db.WithContext(ctx), &curve); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetDistribution compares the distribution of final scores of an appraisal
+// cycle with the target curves, per team or per designation
+//
+// @summary Compare the score distribution of an appraisal cycle with the target curves
+// @query appraisal_year integer Year of the appraisal cycle
+// @query appraisal_type string Type of the appraisal cycle
+// @query group_by string Either team, the default, or designation
+// @query flagged boolean Only the groups off their target curve
+// @success 200 models.DistributionReport
+func (s *AnalyticsService) GetDistribution(c *gin.Context) {
+	log.Info("Initializing GetDistribution handler function...")
+
+	ctx := c.Request.Context()
+
+	appraisalYear, appraisalType, ok := parseAppraisalCycle(c, s.Db.WithContext(ctx))
+	if !ok {
+		return
+	}
+
+	groupBy := c.DefaultQuery("group_by", constants.GROUP_BY_TEAM)
+	if groupBy != constants.GROUP_BY_TEAM && groupBy != constants.GROUP_BY_DESIGNATION {
+		respondError(c, apperrors.Invalid("group_by should be either team or designation"))
+		return
+	}
+
+	report, err := controller.GetDistributionReport(s.Db.WithContext(ctx), appraisalYear, appraisalType, groupBy)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	if flagged := c.Query("flagged"); flagged == "true" {
+		groups := make([]models.GroupDistribution, 0)
+		for _, group := range report.Groups {
+			if group.Flagged {
+				groups = append(groups, group)
+			}
+		}
+		report.Groups = groups
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// validateDistributionCurve validates the request curve and writes the error
+// response itself. A curve without an assign type applies to the whole
+// organization; otherwise it targets a team or a designation. Bands must not
+// overlap and their target percentages must add up to 100.
+func (s *AnalyticsService) validateDistributionCurve(c *gin.Context, curve *models.DistributionCurve) bool {
+	ctx := c.Request.Context()
+	if ok := validateStruct(c, curve.Validate()); !ok {
+		return false
+	}
+
+	curve.AssignTypeName = ""
+	curve.SelectedAssignName = ""
+	if curve.AssignTypeID == 0 {
+		curve.SelectedAssignID = 0
+	} else {
+		assignType, name, err := domain.CheckAssignType(s.Db.WithContext(ctx), curve.AssignTypeID)
+		if err != nil || (name != constants.ASSIGN_TYPE_TEAM && name != constants.ASSIGN_TYPE_ROLE) {
+			respondError(c, apperrors.Invalid("invalid assign type"))
+			return false
+		}
+		curve.AssignTypeName = name
+
+		errCode, name, err := utils.VerifyIdAgainstTossApis(ctx, curve.SelectedAssignID, string(assignType.AssignType))
+		if err != nil {
+			respondError(c, apperrors.FromStatus(errCode, err))
+			return false
+		}
+		curve.SelectedAssignName = name
+	}
+
+	sort.Slice(curve.Bands, func(i, j int) bool {
+		return curve.Bands[i].MinScore < curve.Bands[j].MinScore
+	})
+
+	var totalTarget float64
+	for k, band := range curve.Bands {
+		if band.MinScore >= band.MaxScore {
+			errMsg := fmt.Sprintf("min_score of band '%s' should be less than its max_score", band.BandName)
+			respondError(c, apperrors.Invalid(errMsg))
+			return false
+		}
+		if k > 0 && band.MinScore < curve.Bands[k-1].MaxScore {
+			errMsg := fmt.Sprintf("band '%s' overlaps band '%s'", band.BandName, curve.Bands[k-1].BandName)
+			respondError(c, apperrors.Invalid(errMsg))
+			return false
+		}
+		totalTarget += band.TargetPercentage
+	}
+
+	if math.Abs(totalTarget-100) > 0.01 {
+		errMsg := "target percentages of the bands should add up to 100"
+		respondError(c, apperrors.Invalid(errMsg))
+		return false
+	}
+
+	return true
+}
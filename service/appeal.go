@@ -0,0 +1,497 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type AppealService struct {
+	Db *gorm.DB
+}
+
+func NewAppealService() *AppealService {
+	db := database.DB
+	err := db.AutoMigrate(&models.Appeal{}, &models.AppealComment{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &AppealService{Db: db}
+}
+
+// FileMyAppeal files an appeal of the caller against the ratings of some of their
+// kpis. The appeal goes to the skip-level supervisor of the supervisor evaluating
+// the caller unless routed to HR, or if TOSS has no skip-level supervisor for them.
+//
+// @summary File an appeal against the ratings of the caller
+// @body models.Appeal
+// @success 201 models.Appeal
+// @auth
+func (s *AppealService) FileMyAppeal(c *gin.Context) {
+	log.Info("Initializing FileMyAppeal handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	var appeal models.Appeal
+	if err := c.ShouldBindJSON(&appeal); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, appeal.Validate()); !ok {
+		return
+	}
+	appeal.ID = 0
+	appeal.EmployeeID = tokenInfo.EmpID
+	appeal.ReviewerID = 0
+	appeal.ReviewerName = ""
+	appeal.Resolution = ""
+	appeal.ResolvedBy = 0
+	appeal.ResolvedAt = nil
+	appeal.AdjustmentID = nil
+	appeal.Adjustment = nil
+	appeal.Comments = nil
+
+	switch appeal.RoutedTo {
+	case "", constants.APPEAL_ROUTE_SKIP_LEVEL:
+		appeal.RoutedTo = constants.APPEAL_ROUTE_SKIP_LEVEL
+	case constants.APPEAL_ROUTE_HR:
+	default:
+		respondError(c, apperrors.Invalid("routed_to should be either skip_level or hr"))
+		return
+	}
+
+	if appeal.RoutedTo == constants.APPEAL_ROUTE_SKIP_LEVEL {
+		var appraisal models.Appraisal
+		err := controller.GetAppraisalByID(s.Db.WithContext(ctx), &appraisal, uint64(appeal.AppraisalID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondError(c, apperrors.NotFound("Record not found against appraisal id"))
+			} else {
+				respondError(c, apperrors.Internal(err))
+			}
+			return
+		}
+
+		supervisorID, err := controller.GetEffectiveSupervisor(s.Db.WithContext(ctx), &appraisal, appeal.EmployeeID)
+		if err != nil {
+			respondError(c, apperrors.Internal(err))
+			return
+		}
+
+		reviewerID, reviewerName, err := utils.GetSkipLevelSupervisor(ctx, supervisorID)
+		if err != nil || reviewerID == appeal.EmployeeID {
+			log.Info("skip-level supervisor not resolved, routing the appeal to HR")
+			appeal.RoutedTo = constants.APPEAL_ROUTE_HR
+		} else {
+			appeal.ReviewerID = reviewerID
+			appeal.ReviewerName = reviewerName
+		}
+	}
+
+	dbAppeal, err := controller.FileAppeal(s.Db.WithContext(ctx), &appeal)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No results found against the appraisal"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbAppeal)
+}
+
+// @summary List the appeals of the caller
+// @success 200 []models.Appeal
+// @auth
+func (s *AppealService) GetMyAppeals(c *gin.Context) {
+	log.Info("Initializing GetMyAppeals handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appeals := make([]models.Appeal, 0)
+	db := s.Db.WithContext(ctx).Model(&models.Appeal{}).Where("employee_id = ?", tokenInfo.EmpID)
+	if err := controller.GetAllAppeals(db, &appeals); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, appeals)
+}
+
+// @summary Get an appeal of the caller
+// @success 200 models.Appeal
+// @auth
+func (s *AppealService) GetMyAppeal(c *gin.Context) {
+	log.Info("Initializing GetMyAppeal handler function...")
+
+	appeal, ok := s.findMyAppeal(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+// @summary Comment on an appeal of the caller
+// @body models.AppealComment
+// @success 201 models.AppealComment
+// @auth
+func (s *AppealService) AddMyAppealComment(c *gin.Context) {
+	log.Info("Initializing AddMyAppealComment handler function...")
+
+	ctx := c.Request.Context()
+
+	appeal, ok := s.findMyAppeal(c)
+	if !ok {
+		return
+	}
+
+	var comment models.AppealComment
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+	comment.ID = 0
+	comment.AuthorID = appeal.EmployeeID
+
+	if ok := validateStruct(c, comment.Validate()); !ok {
+		return
+	}
+
+	dbComment, err := controller.AddAppealComment(s.Db.WithContext(ctx), &appeal, &comment)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbComment)
+}
+
+// GetAllAppeals lists the appeals. HR sees every appeal, and the others only
+// the appeals they filed or review.
+//
+// @summary List the appeals
+// @query status string Status of the appeals
+// @query routed_to string Either skip_level or hr
+// @query reviewer_id integer Reviewer of the appeals
+// @query appraisal_id integer Appraisal the appeals contest
+// @query employee_id integer Employee who filed the appeals
+// @success 200 []models.Appeal
+// @auth
+func (s *AppealService) GetAllAppeals(c *gin.Context) {
+	log.Info("Initializing GetAllAppeals handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appeals := make([]models.Appeal, 0)
+	db := s.Db.WithContext(ctx).Model(&models.Appeal{})
+
+	if !tokenInfo.IsHR() {
+		db = db.Where("employee_id = ? OR reviewer_id = ?", tokenInfo.EmpID, tokenInfo.EmpID)
+	}
+
+	status := c.Query("status")
+	routedTo := c.Query("routed_to")
+	reviewerID := c.Query("reviewer_id")
+	appraisalID := c.Query("appraisal_id")
+	employeeID := c.Query("employee_id")
+
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if routedTo != "" {
+		db = db.Where("routed_to = ?", routedTo)
+	}
+
+	if reviewerID != "" {
+		db = db.Where("reviewer_id = ?", reviewerID)
+	}
+
+	if appraisalID != "" {
+		db = db.Where("appraisal_id = ?", appraisalID)
+	}
+
+	if employeeID != "" {
+		db = db.Where("employee_id = ?", employeeID)
+	}
+
+	if err := controller.GetAllAppeals(db, &appeals); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, appeals)
+}
+
+// @summary Get an appeal
+// @success 200 models.Appeal
+// @auth
+func (s *AppealService) GetAppealByID(c *gin.Context) {
+	log.Info("Initializing GetAppealByID handler function...")
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appeal, ok := s.findAccessibleAppeal(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+// ReviewAppeal takes a filed appeal under review. The caller takes it themselves,
+// and only HR can take the appeals routed to HR or hand them to someone else.
+//
+// @summary Take an appeal under review
+// @body models.AppealReview
+// @success 200 models.Appeal
+// @auth
+func (s *AppealService) ReviewAppeal(c *gin.Context) {
+	log.Info("Initializing ReviewAppeal handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appeal, ok := s.findAppeal(c)
+	if !ok {
+		return
+	}
+
+	var review models.AppealReview
+	if err := c.ShouldBindJSON(&review); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, review.Validate()); !ok {
+		return
+	}
+
+	if !tokenInfo.IsHR() {
+		if appeal.RoutedTo == constants.APPEAL_ROUTE_HR {
+			respondError(c, apperrors.Forbidden("appeal is routed to hr"))
+			return
+		}
+		if review.ReviewerID != tokenInfo.EmpID {
+			respondError(c, apperrors.Forbidden("reviewer_id should be the caller"))
+			return
+		}
+	}
+
+	errCode, err := utils.CheckIndividualAgainstToss(ctx, review.ReviewerID)
+	if err != nil {
+		respondError(c, apperrors.FromStatus(errCode, err))
+		return
+	}
+
+	reviewerName, err := utils.GetEmployeeName(ctx, review.ReviewerID)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	dbAppeal, err := controller.ReviewAppeal(s.Db.WithContext(ctx), &appeal, review.ReviewerID, reviewerName)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbAppeal)
+}
+
+// ResolveAppeal upholds the contested ratings or adjusts the final score. Only
+// the reviewer of the appeal and HR can resolve it.
+//
+// @summary Uphold the contested ratings or adjust the final score
+// @body models.AppealResolution
+// @success 200 models.Appeal
+// @auth
+func (s *AppealService) ResolveAppeal(c *gin.Context) {
+	log.Info("Initializing ResolveAppeal handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appeal, ok := s.findAppeal(c)
+	if !ok {
+		return
+	}
+
+	var resolution models.AppealResolution
+	if err := c.ShouldBindJSON(&resolution); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, resolution.Validate()); !ok {
+		return
+	}
+
+	if resolution.ResolvedBy != tokenInfo.EmpID {
+		respondError(c, apperrors.Forbidden("resolved_by should be the caller"))
+		return
+	}
+	if appeal.ReviewerID != tokenInfo.EmpID && !tokenInfo.IsHR() {
+		respondError(c, apperrors.Forbidden("only the reviewer of the appeal or hr can resolve it"))
+		return
+	}
+
+	if resolution.Decision == constants.APPEAL_STATUS_ADJUSTED && resolution.AdjustedScore == nil {
+		respondError(c, apperrors.Invalid("adjusted_score field is required"))
+		return
+	}
+	if resolution.Decision == constants.APPEAL_STATUS_UPHELD {
+		resolution.AdjustedScore = nil
+	}
+
+	dbAppeal, err := controller.ResolveAppeal(s.Db.WithContext(ctx), &appeal, &resolution)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbAppeal)
+}
+
+// AddAppealComment adds a comment of the caller to the trail of the appeal
+//
+// @summary Comment on an appeal
+// @body models.AppealComment
+// @success 201 models.AppealComment
+// @auth
+func (s *AppealService) AddAppealComment(c *gin.Context) {
+	log.Info("Initializing AddAppealComment handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appeal, ok := s.findAccessibleAppeal(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	var comment models.AppealComment
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+	comment.ID = 0
+	comment.AuthorID = tokenInfo.EmpID
+
+	if ok := validateStruct(c, comment.Validate()); !ok {
+		return
+	}
+
+	dbComment, err := controller.AddAppealComment(s.Db.WithContext(ctx), &appeal, &comment)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbComment)
+}
+
+// findAppeal loads the appeal of the id path param and writes the error response
+// itself
+func (s *AppealService) findAppeal(c *gin.Context) (models.Appeal, bool) {
+	ctx := c.Request.Context()
+	var appeal models.Appeal
+
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return appeal, false
+	}
+
+	err = controller.GetAppealByID(s.Db.WithContext(ctx), &appeal, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against appeal id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return appeal, false
+	}
+
+	return appeal, true
+}
+
+// findAccessibleAppeal is findAppeal restricted to the appeals the caller filed or
+// reviews, unless the caller is HR
+func (s *AppealService) findAccessibleAppeal(c *gin.Context, tokenInfo models.TokenInfo) (models.Appeal, bool) {
+	appeal, ok := s.findAppeal(c)
+	if !ok {
+		return appeal, false
+	}
+
+	if appeal.EmployeeID != tokenInfo.EmpID && appeal.ReviewerID != tokenInfo.EmpID && !tokenInfo.IsHR() {
+		respondError(c, apperrors.NotFound("Record not found against appeal id"))
+		return appeal, false
+	}
+
+	return appeal, true
+}
+
+// findMyAppeal is findAppeal restricted to the appeals of the caller
+func (s *AppealService) findMyAppeal(c *gin.Context) (models.Appeal, bool) {
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return models.Appeal{}, false
+	}
+
+	appeal, ok := s.findAppeal(c)
+	if !ok {
+		return appeal, false
+	}
+
+	if appeal.EmployeeID != tokenInfo.EmpID {
+		respondError(c, apperrors.NotFound("Record not found against appeal id"))
+		return appeal, false
+	}
+
+	return appeal, true
+}
@@ -1,56 +1,67 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	"github.com/mrehanabbasi/appraisal-system-backend/constants"
 	"github.com/mrehanabbasi/appraisal-system-backend/controller"
 	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	"github.com/mrehanabbasi/appraisal-system-backend/domain"
 	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
 	"github.com/mrehanabbasi/appraisal-system-backend/models"
 	"github.com/mrehanabbasi/appraisal-system-backend/utils"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 type AppraisalService struct {
-	Db *gorm.DB
+	Db         *gorm.DB
+	appraisals *domain.AppraisalService
+	scoring    *domain.ScoringService
 }
 
 func NewAppraisalService() *AppraisalService {
 	db := database.DB
-	err := db.AutoMigrate(&models.Appraisal{}, models.EmployeeData{}, models.AppraisalKpi{}, models.Score{})
+	err := db.AutoMigrate(&models.Appraisal{}, models.EmployeeData{}, models.AppraisalKpi{}, models.Score{}, models.QuestionnaireAnswer{}, models.AppraisalHistory{}, models.Acknowledgement{}, models.Transfer{})
 	if err != nil {
 		panic(err)
 	}
 
-	return &AppraisalService{Db: db}
+	return &AppraisalService{
+		Db:         db,
+		appraisals: domain.NewAppraisalService(db),
+		scoring:    domain.NewScoringService(db),
+	}
 }
 
+// @summary List the active TOSS projects with their members and supervisors
+// @success 200 object
 func (r *AppraisalService) GetAllProjects(c *gin.Context) {
 	log.Info("Initializing GetAllProjects handler function...")
+
+	ctx := c.Request.Context()
+
 	tossBaseURL := os.Getenv("TOSS_BASE_URL") // Get the TOSS base URL from the environment variable
 	apiURL := tossBaseURL + "/api/Project/AllProjectsWithEmployeesList?IsActive=true"
-	method := http.MethodGet                            // HTTP method for sending the request
-	resp, err := utils.SendRequest(method, apiURL, nil) // Send the HTTP request to the specified URL
+	method := http.MethodGet                                 // HTTP method for sending the request
+	resp, err := utils.SendRequest(ctx, method, apiURL, nil) // Send the HTTP request to the specified URL
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
@@ -58,8 +69,7 @@ func (r *AppraisalService) GetAllProjects(c *gin.Context) {
 
 	var apiResponse []models.Employees
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
@@ -131,547 +141,119 @@ func (r *AppraisalService) GetAllProjects(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// @summary Queue the creation of an appraisal
+// @body models.Appraisal
+// @success 202 models.Job
 func (r *AppraisalService) CreateAppraisal(c *gin.Context) {
 	log.Info("Initializing CreateAppraisal handler function...")
 
+	ctx := c.Request.Context()
+
 	var appraisal models.Appraisal
 	err := c.ShouldBindJSON(&appraisal)
 	if err != nil {
-		errs, ok := controller.ErrValidationSlice(err)
-		if !ok {
-			log.Error(err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		log.Error(err.Error())
-		if len(errs) > 1 {
-			c.JSON(http.StatusBadRequest, gin.H{"errors": errs})
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": errs[0]})
-		}
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 
-	// Validate each FlowStep struct
-	for _, ak := range appraisal.AppraisalKpis {
-		//check employee id exist in toss api
-
-		if ak.EmployeeID == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "employee_id field is required"})
-			return
-		}
-
-		if ak.KpiID == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "kpi_id field is required "})
-			return
-		}
-
-		if ak.Status == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "status field is required "})
-			return
-		}
-		//check employees id in toss api
-		errCode, err := utils.CheckIndividualAgainstToss(ak.EmployeeID)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-	}
-
-	// Fetch all employee IDs from the AppraisalKpis table
-	existingEmployeeIDs := make([]int, 0)
-	if err := r.Db.Model(&models.AppraisalKpi{}).Pluck("employee_id", &existingEmployeeIDs).Error; err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve existing employee IDs"})
+	if err := r.appraisals.CheckNew(ctx, &appraisal); err != nil {
+		respondError(c, err)
 		return
 	}
-	_, name, err := checkAssignType(r.Db, uint16(appraisal.AppraisalFor))
+
+	// Enrolling the employees takes several TOSS calls per employee, so the
+	// appraisal is created by a background job
+	job, err := enqueueJob(ctx, constants.JOB_TYPE_CREATE_APPRAISAL, appraisal)
 	if err != nil {
-		log.Error("invalid assign type")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assign type"})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
-	appraisal.AppraisalForName = name
-
-	switch appraisal.AppraisalForName {
-	case constants.ASSIGN_TYPE_TEAM:
-		errCode, name, err := utils.VerifyTeamAndSupervisorID(appraisal.SelectedFieldID, appraisal.SupervisorID)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-		appraisal.SelectedFieldNames = name
-		kpis := make([]models.Kpi, 0)
-
-		empIds, err := utils.GetEmployeesId(uint16(appraisal.SelectedFieldID))
-		if err != nil {
-			log.Error(err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch employee IDs"})
-			return
-		}
-
-		// Fetch employee names and role IDs for each employee ID
-		employeeDataList := make([]models.EmployeeData, 0)
-		for _, empID := range empIds {
-			empName, err := utils.GetEmployeeName(empID)
-			if err != nil {
-				log.Error("Invalid Employee ID")
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Employee ID"})
-				return
-			}
-
-			roleIDs, err := utils.GetRolesID([]uint16{empID})
-			if err != nil {
-				log.Error(err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch role IDs"})
-				return
-			}
-
-			roleID := roleIDs[0] // Retrieve the RoleID for the employee (assuming there's only one role ID for each employee)
-
-			designationName, err := utils.GetDesignationName(roleID)
-			if err != nil {
-				log.Error(err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch role IDs"})
-				return
-			}
-
-			employeeImage, err := utils.GetEmployeeImageByID(uint64(empID))
-			if err != nil {
-				log.Error(err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch employee image"})
-				return
-			}
-
-			projectDetails, err := utils.GetProjectDetailsByEmployeeID(empID)
-			if err != nil {
-				log.Error(err.Error())
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch project details"})
-				return
-			}
-
-			var ProjectID uint16
-			var ProjectName string
-
-			for _, project := range projectDetails {
-				ProjectID = project.ProjectID
-				ProjectName = project.ProjectName
-				ProjectName = strings.Trim(ProjectName, "\r\n")
-			}
-			baseurl := os.Getenv("TOSS_BASE_URL")
-			employeeData := models.EmployeeData{
-				AppraisalID:     appraisal.ID,
-				TossEmpID:       empID,
-				EmployeeName:    empName,
-				TeamID:          ProjectID,
-				TeamName:        ProjectName,
-				EmployeeImage:   baseurl + "/" + employeeImage,
-				Designation:     roleID, // Assign the RoleID as Designation
-				DesignationName: designationName,
-				AppraisalStatus: "pending",
-			}
-			employeeDataList = append(employeeDataList, employeeData)
-		}
-
-		// Append EmployeeData to Appraisal
-		appraisal.EmployeesList = employeeDataList
-
-		roleIds, err := utils.GetRolesID(empIds)
-		if err != nil {
-			log.Error(err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch role IDs"})
-			return
-		}
-
-		db := r.Db.Model(&models.Kpi{})
-		db = db.Joins("JOIN assign_types ON assign_types.id = kpis.assign_type_id").
-			Where(`(kpis.selected_assign_id = ? AND assign_types.assign_type = ?)
-			OR (kpis.selected_assign_id IN (?) AND assign_types.assign_type = ?)
-			OR (kpis.selected_assign_id IN (?) AND assign_types.assign_type = ?)`,
-				appraisal.SelectedFieldID, constants.ASSIGN_TYPE_TEAM, empIds, constants.ASSIGN_TYPE_INDIVIDUAL, roleIds, constants.ASSIGN_TYPE_ROLE)
-		err = db.Model(&models.Kpi{}).Order("id ASC").Find(&kpis).Error
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
-			return
-		}
-
-		if len(kpis) == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "KPI does not exist for the team"})
-			return
-		}
-
-		for _, kpi := range kpis {
-			if kpi.AssignTypeName == constants.ASSIGN_TYPE_INDIVIDUAL {
-				appraisalKpi := models.AppraisalKpi{
-					AppraisalID: appraisal.ID,
-					EmployeeID:  kpi.SelectedAssignID,
-					KpiID:       kpi.ID,
-					Status:      "pending",
-				}
-				appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
-			}
-
-			if kpi.AssignTypeName == constants.ASSIGN_TYPE_TEAM {
-
-				// Assign the KPI to individual employees of the team
-				for _, employeeID := range empIds {
-					appraisalKpi := models.AppraisalKpi{
-						AppraisalID: appraisal.ID,
-						EmployeeID:  employeeID,
-						KpiID:       kpi.ID,
-						Status:      "pending",
-					}
-					appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
-				}
-			}
-			if kpi.AssignTypeName == constants.ASSIGN_TYPE_ROLE {
-
-				for _, employeeID := range empIds {
-					roleIDs, err := utils.GetRolesID([]uint16{employeeID})
-					if err != nil {
-						log.Error(err)
-						c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch role IDs"})
-						return
-					}
-					roleID := roleIDs[0] // Assuming there's only one role ID for each employee
-
-					if roleID == kpi.SelectedAssignID {
-						appraisalKpi := models.AppraisalKpi{
-							AppraisalID: appraisal.ID,
-							EmployeeID:  employeeID,
-							KpiID:       kpi.ID,
-							Status:      "pending",
-						}
-						appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
-					}
-				}
-
-			}
-
-		}
-
-	case constants.ASSIGN_TYPE_INDIVIDUAL:
-		errCode, name, err := utils.VerifyIndividualAndSupervisorID(appraisal.SelectedFieldID, appraisal.SupervisorID)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-
-		// Fetch employee name
-		empName, err := utils.GetEmployeeName(appraisal.SelectedFieldID)
-		if err != nil {
-			log.Error("Invalid Employee ID")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Employee ID"})
-			return
-		}
 
-		// Get the role ID for the employee
-		roleIDs, err := utils.GetRolesID([]uint16{uint16(appraisal.SelectedFieldID)})
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch role IDs"})
-			return
-		}
-
-		roleID := roleIDs[0] // Retrieve the RoleID for the employee (assuming there's only one role ID for each employee)
-
-		designationName, err := utils.GetDesignationName(uint16(roleID))
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch role IDs"})
-			return
-		}
-
-		employeeImage, err := utils.GetEmployeeImageByID(uint64(appraisal.SelectedFieldID))
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch Employees Image"})
-			return
-		}
-
-		projectDetails, err := utils.GetProjectDetailsByEmployeeID(appraisal.SelectedFieldID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch Project Details"})
-			log.Error(err.Error())
-			return
-		}
-
-		var ProjectID uint16
-		var ProjectName string
-
-		for _, project := range projectDetails {
-			ProjectID = project.ProjectID
-			ProjectName = project.ProjectName
-			ProjectName = strings.Trim(ProjectName, "\r\n")
-		}
-		baseurl := os.Getenv("TOSS_BASE_URL")
-		// Create EmployeeData instance
-		employeeData := models.EmployeeData{
-			AppraisalID:     appraisal.ID,
-			TossEmpID:       appraisal.AppraisalFor,
-			EmployeeName:    empName,
-			TeamID:          ProjectID,
-			TeamName:        ProjectName,
-			EmployeeImage:   baseurl + "/" + employeeImage,
-			Designation:     roleID, // Assign the RoleID as Designation
-			DesignationName: designationName,
-			AppraisalStatus: "pending",
-		}
-
-		// Append EmployeeData to Appraisal
-		appraisal.EmployeesList = []models.EmployeeData{employeeData}
-
-		appraisal.SelectedFieldNames = name
-		kpis := make([]models.Kpi, 0)
-		if err := r.Db.Where("assign_type_id = ? AND selected_assign_id = ?", appraisal.AppraisalFor, appraisal.SelectedFieldID).Find(&kpis).Error; err != nil {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "An error occurred while retrieving KPIs"})
-			return
-		}
-
-		if len(kpis) == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Kpi does not exist for the Individual"})
-			return
-		}
-
-		for _, kpi := range kpis {
-			appraisalKpi := models.AppraisalKpi{
-				AppraisalID: appraisal.ID,
-				EmployeeID:  appraisal.SelectedFieldID,
-				KpiID:       kpi.ID,
-				Status:      "pending",
-			}
-			appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
-		}
-
-		// Modify the Case ROLE section
-	case constants.ASSIGN_TYPE_ROLE:
-		errCode, name, err := utils.CheckRoleExists(appraisal.SelectedFieldID)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-		appraisal.SelectedFieldNames = name
-
-		// Get employee IDs for the provided role ID
-		employeeIDs, err := utils.GetEmployeeIDsByDesignation(uint16(appraisal.SelectedFieldID))
-		if err != nil {
-			log.Error(err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch employee IDs"})
-			return
-		}
-
-		if len(employeeIDs) == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "No employees found for the provided role"})
-			return
-		}
-
-		employeeDataList := make([]models.EmployeeData, 0)
-		for _, empID := range employeeIDs {
-			empName, err := utils.GetEmployeeName(empID)
-			if err != nil {
-				log.Error("Invalid Employee ID")
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Employee ID"})
-				return
-			}
-
-			designationName, err := utils.GetDesignationName(uint16(appraisal.SelectedFieldID))
-			if err != nil {
-				log.Error(err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch designation name"})
-				return
-			}
-			employeeImage, err := utils.GetEmployeeImageByID(uint64(empID))
-			if err != nil {
-				log.Error(err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch Employee Image"})
-				return
-			}
-
-			projectDetails, err := utils.GetProjectDetailsByEmployeeID(empID)
-			if err != nil {
-				log.Error(err.Error())
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch Project Details"})
-				return
-			}
-
-			var ProjectID uint16
-			var ProjectName string
-
-			for _, project := range projectDetails {
-				ProjectID = project.ProjectID
-				ProjectName = project.ProjectName
-				ProjectName = strings.Trim(ProjectName, "\r\n")
-			}
-			baseurl := os.Getenv("TOSS_BASE_URL")
-			employeeData := models.EmployeeData{
-				AppraisalID:     appraisal.ID,
-				TossEmpID:       empID,
-				EmployeeName:    empName,
-				EmployeeImage:   baseurl + "/" + employeeImage,
-				Designation:     uint16(appraisal.SelectedFieldID),
-				DesignationName: designationName,
-				TeamID:          ProjectID,
-				TeamName:        ProjectName,
-				AppraisalStatus: "pending",
-			}
-			employeeDataList = append(employeeDataList, employeeData)
-		}
-
-		// Append EmployeeData to Appraisal
-		appraisal.EmployeesList = employeeDataList
-
-		kpis := make([]models.Kpi, 0)
-		if err := r.Db.Where("assign_type_id = ? AND selected_assign_id = ?", appraisal.AppraisalFor, appraisal.SelectedFieldID).Find(&kpis).Error; err != nil {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "An error occurred while retrieving KPIs"})
-			return
-		}
-
-		if len(kpis) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "KPI does not exist for the Role"})
-			return
-		}
+	respondJobAccepted(c, job)
+}
 
-		for _, kpi := range kpis {
-			for _, empID := range employeeIDs {
-				appraisalKpi := models.AppraisalKpi{
-					AppraisalID: appraisal.ID,
-					EmployeeID:  empID,
-					KpiID:       kpi.ID,
-					Status:      "pending",
-				}
-				appraisal.AppraisalKpis = append(appraisal.AppraisalKpis, appraisalKpi)
-			}
-		}
+// RunCreateAppraisal is the job creating the appraisal queued by CreateAppraisal
+func (r *AppraisalService) RunCreateAppraisal(ctx context.Context, job *models.Job, progress *controller.JobProgress) error {
+	var appraisal models.Appraisal
+	if err := json.Unmarshal([]byte(job.Payload), &appraisal); err != nil {
+		return err
 	}
 
-	// check appraisal type exists
-	err = checkAppraisalType(r.Db, appraisal.AppraisalTypeStr)
-	if err != nil {
-		log.Error("invalid appraisal type")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appraisal type"})
-		return
-	}
-	var appraisalFlow models.AppraisalFlow
-	err = r.Db.Model(&models.AppraisalFlow{}).First(&appraisalFlow, appraisal.AppraisalFlowID).Error
-	if err != nil {
-		log.Error("invalid appraisal flow ID")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid appraisal flow ID"})
-		return
+	if err := progress.SetTotal(1); err != nil {
+		return err
 	}
 
-	// Call GetSupervisorName function to retrieve the supervisor name
-	supervisorName, err := utils.GetSupervisorName(appraisal.SupervisorID)
+	dbAppraisal, err := r.appraisals.Create(ctx, &appraisal)
 	if err != nil {
-		log.Error("failed to get supervisor name")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get supervisor name"})
-		return
+		return err
 	}
-	appraisal.SupervisorName = supervisorName
 
-	dbAppraisal, err := controller.CreateAppraisal(r.Db, &appraisal)
-	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	if err := progress.ItemSucceeded(); err != nil {
+		return err
 	}
-
-	c.JSON(http.StatusCreated, dbAppraisal)
+	return progress.SetResult(dbAppraisal.ID, fmt.Sprintf("/v1/appraisals/%d", dbAppraisal.ID))
 }
 
+// @summary Get an appraisal
+// @success 200 models.AppraisalResponse
 func (r *AppraisalService) GetAppraisalByID(c *gin.Context) {
 	log.Info("Initializing GetAppraisalByID handler function...")
 
 	id, _ := strconv.ParseUint(c.Param("id"), 0, 64)
 
-	var appraisal models.Appraisal
-	err := controller.GetAppraisalByID(r.Db, &appraisal, id)
+	appraisal, err := r.appraisals.Get(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error(err.Error())
-			c.JSON(http.StatusNotFound, gin.H{"error": "Record not found against appraisal id"})
-
-		} else {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-
+		respondError(c, err)
 		return
 	}
 
 	// Create a response structure with the required fields
-	response := struct {
-		Appraisal models.Appraisal `json:"appraisal"`
-	}{
-		Appraisal: appraisal,
+	response := models.AppraisalResponse{
+		Appraisal: *appraisal,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// @summary List the employees of an appraisal
+// @query toss_emp_id integer TOSS id of an employee
+// @success 200 []models.EmployeeData
 func (r *AppraisalService) GetEmployeeDataByAppraisalID(c *gin.Context) {
 	log.Info("Initializing GetEmployeeDataByAppraisalID handler function...")
 
 	id, _ := strconv.ParseUint(c.Param("id"), 0, 64)
-	var employeeData []models.EmployeeData
-	db := r.Db.Model(&models.EmployeeData{})
 
-	tossEmpId := c.Query("toss_emp_id")
-	if tossEmpId != "" {
-		db = db.Where("toss_emp_id = ?", tossEmpId)
-	}
-
-	err := controller.GetEmployeeDataByAppraisalID(db, &employeeData, id)
+	employeeData, err := r.appraisals.GetEmployees(c.Request.Context(), id, c.Query("toss_emp_id"))
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error(err.Error())
-			c.JSON(http.StatusNotFound, gin.H{"error": "Record not found against employee data"})
-
-		} else {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, employeeData)
 }
 
+// @summary List the appraisals
+// @query appraisal_name string Name of the appraisals
+// @query supervisor_id integer Supervisor of the appraisals
+// @success 200 []models.Appraisal
 func (r *AppraisalService) GetAllAppraisals(c *gin.Context) {
 	log.Info("Initializing GetAllAppraisal handler function...")
-	var appraisals []models.Appraisal
-	db := r.Db.Model(&models.Appraisal{})
-
-	appraisalName := c.Query("appraisal_name")
-	supervisorID := c.Query("supervisor_id")
-
-	if appraisalName != "" {
-		db = db.Where("appraisal_name LIKE ?", "%"+appraisalName+"%")
-	}
-
-	if supervisorID != "" {
-		db = db.Where("supervisor_id = ?", supervisorID)
-	}
-
-	err := controller.GetAllAppraisals(db, &appraisals)
 
+	appraisals, err := r.appraisals.List(c.Request.Context(), domain.AppraisalFilter{
+		AppraisalName: c.Query("appraisal_name"),
+		SupervisorID:  c.Query("supervisor_id"),
+	})
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, appraisals)
 }
 
+// @summary Update an appraisal
+// @body models.Appraisal
+// @success 200 models.Appraisal
 func (r *AppraisalService) UpdateAppraisal(c *gin.Context) {
 	log.Info("Initializing UpdateAppraisal handler function...")
 
@@ -681,333 +263,153 @@ func (r *AppraisalService) UpdateAppraisal(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&appraisal)
 	if err != nil {
-		errs, ok := controller.ErrValidationSlice(err)
-		if !ok {
-			log.Error(err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		log.Error(err.Error())
-		if len(errs) > 1 {
-			c.JSON(http.StatusBadRequest, gin.H{"errors": errs})
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": errs[0]})
-		}
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 
-	// Validate each FlowStep struct
-	for _, ak := range appraisal.AppraisalKpis {
-		if ak.EmployeeID == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "employee_id field is required"})
-			return
-		}
-
-		if ak.KpiID == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "kpi_id field is required "})
-			return
-		}
-
-		if ak.Status == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "status field is required "})
-			return
-		}
-		//check employees id in toss api
-		errCode, err := utils.CheckIndividualAgainstToss(ak.EmployeeID)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-	}
-
-	// Fetch all employee IDs from the AppraisalKpis table
-	existingEmployeeIDs := make([]int, 0)
-	if err := r.Db.Model(&models.AppraisalKpi{}).Pluck("employee_id", &existingEmployeeIDs).Error; err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve existing employee IDs"})
-		return
-	}
-
-	// Check if the provided employee IDs exist in the AppraisalKpis table
-	for _, ed := range appraisal.EmployeesList {
-
-		errCode, _, err := utils.CheckRoleExists(ed.Designation)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-
-		employeeIDExists := false
-		for _, existingID := range existingEmployeeIDs {
-			if existingID == int(ed.TossEmpID) {
-				employeeIDExists = true
-				break
-			}
-		}
-
-		if !employeeIDExists {
-			c.JSON(http.StatusNotFound, gin.H{"error": "No KPI found against this Employee"})
-			return
-		}
-
-		// Check employee ID in the Toss API
-		errCode, err = utils.CheckIndividualAgainstToss(ed.TossEmpID)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-	}
-
-	//check assigns type
-	_, name, err := checkAssignType(r.Db, uint16(appraisal.AppraisalFor))
-
+	dbAppraisal, err := r.appraisals.Update(c.Request.Context(), uint16(id), &appraisal)
 	if err != nil {
-		log.Error("invalid assign type")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assign type"})
+		respondError(c, err)
 		return
 	}
-	appraisal.AppraisalForName = name
-
-	switch appraisal.AppraisalForName {
-	case constants.ASSIGN_TYPE_TEAM:
-		errCode, name, err := utils.VerifyTeamAndSupervisorID(appraisal.SelectedFieldID, appraisal.SupervisorID)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-		appraisal.SelectedFieldNames = name
-
-	case constants.ASSIGN_TYPE_INDIVIDUAL:
-		errCode, name, err := utils.VerifyIndividualAndSupervisorID(appraisal.SelectedFieldID, appraisal.SupervisorID)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-		appraisal.SelectedFieldNames = name
-
-	case constants.ASSIGN_TYPE_ROLE:
-		errCode, name, err := utils.CheckRoleExists(appraisal.SelectedFieldID)
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-		appraisal.SelectedFieldNames = name
-	}
 
-	err = checkAppraisalType(r.Db, appraisal.AppraisalTypeStr)
-	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appraisal type"})
-		return
-	}
-	appraisal.ID = uint16(id)
+	c.JSON(http.StatusOK, dbAppraisal)
+}
 
-	// checking appraisal flow id exists in db
-	var appraisalFlow models.AppraisalFlow
-	err = r.Db.Model(&models.AppraisalFlow{}).First(&appraisalFlow, appraisal.AppraisalFlowID).Error
-	if err != nil {
-		log.Error("invalid appraisal flow id")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid appraisal flow id"})
-		return
-	}
+// @summary Delete an appraisal
+// @success 204
+func (r *AppraisalService) DeleteAppraisal(c *gin.Context) {
+	log.Info("Initializing DeleteAppraisal handler function...")
 
-	// Call GetSupervisorName function to retrieve the supervisor name
-	supervisorName, err := utils.GetSupervisorName(appraisal.SupervisorID)
-	if err != nil {
-		log.Error("failed to get supervisor name")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get supervisor name"})
-		return
-	}
-	appraisal.SupervisorName = supervisorName
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
 
-	// callling controller update function
-	dbAppraisal, err := controller.UpdateAppraisal(r.Db, &appraisal)
-	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := r.appraisals.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dbAppraisal)
+	c.Status(http.StatusNoContent)
 }
 
-func (r *AppraisalService) DeleteAppraisal(c *gin.Context) {
-	log.Info("Initializing DeleteAppraisal handler function...")
+// PublishResults makes the final scores of an appraisal visible to the employees
+// through the /me endpoints. Only the listed employees are published if any.
+//
+// @summary Publish the results of an appraisal to its employees
+// @body models.PublishRequest
+// @success 200 object
+func (r *AppraisalService) PublishResults(c *gin.Context) {
+	log.Info("Initializing PublishResults handler function...")
 
-	var appraisal models.Appraisal
 	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
-	appraisal.ID = uint16(id)
-
-	err := controller.GetAppraisalByID(r.Db, &appraisal, id)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error(err.Error())
-			c.JSON(http.StatusNotFound, gin.H{"error": "Record not found against appraisal id"})
-
-		} else {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
 
+	// The request body is optional
+	var request models.PublishRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 
-	err = controller.DeleteAppraisal(r.Db, &appraisal, id)
+	count, err := r.appraisals.PublishResults(c.Request.Context(), id, request.EmployeeIDs)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.Status(http.StatusNoContent)
-}
-
-func checkAppraisalType(db *gorm.DB, appraisal_type string) error {
-	log.Info("Checking Appraisal type")
-	var appraisalTypeModel models.AppraisalType
-	err := db.Model(&models.AppraisalType{}).Where("appraisal_type = ?", appraisal_type).First(&appraisalTypeModel).Error
-	if err != nil {
-		return err
-	}
-
-	return nil
+	c.JSON(http.StatusOK, gin.H{"published": count})
 }
 
+// @summary List the kpis of an appraisal
+// @query employee_id integer Employee the kpis are assigned to
+// @success 200 []models.AppraisalKpi
 func (r *AppraisalService) GetAppraisalKpisByEmpID(c *gin.Context) {
 	log.Info("Initializing GetAppraisalkpisByEmpID handler function...")
 
 	id, _ := strconv.ParseUint(c.Param("emp_id"), 0, 64)
-	var appraisalKpi []models.AppraisalKpi
-
-	//Adding query parameters for employees id
-	db := r.Db.Model(&models.AppraisalKpi{})
-	employeeid := c.Query("employee_id")
-	if employeeid != "" {
-		db = db.Where("employee_id = ?", employeeid)
-	}
 
-	err := controller.GetAppraisalKpisByEmpID(db, &appraisalKpi, id)
+	appraisalKpi, err := r.appraisals.GetKpis(c.Request.Context(), id, c.Query("employee_id"))
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error("Appraisal record not found against the given id")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Record not found against appraisal kpi id"})
-
-		} else {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, appraisalKpi)
 }
 
+// @summary Score the kpis of an employee in an appraisal
+// @body []models.Score
+// @success 201 []models.Score
 func (r *AppraisalService) AddScore(c *gin.Context) {
 	log.Info("Initializing Score handler function...")
 
-	appraisalID := c.Param("id")
-	employeeID := c.Param("emp_id")
-
-	// Check if the employee ID exists
-	var appraisalKpi models.AppraisalKpi
-	if err := r.Db.Model(&models.AppraisalKpi{}).Preload(clause.Associations).Where("appraisal_id = ? AND employee_id = ?", appraisalID, employeeID).First(&appraisalKpi).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error(err.Error())
-			c.JSON(http.StatusNotFound, gin.H{"error": "Record not found against appraisal id"})
-		} else {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 64)
+	employeeID, _ := strconv.ParseUint(c.Param("emp_id"), 0, 64)
+
+	var score []models.Score
+
+	if err := c.ShouldBindJSON(&score); err != nil {
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 
-	// Get all the appraisalKpi IDs for the given appraisalID and employeeID
-	var existingKpis []models.AppraisalKpi
-	if err := r.Db.Model(&models.AppraisalKpi{}).Preload(clause.Associations).Where("appraisal_id = ? AND employee_id = ?", appraisalID, employeeID).Find(&existingKpis).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		log.Error(err.Error())
+	scores, err := r.scoring.AddScores(c.Request.Context(), appraisalID, employeeID, score)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	// Create a map of existing appraisal_kpi_id for faster lookup
-	existingKpiMap := make(map[uint16]bool)
-	for _, kpi := range existingKpis {
-		existingKpiMap[kpi.ID] = true
-	}
+	c.JSON(http.StatusCreated, scores)
+}
 
-	var score []models.Score
+// @summary List the scores of an employee in an appraisal
+// @success 200 []models.Score
+func (r *AppraisalService) GetScores(c *gin.Context) {
+	log.Info("Initializing GetScores handler function...")
 
-	if err := c.ShouldBindJSON(&score); err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 64)
+	employeeID, _ := strconv.ParseUint(c.Param("emp_id"), 0, 64)
+
+	scores, err := r.scoring.GetScores(c.Request.Context(), appraisalID, employeeID)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	if len(score) != len(existingKpis) {
-		log.Error("number of scores does not match the number of appraisal_kpi records")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "number of scores does not match the number of appraisal_kpi records"})
+	c.JSON(http.StatusOK, scores)
+}
+
+// GetHistory returns the actions taken on the appraisal of an employee, including
+// the ones taken by delegates on behalf of the evaluators
+//
+// @summary List the actions taken on the appraisal of an employee
+// @success 200 []models.AppraisalHistory
+func (r *AppraisalService) GetHistory(c *gin.Context) {
+	log.Info("Initializing GetHistory handler function...")
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 64)
+	employeeID, _ := strconv.ParseUint(c.Param("emp_id"), 0, 64)
+
+	history, err := r.appraisals.GetHistory(c.Request.Context(), appraisalID, employeeID)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	// Check if the appraisal_kpi_id exists in the database and matches with the existing appraisal_kpi records
-	for k := range score {
-		if !existingKpiMap[score[k].AppraisalKpiID] {
-			errMsg := fmt.Sprintf("invalid appraisal_kpi_id :%v", score[k].AppraisalKpiID)
-			log.Error(errMsg)
-			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
-			return
-		}
+	c.JSON(http.StatusOK, history)
+}
 
-		for k := range score {
-			found := false
-			for _, existingKpi := range existingKpis {
-				if score[k].AppraisalKpiID == existingKpi.ID {
-					found = true
-					break
-				}
-			}
+// @summary Get the acknowledgement of the results of an employee
+// @success 200 models.Acknowledgement
+func (r *AppraisalService) GetAcknowledgement(c *gin.Context) {
+	log.Info("Initializing GetAcknowledgement handler function...")
 
-			if !found {
-				errMsg := "invalid appraisal_kpi_id"
-				log.Error(errMsg)
-				c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
-				return
-			}
-		}
-		kpiType := existingKpis[k].Kpi.KpiTypeStr
-
-		switch kpiType {
-		case constants.FEEDBACK_KPI_TYPE, constants.OBSERVATORY_KPI_TYPE:
-			score[k].Score = nil
-
-		case constants.QUESTIONNAIRE_KPI_TYPE:
-			if score[k].Score != nil && (*score[k].Score != 0 && *score[k].Score != 1) {
-				errMsg := "questionnaire score should be either 0 or 1"
-				log.Error(errMsg)
-				c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
-				return
-			}
-			score[k].TextAnswer = ""
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 64)
+	employeeID, _ := strconv.ParseUint(c.Param("emp_id"), 0, 64)
 
-		case constants.MEASURED_KPI_TYPE:
-			score[k].TextAnswer = ""
-		}
-	}
-	// Save the score to the database or perform any necessary operations
-	scores, err := controller.AddScore(r.Db, score)
+	acknowledgement, err := r.appraisals.GetAcknowledgement(c.Request.Context(), appraisalID, employeeID)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, scores)
+	c.JSON(http.StatusOK, acknowledgement)
 }
@@ -1,23 +1,23 @@
 package service
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	"github.com/mrehanabbasi/appraisal-system-backend/constants"
-	"github.com/mrehanabbasi/appraisal-system-backend/controller"
 	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	"github.com/mrehanabbasi/appraisal-system-backend/domain"
 	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
 	"github.com/mrehanabbasi/appraisal-system-backend/models"
-	"github.com/mrehanabbasi/appraisal-system-backend/utils"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type AppraisalFlowService struct {
-	Db *gorm.DB
+	Db    *gorm.DB
+	flows *domain.FlowService
 }
 
 func NewAppraisalFlowService() *AppraisalFlowService {
@@ -34,7 +34,7 @@ func NewAppraisalFlowService() *AppraisalFlowService {
 		panic(err)
 	}
 
-	return &AppraisalFlowService{Db: db}
+	return &AppraisalFlowService{Db: db, flows: domain.NewFlowService(db)}
 }
 
 func populateAppraisalTypeTable(db *gorm.DB) error {
@@ -67,6 +67,9 @@ func populateAppraisalTypeTable(db *gorm.DB) error {
 	return nil
 }
 
+// @summary Create an appraisal flow
+// @body models.AppraisalFlow
+// @success 201 models.AppraisalFlow
 func (r *AppraisalFlowService) CreateAppraisalFlow(c *gin.Context) {
 	log.Info("Initializing CreateAppraisalFlow handler function...")
 
@@ -74,136 +77,59 @@ func (r *AppraisalFlowService) CreateAppraisalFlow(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&appraisalFlow)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 
-	// Validate AppraisalFlow struct
-	err = appraisalFlow.Validate()
+	dbAppraisalFlow, err := r.flows.Create(c.Request.Context(), &appraisalFlow)
 	if err != nil {
-		errs, ok := controller.ErrValidationSlice(err)
-		if !ok {
-			log.Error(err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		log.Error(err.Error())
-		if len(errs) > 1 {
-			c.JSON(http.StatusBadRequest, gin.H{"errors": errs})
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": errs[0]})
-		}
-		return
-	}
-
-	appraisalFlow.ID = 0
-
-	// Validate each FlowStep struct
-	for _, flowStep := range appraisalFlow.FlowSteps {
-		errCode, err := utils.CheckIndividualAgainstToss(uint16(flowStep.UserId))
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-		err = flowStep.Validate()
-		if err != nil {
-			errs, ok := controller.ErrValidationSlice(err)
-			if !ok {
-				log.Error(err.Error())
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-
-			log.Error(err.Error())
-			if len(errs) > 1 {
-				c.JSON(http.StatusBadRequest, gin.H{"errors": errs})
-			} else {
-				c.JSON(http.StatusBadRequest, gin.H{"error": errs[0]})
-			}
-			return
-		}
-	}
-	// check employee id exist
-	// errCode, err := utils.CheckIndividualAgainstToss(uint16(appraisalFlow.CreatedBy))
-	// if err != nil {
-	// 	log.Error(err.Error())
-	// 	c.JSON(errCode, gin.H{"error": err.Error()})
-	// 	return
-	// }
-	//check Assign type exist
-	assignType, name, err := checkAssignType(r.Db, uint16(appraisalFlow.AssignTypeID))
-	if err != nil {
-		log.Error("invalid assign type")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assign type"})
-		return
-	}
-	appraisalFlow.AssignTypeName = name
-
-	//Check team role and individual
-	errorCode, name, err := utils.VerifyIdAgainstTossApis(appraisalFlow.SelectedAssignID, string(assignType.AssignType))
-	if err != nil {
-		log.Error(err.Error())
-		c.JSON(errorCode, gin.H{"error": err.Error()})
-		return
-	}
-	appraisalFlow.SelectedAssignName = name
-
-	dbAppraisalFlow, err := controller.CreateAppraisalFlow(r.Db, &appraisalFlow)
-	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, dbAppraisalFlow)
 }
 
+// @summary Get an appraisal flow
+// @success 200 models.AppraisalFlow
 func (r *AppraisalFlowService) GetAppraisalFlowByID(c *gin.Context) {
 	log.Info("Initializing GetAppraisalFlowByID handler function...")
 
 	id, _ := strconv.ParseUint(c.Param("id"), 0, 64)
 
-	var appraisalFlow models.AppraisalFlow
-	err := controller.GetAppraisalFlowByID(r.Db, &appraisalFlow, id)
+	appraisalFlow, err := r.flows.Get(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error("appraisal flow record not found against the given id")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Record not found against appraisal flow id"})
-
-		} else {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, appraisalFlow)
 }
 
+// @summary List the appraisal flows
+// @query flow_name string Name of the flows
+// @query is_active boolean Whether the flows are active
+// @query team_id integer Team the flows are assigned to
+// @success 200 []models.AppraisalFlow
 func (r *AppraisalFlowService) GetAllAppraisalFlows(c *gin.Context) {
 	log.Info("Initializing GetAllAppraisalFlow handler function...")
 
-	var appraisalFlows []models.AppraisalFlow
-
-	flowName := c.Query("flow_name")
-	isActive := c.Query("is_active")
-	teamId := c.Query("team_id")
-
-	err := controller.GetAllAppraisalFlow(flowName, isActive, teamId, r.Db, &appraisalFlows)
-
+	appraisalFlows, err := r.flows.List(c.Request.Context(), domain.FlowFilter{
+		FlowName: c.Query("flow_name"),
+		IsActive: c.Query("is_active"),
+		TeamID:   c.Query("team_id"),
+	})
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, appraisalFlows)
 }
 
+// @summary Update an appraisal flow
+// @body models.AppraisalFlow
+// @success 200 models.AppraisalFlow
 func (r *AppraisalFlowService) UpdateAppraisalFlow(c *gin.Context) {
 	log.Info("Initializing UpdateAppraisalFlow handler function...")
 
@@ -212,120 +138,28 @@ func (r *AppraisalFlowService) UpdateAppraisalFlow(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&appraisalFlow)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Validate AppraisalFlow struct
-	err = appraisalFlow.Validate()
-	if err != nil {
-		errs, ok := controller.ErrValidationSlice(err)
-		if !ok {
-			log.Error(err.Error())
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		log.Error(err.Error())
-		if len(errs) > 1 {
-			c.JSON(http.StatusBadRequest, gin.H{"errors": errs})
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": errs[0]})
-		}
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 
-	appraisalFlow.ID = uint16(id)
-
-	// Validate each FlowStep struct
-	for _, flowStep := range appraisalFlow.FlowSteps {
-		errCode, err := utils.CheckIndividualAgainstToss(uint16(flowStep.UserId))
-		if err != nil {
-			log.Error(err.Error())
-			c.JSON(errCode, gin.H{"error": err.Error()})
-			return
-		}
-		err = flowStep.Validate()
-		if err != nil {
-			errs, ok := controller.ErrValidationSlice(err)
-			if !ok {
-				log.Error(err.Error())
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-
-			log.Error(err.Error())
-			if len(errs) > 1 {
-				c.JSON(http.StatusBadRequest, gin.H{"errors": errs})
-			} else {
-				c.JSON(http.StatusBadRequest, gin.H{"error": errs[0]})
-			}
-			return
-		}
-	}
-
-	// check employee id exist
-	// errCode, err := utils.CheckIndividualAgainstToss(uint16(appraisalFlow.CreatedBy))
-	// if err != nil {
-	// 	log.Error(err.Error())
-	// 	c.JSON(errCode, gin.H{"error": err.Error()})
-	// 	return
-	// }
-	//check Assign type exist
-	assignType, name, err := checkAssignType(r.Db, uint16(appraisalFlow.AssignTypeID))
-	if err != nil {
-		log.Error("invalid assign type")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assign type"})
-		return
-	}
-	appraisalFlow.AssignTypeName = name
-
-	//Check team role and individual
-	errorCode, name, err := utils.VerifyIdAgainstTossApis(appraisalFlow.SelectedAssignID, string(assignType.AssignType))
+	dbAppraisalFlow, err := r.flows.Update(c.Request.Context(), uint16(id), &appraisalFlow)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(errorCode, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
-	appraisalFlow.SelectedAssignName = name
 
-	// calling controller update method
-	err = controller.UpdateAppraisalFlow(r.Db, &appraisalFlow)
-	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, appraisalFlow)
+	c.JSON(http.StatusOK, dbAppraisalFlow)
 }
 
+// @summary Delete an appraisal flow
+// @success 204
 func (r *AppraisalFlowService) DeleteAppraisalFlow(c *gin.Context) {
 	log.Info("Initializing DeleteAppraisalFlow handler function...")
 
-	var appraisalFlow models.AppraisalFlow
 	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
-	appraisalFlow.ID = uint16(id)
-
-	err := controller.GetAppraisalFlowByID(r.Db, &appraisalFlow, id)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error("Appraisal flow record not found against the given id")
-			c.JSON(http.StatusNotFound, gin.H{"error": "Record not found against appraisal flow id"})
-
-		} else {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-
-		return
-	}
 
-	err = controller.DeleteAppraisalFlow(r.Db, &appraisalFlow, id)
-	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := r.flows.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
 		return
 	}
 
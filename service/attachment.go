@@ -0,0 +1,325 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/storage"
+	"gorm.io/gorm"
+)
+
+type AttachmentService struct {
+	Db *gorm.DB
+}
+
+func NewAttachmentService() *AttachmentService {
+	db := database.DB
+	err := db.AutoMigrate(&models.Attachment{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &AttachmentService{Db: db}
+}
+
+// UploadAttachment attaches evidence to an appraisal kpi. The request is a
+// multipart form with the appraisal_kpi_id and the file.
+//
+// @summary Attach evidence to an appraisal kpi
+// @form appraisal_kpi_id integer Appraisal kpi the file is evidence for
+// @form file file The evidence
+// @success 201 models.Attachment
+// @auth
+func (s *AttachmentService) UploadAttachment(c *gin.Context) {
+	log.Info("Initializing UploadAttachment handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	maxSize := attachmentMaxSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+
+	appraisalKpiID, err := strconv.ParseUint(c.PostForm("appraisal_kpi_id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Invalid("invalid appraisal_kpi_id"))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, apperrors.Invalid("file is required"))
+		return
+	}
+	if fileHeader.Size > maxSize {
+		respondError(c, apperrors.New(apperrors.KindTooLarge, fmt.Sprintf("file should not be larger than %d bytes", maxSize)))
+		return
+	}
+
+	fileName := filepath.Base(fileHeader.Filename)
+	allowedType, ok := constants.ATTACHMENT_CONTENT_TYPES[strings.ToLower(filepath.Ext(fileName))]
+	if !ok {
+		respondError(c, apperrors.New(apperrors.KindUnsupportedMedia, "file type is not allowed"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+	if int64(len(data)) > maxSize {
+		respondError(c, apperrors.New(apperrors.KindTooLarge, fmt.Sprintf("file should not be larger than %d bytes", maxSize)))
+		return
+	}
+
+	if !contentMatchesType(data, allowedType) {
+		respondError(c, apperrors.New(apperrors.KindUnsupportedMedia, "file content does not match its type"))
+		return
+	}
+
+	appraisalKpi, err := controller.GetAttachableKpi(s.Db.WithContext(ctx), appraisalKpiID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against appraisal kpi id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	if ok := s.checkMembership(c, appraisalKpi.AppraisalID, appraisalKpi.EmployeeID, tokenInfo.EmpID); !ok {
+		return
+	}
+
+	attachment := models.Attachment{
+		AppraisalKpiID: appraisalKpi.ID,
+		AppraisalID:    appraisalKpi.AppraisalID,
+		EmployeeID:     appraisalKpi.EmployeeID,
+		FileName:       fileName,
+		ContentType:    allowedType,
+		UploadedBy:     tokenInfo.EmpID,
+	}
+
+	dbAttachment, err := controller.CreateAttachment(ctx, s.Db.WithContext(ctx), &attachment, data)
+	if err != nil {
+		if errors.Is(err, storage.ErrInfected) {
+			respondError(c, apperrors.Wrap(apperrors.KindUnprocessable, "", err))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbAttachment)
+}
+
+// @summary List the attachments of an appraisal kpi
+// @query appraisal_kpi_id integer Appraisal kpi of the attachments
+// @success 200 []models.Attachment
+// @auth
+func (s *AttachmentService) GetAttachments(c *gin.Context) {
+	log.Info("Initializing GetAttachments handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalKpiID, err := strconv.ParseUint(c.Query("appraisal_kpi_id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Invalid("invalid appraisal_kpi_id"))
+		return
+	}
+
+	var appraisalKpi models.AppraisalKpi
+	if err := s.Db.WithContext(ctx).Model(&models.AppraisalKpi{}).First(&appraisalKpi, appraisalKpiID).Error; err != nil {
+		log.Error(err.Error())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against appraisal kpi id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	if ok := s.checkMembership(c, appraisalKpi.AppraisalID, appraisalKpi.EmployeeID, tokenInfo.EmpID); !ok {
+		return
+	}
+
+	attachments := make([]models.Attachment, 0)
+	if err := controller.GetAttachmentsByKpiID(s.Db.WithContext(ctx), &attachments, appraisalKpiID); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// @summary Get an attachment
+// @success 200 models.Attachment
+// @auth
+func (s *AttachmentService) GetAttachmentByID(c *gin.Context) {
+	log.Info("Initializing GetAttachmentByID handler function...")
+
+	attachment, ok := s.findAttachment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// DownloadAttachment streams the file of the attachment to a member of its
+// appraisal
+//
+// @summary Download the file of an attachment
+// @success 200 file application/octet-stream
+// @auth
+func (s *AttachmentService) DownloadAttachment(c *gin.Context) {
+	log.Info("Initializing DownloadAttachment handler function...")
+
+	attachment, ok := s.findAttachment(c)
+	if !ok {
+		return
+	}
+
+	file, err := controller.OpenAttachment(c.Request.Context(), &attachment)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("file of the attachment not found"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, file, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", attachment.FileName),
+	})
+}
+
+// DeleteAttachment removes an attachment. Only its uploader can remove it.
+//
+// @summary Delete an attachment
+// @success 204
+// @auth
+func (s *AttachmentService) DeleteAttachment(c *gin.Context) {
+	log.Info("Initializing DeleteAttachment handler function...")
+
+	ctx := c.Request.Context()
+
+	attachment, ok := s.findAttachment(c)
+	if !ok {
+		return
+	}
+
+	tokenInfo, _ := getTokenInfo(c)
+	if attachment.UploadedBy != tokenInfo.EmpID {
+		respondError(c, apperrors.Forbidden("only the uploader can delete the attachment"))
+		return
+	}
+
+	if err := controller.DeleteAttachment(ctx, s.Db.WithContext(ctx), &attachment); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// findAttachment returns the attachment of the id param if the caller is a
+// member of its appraisal. It writes the error response itself.
+func (s *AttachmentService) findAttachment(c *gin.Context) (models.Attachment, bool) {
+	ctx := c.Request.Context()
+	var attachment models.Attachment
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return attachment, false
+	}
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err := controller.GetAttachmentByID(s.Db.WithContext(ctx), &attachment, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against attachment id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return attachment, false
+	}
+
+	if ok := s.checkMembership(c, attachment.AppraisalID, attachment.EmployeeID, tokenInfo.EmpID); !ok {
+		return attachment, false
+	}
+
+	return attachment, true
+}
+
+// checkMembership writes a forbidden response if the user is not a member of
+// the appraisal of the employee
+func (s *AttachmentService) checkMembership(c *gin.Context, appraisalID, employeeID, userID uint16) bool {
+	ctx := c.Request.Context()
+	isMember, err := controller.IsAppraisalMember(s.Db.WithContext(ctx), appraisalID, employeeID, userID)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return false
+	}
+	if !isMember {
+		respondError(c, apperrors.Forbidden("you are not a member of this appraisal"))
+		return false
+	}
+
+	return true
+}
+
+// attachmentMaxSize returns the maximum size of an attachment in bytes
+func attachmentMaxSize() int64 {
+	sizeMB, err := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_SIZE_MB"), 10, 64)
+	if err != nil || sizeMB <= 0 {
+		sizeMB = constants.ATTACHMENT_MAX_SIZE_MB
+	}
+
+	return sizeMB << 20
+}
+
+// contentMatchesType sniffs the content of the file to check it against the type
+// of its extension. Office documents are zip archives and text files sniff as
+// plain text.
+func contentMatchesType(data []byte, contentType string) bool {
+	detected := http.DetectContentType(data)
+	switch {
+	case strings.HasPrefix(contentType, "application/vnd.openxmlformats"):
+		return detected == "application/zip"
+	case strings.HasPrefix(contentType, "text/"):
+		return strings.HasPrefix(detected, "text/plain")
+	}
+
+	return strings.HasPrefix(detected, contentType)
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+type CalibrationService struct {
+	Db *gorm.DB
+}
+
+func NewCalibrationService() *CalibrationService {
+	db := database.DB
+	err := db.AutoMigrate(&models.CalibrationSession{}, &models.ScoreAdjustment{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &CalibrationService{Db: db}
+}
+
+// CreateCalibrationSession lets HR group appraisals for calibration. The
+// creator is the caller.
+//
+// @summary Create a calibration session
+// @body models.CalibrationSession
+// @success 201 models.CalibrationSession
+// @auth
+func (s *CalibrationService) CreateCalibrationSession(c *gin.Context) {
+	log.Info("Initializing CreateCalibrationSession handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getHRTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	var session models.CalibrationSession
+	if err := c.ShouldBindJSON(&session); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, session.Validate()); !ok {
+		return
+	}
+	session.ID = 0
+	session.Adjustments = nil
+	session.CreatedBy = tokenInfo.EmpID
+
+	dbSession, err := controller.CreateCalibrationSession(s.Db.WithContext(ctx), &session)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbSession)
+}
+
+// @summary List the calibration sessions
+// @query session_name string Name of the sessions
+// @query is_closed boolean Whether the sessions are closed
+// @success 200 []models.CalibrationSession
+func (s *CalibrationService) GetAllCalibrationSessions(c *gin.Context) {
+	log.Info("Initializing GetAllCalibrationSessions handler function...")
+
+	ctx := c.Request.Context()
+
+	var sessions []models.CalibrationSession
+	db := s.Db.WithContext(ctx).Model(&models.CalibrationSession{})
+
+	sessionName := c.Query("session_name")
+	isClosed := c.Query("is_closed")
+
+	if sessionName != "" {
+		db = db.Where("session_name LIKE ?", "%"+sessionName+"%")
+	}
+
+	if isClosed != "" {
+		db = db.Where("is_closed = ?", isClosed)
+	}
+
+	if err := controller.GetAllCalibrationSessions(db, &sessions); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// @summary Get a calibration session
+// @success 200 models.CalibrationSession
+func (s *CalibrationService) GetCalibrationSessionByID(c *gin.Context) {
+	log.Info("Initializing GetCalibrationSessionByID handler function...")
+
+	session, ok := s.findSession(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// UpdateCalibrationSession lets HR change or close an open calibration session
+//
+// @summary Update a calibration session
+// @body models.CalibrationSession
+// @success 200 models.CalibrationSession
+// @auth
+func (s *CalibrationService) UpdateCalibrationSession(c *gin.Context) {
+	log.Info("Initializing UpdateCalibrationSession handler function...")
+
+	ctx := c.Request.Context()
+
+	if _, ok := getHRTokenInfo(c); !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	var session models.CalibrationSession
+	if err := c.ShouldBindJSON(&session); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, session.Validate()); !ok {
+		return
+	}
+	session.ID = uint16(id)
+	session.Adjustments = nil
+
+	dbSession, err := controller.UpdateCalibrationSession(s.Db.WithContext(ctx), &session)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbSession)
+}
+
+// @summary Delete a calibration session
+// @success 204
+// @auth
+func (s *CalibrationService) DeleteCalibrationSession(c *gin.Context) {
+	log.Info("Initializing DeleteCalibrationSession handler function...")
+
+	ctx := c.Request.Context()
+
+	if _, ok := getHRTokenInfo(c); !ok {
+		return
+	}
+
+	session, ok := s.findSession(c)
+	if !ok {
+		return
+	}
+
+	if err := controller.DeleteCalibrationSession(s.Db.WithContext(ctx), &session); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetCalibrationEmployees lists the computed and final score of every employee
+// grouped by the session
+//
+// @summary List the computed and final scores of the employees of a calibration session
+// @success 200 []models.EmployeeResult
+func (s *CalibrationService) GetCalibrationEmployees(c *gin.Context) {
+	log.Info("Initializing GetCalibrationEmployees handler function...")
+
+	ctx := c.Request.Context()
+
+	session, ok := s.findSession(c)
+	if !ok {
+		return
+	}
+
+	results := make([]models.EmployeeResult, 0)
+	if err := controller.GetCalibrationResults(s.Db.WithContext(ctx), &session, &results); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// @summary Compare the score distributions of the supervisors of a calibration session
+// @success 200 []models.SupervisorDistribution
+func (s *CalibrationService) GetCalibrationDistribution(c *gin.Context) {
+	log.Info("Initializing GetCalibrationDistribution handler function...")
+
+	ctx := c.Request.Context()
+
+	session, ok := s.findSession(c)
+	if !ok {
+		return
+	}
+
+	distribution := make([]models.SupervisorDistribution, 0)
+	if err := controller.GetSupervisorDistribution(s.Db.WithContext(ctx), &session, &distribution); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, distribution)
+}
+
+// AdjustScore lets HR override the final score of an employee. The adjustment
+// is made by the caller.
+//
+// @summary Adjust the final score of an employee in a calibration session
+// @body models.ScoreAdjustment
+// @success 201 models.ScoreAdjustment
+// @auth
+func (s *CalibrationService) AdjustScore(c *gin.Context) {
+	log.Info("Initializing AdjustScore handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getHRTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	session, ok := s.findSession(c)
+	if !ok {
+		return
+	}
+
+	var adjustment models.ScoreAdjustment
+	if err := c.ShouldBindJSON(&adjustment); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, adjustment.Validate()); !ok {
+		return
+	}
+	adjustment.ID = 0
+	adjustment.AdjustedBy = tokenInfo.EmpID
+
+	dbAdjustment, err := controller.AdjustCalibratedScore(s.Db.WithContext(ctx), &session, &adjustment)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbAdjustment)
+}
+
+// findSession loads the calibration session of the id path param and writes
+// the error response itself
+func (s *CalibrationService) findSession(c *gin.Context) (models.CalibrationSession, bool) {
+	ctx := c.Request.Context()
+	var session models.CalibrationSession
+
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return session, false
+	}
+
+	err = controller.GetCalibrationSessionByID(s.Db.WithContext(ctx), &session, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against calibration session id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return session, false
+	}
+
+	return session, true
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	"github.com/mrehanabbasi/appraisal-system-backend/domain"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type CampaignService struct {
+	Db         *gorm.DB
+	appraisals *domain.AppraisalService
+}
+
+func NewCampaignService() *CampaignService {
+	db := database.DB
+	err := db.AutoMigrate(&models.Campaign{}, &models.CampaignSkippedTeam{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &CampaignService{Db: db, appraisals: domain.NewAppraisalService(db)}
+}
+
+// CreateCampaign queues the creation of an active team appraisal for every team
+// in TOSS, or for the listed teams. Teams without a supervisor, employees or kpis
+// are skipped with the reason, while failures to reach TOSS abort the campaign.
+//
+// @summary Queue the creation of a team appraisal for every team
+// @body models.Campaign
+// @success 202 models.Job
+// @auth
+func (s *CampaignService) CreateCampaign(c *gin.Context) {
+	log.Info("Initializing CreateCampaign handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getHRTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	var campaign models.Campaign
+	if err := c.ShouldBindJSON(&campaign); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, campaign.Validate()); !ok {
+		return
+	}
+	campaign.ID = 0
+	campaign.Appraisals = nil
+	campaign.SkippedTeams = nil
+	campaign.CreatedBy = tokenInfo.EmpID
+
+	if campaign.DueDate != nil && campaign.DueDate.Before(time.Now()) {
+		respondError(c, apperrors.Invalid("due_date should be in the future"))
+		return
+	}
+
+	if err := domain.CheckAppraisalType(s.Db.WithContext(ctx), campaign.AppraisalTypeStr); err != nil {
+		respondError(c, apperrors.Invalid("invalid appraisal type"))
+		return
+	}
+
+	var appraisalFlow models.AppraisalFlow
+	if err := s.Db.WithContext(ctx).Model(&models.AppraisalFlow{}).First(&appraisalFlow, campaign.AppraisalFlowID).Error; err != nil {
+		respondError(c, apperrors.Invalid("invalid appraisal flow ID"))
+		return
+	}
+
+	// Creating the appraisal of a team takes several TOSS calls per employee, so
+	// the campaign is created by a background job
+	job, err := enqueueJob(ctx, constants.JOB_TYPE_CREATE_CAMPAIGN, campaign)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	respondJobAccepted(c, job)
+}
+
+// RunCreateCampaign is the job creating the campaign queued by CreateCampaign.
+// Every team is an item of the job, and the skipped teams are its failed items.
+func (s *CampaignService) RunCreateCampaign(ctx context.Context, job *models.Job, progress *controller.JobProgress) error {
+	var campaign models.Campaign
+	if err := json.Unmarshal([]byte(job.Payload), &campaign); err != nil {
+		return err
+	}
+
+	var assignType models.AssignType
+	if err := s.Db.WithContext(ctx).Where("assign_type = ?", constants.ASSIGN_TYPE_TEAM).First(&assignType).Error; err != nil {
+		log.Error(err.Error())
+		return errors.New("team assign type not found")
+	}
+
+	projects, err := utils.GetTossProjects(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return errors.New("failed to fetch teams")
+	}
+	projects, err = campaignTeams(projects, campaign.TeamIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := progress.SetTotal(len(projects)); err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		teamName := strings.Trim(project.ProjectName, "\r\n")
+		skip := func(reason string) error {
+			campaign.SkippedTeams = append(campaign.SkippedTeams, models.CampaignSkippedTeam{
+				TeamID:   project.ProjectID,
+				TeamName: teamName,
+				Reason:   reason,
+			})
+			return progress.ItemFailed(fmt.Sprintf("team %d %s", project.ProjectID, teamName), reason)
+		}
+
+		supervisorID, supervisorName, err := utils.GetTeamSupervisor(project)
+		if err != nil {
+			if err := skip(err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		status := true
+		appraisal := models.Appraisal{
+			AppraisalName:    fmt.Sprintf("%s - %s", campaign.CampaignName, teamName),
+			AppraisalYear:    campaign.AppraisalYear,
+			AppraisalTypeStr: campaign.AppraisalTypeStr,
+			SupervisorID:     supervisorID,
+			SupervisorName:   supervisorName,
+			AppraisalFlowID:  campaign.AppraisalFlowID,
+			AppraisalFor:     assignType.AssignTypeId,
+			AppraisalForName: constants.ASSIGN_TYPE_TEAM,
+			SelectedFieldID:  project.ProjectID,
+			Status:           &status,
+			DueDate:          campaign.DueDate,
+		}
+
+		// Failures to reach TOSS abort the campaign, other failures skip the team
+		err = s.appraisals.Build(ctx, &appraisal)
+		if kind := apperrors.KindOf(err); err != nil && (kind == apperrors.KindInternal || kind == apperrors.KindUpstream) {
+			return fmt.Errorf("team %d: %s", project.ProjectID, err.Error())
+		}
+		if err == nil && len(appraisal.EmployeesList) == 0 {
+			err = errors.New("team has no employees")
+		}
+		if err != nil {
+			if err := skip(err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		campaign.Appraisals = append(campaign.Appraisals, appraisal)
+		if err := progress.ItemSucceeded(); err != nil {
+			return err
+		}
+	}
+
+	if len(campaign.Appraisals) == 0 {
+		return errors.New("no team of the campaign can be appraised")
+	}
+
+	dbCampaign, err := controller.CreateCampaign(s.Db.WithContext(ctx), &campaign)
+	if err != nil {
+		return err
+	}
+
+	return progress.SetResult(dbCampaign.ID, fmt.Sprintf("/v1/campaigns/%d", dbCampaign.ID))
+}
+
+// campaignTeams narrows the projects down to the teams of the campaign, all of
+// them if none are listed
+func campaignTeams(projects []utils.ProjectResponse, teamIDs []int64) ([]utils.ProjectResponse, error) {
+	if len(teamIDs) == 0 {
+		return projects, nil
+	}
+
+	byID := make(map[int64]utils.ProjectResponse, len(projects))
+	for _, project := range projects {
+		byID[int64(project.ProjectID)] = project
+	}
+
+	teams := make([]utils.ProjectResponse, 0, len(teamIDs))
+	for _, teamID := range teamIDs {
+		project, ok := byID[teamID]
+		if !ok {
+			return nil, fmt.Errorf("invalid team ID %d", teamID)
+		}
+		teams = append(teams, project)
+	}
+
+	return teams, nil
+}
+
+// @summary List the campaigns
+// @query appraisal_year integer Year of the appraisal cycle
+// @query appraisal_type string Type of the appraisal cycle
+// @success 200 []models.Campaign
+func (s *CampaignService) GetAllCampaigns(c *gin.Context) {
+	log.Info("Initializing GetAllCampaigns handler function...")
+
+	ctx := c.Request.Context()
+
+	campaigns := make([]models.Campaign, 0)
+	db := s.Db.WithContext(ctx).Model(&models.Campaign{})
+
+	appraisalYear := c.Query("appraisal_year")
+	appraisalType := c.Query("appraisal_type")
+
+	if appraisalYear != "" {
+		db = db.Where("appraisal_year = ?", appraisalYear)
+	}
+
+	if appraisalType != "" {
+		db = db.Where("appraisal_type_str = ?", appraisalType)
+	}
+
+	if err := controller.GetAllCampaigns(db, &campaigns); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, campaigns)
+}
+
+// @summary Get a campaign
+// @success 200 models.Campaign
+func (s *CampaignService) GetCampaignByID(c *gin.Context) {
+	log.Info("Initializing GetCampaignByID handler function...")
+
+	ctx := c.Request.Context()
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var campaign models.Campaign
+	err := controller.GetCampaignByID(s.Db.WithContext(ctx), &campaign, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against campaign id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
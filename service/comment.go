@@ -0,0 +1,489 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type CommentService struct {
+	Db *gorm.DB
+}
+
+func NewCommentService() *CommentService {
+	db := database.DB
+	err := db.AutoMigrate(&models.Comment{}, &models.CommentRevision{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &CommentService{Db: db}
+}
+
+// CreateComment posts a comment of the caller. The role of the author is taken
+// from the jwt: HR, the employee the comment is about, or one of their
+// evaluators.
+//
+// @summary Comment on a kpi or a score
+// @body models.Comment
+// @success 201 models.Comment
+// @auth
+func (s *CommentService) CreateComment(c *gin.Context) {
+	log.Info("Initializing CreateComment handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	var comment models.Comment
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+	comment.AuthorID = tokenInfo.EmpID
+
+	if comment.Visibility == "" {
+		comment.Visibility = constants.COMMENT_VISIBILITY_SHARED
+	}
+
+	// A reply is about the kpi or score of its parent
+	if comment.ParentID != nil {
+		parent, ok := s.loadComment(c, tokenInfo, uint64(*comment.ParentID))
+		if !ok {
+			return
+		}
+		comment.AppraisalKpiID, comment.ScoreID = parent.AppraisalKpiID, parent.ScoreID
+	}
+
+	if err := controller.ResolveCommentTarget(s.Db.WithContext(ctx), &comment); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	switch {
+	case tokenInfo.IsHR():
+		comment.AuthorRole = constants.COMMENT_ROLE_HR
+	case tokenInfo.EmpID == comment.EmployeeID:
+		comment.AuthorRole = constants.COMMENT_ROLE_EMPLOYEE
+	default:
+		isMember, err := controller.IsAppraisalMember(s.Db.WithContext(ctx), comment.AppraisalID, comment.EmployeeID, tokenInfo.EmpID)
+		if err != nil {
+			respondError(c, apperrors.Internal(err))
+			return
+		}
+		if !isMember {
+			respondError(c, apperrors.Forbidden("you are not a member of this appraisal"))
+			return
+		}
+		comment.AuthorRole = constants.COMMENT_ROLE_SUPERVISOR
+	}
+
+	if ok := validateStruct(c, comment.Validate()); !ok {
+		return
+	}
+
+	if comment.Visibility == constants.COMMENT_VISIBILITY_HR_PRIVATE && comment.AuthorRole != constants.COMMENT_ROLE_HR {
+		respondError(c, apperrors.Forbidden("only hr can post comments private to hr"))
+		return
+	}
+
+	s.createComment(c, &comment)
+}
+
+// GetAllComments lists the comments selected by the query. Comments private to
+// HR are only listed to HR.
+//
+// @summary List the comments
+// @query appraisal_id integer Appraisal of the comments
+// @query employee_id integer Employee the comments are about
+// @query appraisal_kpi_id integer Appraisal kpi the comments are on
+// @query score_id integer Score the comments are on
+// @query visibility string Either shared or hr_private
+// @success 200 []models.Comment
+// @auth
+func (s *CommentService) GetAllComments(c *gin.Context) {
+	log.Info("Initializing GetAllComments handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	comments := make([]models.Comment, 0)
+	db := s.Db.WithContext(ctx).Model(&models.Comment{})
+
+	appraisalID := c.Query("appraisal_id")
+	employeeID := c.Query("employee_id")
+	appraisalKpiID := c.Query("appraisal_kpi_id")
+	scoreID := c.Query("score_id")
+	visibility := c.Query("visibility")
+
+	if appraisalID != "" {
+		db = db.Where("appraisal_id = ?", appraisalID)
+	}
+
+	if employeeID != "" {
+		db = db.Where("employee_id = ?", employeeID)
+	}
+
+	if appraisalKpiID != "" {
+		db = db.Where("appraisal_kpi_id = ?", appraisalKpiID)
+	}
+
+	if scoreID != "" {
+		db = db.Where("score_id = ?", scoreID)
+	}
+
+	if visibility != "" {
+		db = db.Where("visibility = ?", visibility)
+	}
+
+	if !tokenInfo.IsHR() {
+		db = db.Where("visibility = ?", constants.COMMENT_VISIBILITY_SHARED)
+	}
+
+	if err := controller.GetComments(db, &comments); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// @summary Get a comment
+// @success 200 models.Comment
+// @auth
+func (s *CommentService) GetCommentByID(c *gin.Context) {
+	log.Info("Initializing GetCommentByID handler function...")
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := s.findComment(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// @summary Edit a comment
+// @body models.CommentEdit
+// @success 200 models.Comment
+// @auth
+func (s *CommentService) EditComment(c *gin.Context) {
+	log.Info("Initializing EditComment handler function...")
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := s.findComment(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	var edit models.CommentEdit
+	if err := c.ShouldBindJSON(&edit); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+	edit.AuthorID = tokenInfo.EmpID
+
+	s.editComment(c, &comment, &edit)
+}
+
+// DeleteComment deletes a comment. Only its author and HR can delete it.
+//
+// @summary Delete a comment
+// @success 204
+// @auth
+func (s *CommentService) DeleteComment(c *gin.Context) {
+	log.Info("Initializing DeleteComment handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := s.findComment(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	if comment.AuthorID != tokenInfo.EmpID && !tokenInfo.IsHR() {
+		respondError(c, apperrors.Forbidden("only the author or hr can delete the comment"))
+		return
+	}
+
+	if err := controller.DeleteComment(s.Db.WithContext(ctx), &comment); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetMyComments lists the threads shared with the caller on their appraisal
+//
+// @summary List the comments shared with the caller on their appraisal
+// @success 200 []models.Comment
+// @auth
+func (s *CommentService) GetMyComments(c *gin.Context) {
+	log.Info("Initializing GetMyComments handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	comments := make([]models.Comment, 0)
+	db := s.Db.WithContext(ctx).Model(&models.Comment{}).
+		Where("appraisal_id = ? AND employee_id = ? AND visibility = ?", appraisalID, tokenInfo.EmpID, constants.COMMENT_VISIBILITY_SHARED)
+
+	if err := controller.GetComments(db, &comments); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateMyComment posts a comment of the caller on their own kpis or scores. The
+// comments of employees are always shared.
+//
+// @summary Comment on the kpis or scores of the caller
+// @body models.Comment
+// @success 201 models.Comment
+// @auth
+func (s *CommentService) CreateMyComment(c *gin.Context) {
+	log.Info("Initializing CreateMyComment handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	var comment models.Comment
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+	comment.AuthorID = tokenInfo.EmpID
+	comment.AuthorRole = constants.COMMENT_ROLE_EMPLOYEE
+	comment.Visibility = constants.COMMENT_VISIBILITY_SHARED
+
+	if ok := validateStruct(c, comment.Validate()); !ok {
+		return
+	}
+
+	// Employees only comment on their own appraisal. Replying to a private thread
+	// would give away its existence.
+	if comment.ParentID != nil {
+		var parent models.Comment
+		err := controller.GetCommentByID(s.Db.WithContext(ctx), &parent, uint64(*comment.ParentID))
+		if err != nil || parent.Visibility != constants.COMMENT_VISIBILITY_SHARED || parent.EmployeeID != tokenInfo.EmpID {
+			respondError(c, apperrors.Invalid("parent comment not found"))
+			return
+		}
+	} else {
+		err := controller.ResolveCommentTarget(s.Db.WithContext(ctx), &comment)
+		if err != nil {
+			respondError(c, apperrors.Internal(err))
+			return
+		}
+		if comment.EmployeeID != tokenInfo.EmpID {
+			respondError(c, apperrors.NotFound("appraisal kpi not found"))
+			return
+		}
+	}
+
+	s.createComment(c, &comment)
+}
+
+// @summary Edit a comment of the caller
+// @body models.CommentEdit
+// @success 200 models.Comment
+// @auth
+func (s *CommentService) EditMyComment(c *gin.Context) {
+	log.Info("Initializing EditMyComment handler function...")
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := s.findComment(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	var edit models.CommentEdit
+	if err := c.ShouldBindJSON(&edit); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+	edit.AuthorID = tokenInfo.EmpID
+
+	s.editComment(c, &comment, &edit)
+}
+
+// GetMyMentions lists the shared comments mentioning the caller
+//
+// @summary List the shared comments mentioning the caller
+// @success 200 []models.Comment
+// @auth
+func (s *CommentService) GetMyMentions(c *gin.Context) {
+	log.Info("Initializing GetMyMentions handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	comments := make([]models.Comment, 0)
+	if err := controller.GetMentions(s.Db.WithContext(ctx), &comments, tokenInfo.EmpID, false); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// createComment checks the author and the mentions against TOSS and stores the
+// comment
+func (s *CommentService) createComment(c *gin.Context, comment *models.Comment) {
+	ctx := c.Request.Context()
+	comment.ID = 0
+	comment.Edited = false
+	comment.Revisions = nil
+	comment.Replies = nil
+
+	errCode, err := utils.CheckIndividualAgainstToss(ctx, comment.AuthorID)
+	if err != nil {
+		respondError(c, apperrors.FromStatus(errCode, err))
+		return
+	}
+
+	authorName, err := utils.GetEmployeeName(ctx, comment.AuthorID)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+	comment.AuthorName = authorName
+
+	if ok := checkMentions(c, comment.Mentions); !ok {
+		return
+	}
+
+	dbComment, err := controller.CreateComment(s.Db.WithContext(ctx), comment)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbComment)
+}
+
+func (s *CommentService) editComment(c *gin.Context, comment *models.Comment, edit *models.CommentEdit) {
+	ctx := c.Request.Context()
+	if ok := validateStruct(c, edit.Validate()); !ok {
+		return
+	}
+
+	if ok := checkMentions(c, edit.Mentions); !ok {
+		return
+	}
+
+	dbComment, err := controller.EditComment(s.Db.WithContext(ctx), comment, edit)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbComment)
+}
+
+// checkMentions makes sure every mentioned employee exists in TOSS. It writes the
+// error response itself.
+func checkMentions(c *gin.Context, mentions []int64) bool {
+	ctx := c.Request.Context()
+	for _, empID := range mentions {
+		if empID <= 0 || empID > 65535 {
+			errMsg := fmt.Sprintf("invalid mention :%v", empID)
+			respondError(c, apperrors.Invalid(errMsg))
+			return false
+		}
+
+		errCode, err := utils.CheckIndividualAgainstToss(ctx, uint16(empID))
+		if err != nil {
+			respondError(c, apperrors.FromStatus(errCode, err))
+			return false
+		}
+	}
+
+	return true
+}
+
+// findComment loads the comment of the id path param and writes the error
+// response itself
+func (s *CommentService) findComment(c *gin.Context, tokenInfo models.TokenInfo) (models.Comment, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return models.Comment{}, false
+	}
+
+	return s.loadComment(c, tokenInfo, id)
+}
+
+// loadComment loads a comment seen by the caller and writes the error response
+// itself. Comments private to HR are not found by anyone else.
+func (s *CommentService) loadComment(c *gin.Context, tokenInfo models.TokenInfo, id uint64) (models.Comment, bool) {
+	ctx := c.Request.Context()
+	var comment models.Comment
+
+	err := controller.GetCommentByID(s.Db.WithContext(ctx), &comment, id)
+	if err == nil && comment.Visibility == constants.COMMENT_VISIBILITY_HR_PRIVATE && !tokenInfo.IsHR() {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against comment id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return comment, false
+	}
+
+	return comment, true
+}
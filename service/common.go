@@ -0,0 +1,76 @@
+package service
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/domain"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// validateStruct records the result of a model's Validate method as the error
+// of the request. It returns false if the validation failed.
+func validateStruct(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	respondError(c, apperrors.Validation(err))
+	return false
+}
+
+// respondError records the error the handler failed with. The Problems
+// middleware writes the response for it.
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+}
+
+// parseAppraisalCycle reads the appraisal_year and appraisal_type query params
+// identifying an appraisal cycle. It writes the error response itself.
+func parseAppraisalCycle(c *gin.Context, db *gorm.DB) (uint16, string, bool) {
+	appraisalYear, err := strconv.ParseUint(c.Query("appraisal_year"), 10, 16)
+	if err != nil {
+		respondError(c, apperrors.Invalid("invalid appraisal_year"))
+		return 0, "", false
+	}
+
+	appraisalType := c.Query("appraisal_type")
+	if err := domain.CheckAppraisalType(db, appraisalType); err != nil {
+		respondError(c, apperrors.Invalid("invalid appraisal type"))
+		return 0, "", false
+	}
+
+	return uint16(appraisalYear), appraisalType, true
+}
+
+// getTokenInfo returns the identity of the caller set by the ValidateJWTClaims
+// middleware. It writes the error response itself.
+func getTokenInfo(c *gin.Context) (models.TokenInfo, bool) {
+	value, exists := c.Get(constants.TOKEN_DATA)
+	tokenInfo, ok := value.(models.TokenInfo)
+	if !exists || !ok || tokenInfo.EmpID == 0 {
+		respondError(c, apperrors.Unauthorized("unauthorized"))
+		return tokenInfo, false
+	}
+
+	return tokenInfo, true
+}
+
+// getHRTokenInfo returns the identity of the caller like getTokenInfo, and
+// refuses the callers outside of HR. It writes the error response itself.
+func getHRTokenInfo(c *gin.Context) (models.TokenInfo, bool) {
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return tokenInfo, false
+	}
+
+	if !tokenInfo.IsHR() {
+		respondError(c, apperrors.Forbidden("only hr can perform this action"))
+		return tokenInfo, false
+	}
+
+	return tokenInfo, true
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+)
+
+// GetDashboard returns the progress of an appraisal cycle: employees per
+// appraisal status, scored and outstanding kpis and the overdue evaluators
+//
+// @summary Get the progress of an appraisal cycle
+// @query appraisal_year integer Year of the appraisal cycle
+// @query appraisal_type string Type of the appraisal cycle
+// @success 200 models.CycleDashboard
+func (s *AnalyticsService) GetDashboard(c *gin.Context) {
+	log.Info("Initializing GetDashboard handler function...")
+
+	ctx := c.Request.Context()
+
+	appraisalYear, appraisalType, ok := parseAppraisalCycle(c, s.Db.WithContext(ctx))
+	if !ok {
+		return
+	}
+
+	dashboard, err := controller.GetCycleDashboard(s.Db.WithContext(ctx), appraisalYear, appraisalType)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// @summary List the scoring completion of an appraisal cycle per team
+// @query appraisal_year integer Year of the appraisal cycle
+// @query appraisal_type string Type of the appraisal cycle
+// @success 200 []models.CompletionStat
+func (s *AnalyticsService) GetTeamCompletion(c *gin.Context) {
+	log.Info("Initializing GetTeamCompletion handler function...")
+
+	s.getCompletionStats(c, constants.GROUP_BY_TEAM)
+}
+
+// @summary List the scoring completion of an appraisal cycle per supervisor
+// @query appraisal_year integer Year of the appraisal cycle
+// @query appraisal_type string Type of the appraisal cycle
+// @success 200 []models.CompletionStat
+func (s *AnalyticsService) GetSupervisorCompletion(c *gin.Context) {
+	log.Info("Initializing GetSupervisorCompletion handler function...")
+
+	s.getCompletionStats(c, constants.GROUP_BY_SUPERVISOR)
+}
+
+func (s *AnalyticsService) getCompletionStats(c *gin.Context, groupBy string) {
+	ctx := c.Request.Context()
+	appraisalYear, appraisalType, ok := parseAppraisalCycle(c, s.Db.WithContext(ctx))
+	if !ok {
+		return
+	}
+
+	stats := make([]models.CompletionStat, 0)
+	if err := controller.GetCompletionStats(s.Db.WithContext(ctx), appraisalYear, appraisalType, groupBy, &stats); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
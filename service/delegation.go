@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type DelegationService struct {
+	Db *gorm.DB
+}
+
+func NewDelegationService() *DelegationService {
+	db := database.DB
+	err := db.AutoMigrate(&models.Delegation{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &DelegationService{Db: db}
+}
+
+// CreateDelegation hands the evaluator rights of the caller over to the delegate
+//
+// @summary Delegate the evaluator rights of the caller
+// @body models.Delegation
+// @success 201 models.Delegation
+// @auth
+func (s *DelegationService) CreateDelegation(c *gin.Context) {
+	log.Info("Initializing CreateDelegation handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	var delegation models.Delegation
+	if err := c.ShouldBindJSON(&delegation); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, delegation.Validate()); !ok {
+		return
+	}
+	delegation.ID = 0
+	delegation.DelegatorID = tokenInfo.EmpID
+	isRevoked := false
+	delegation.IsRevoked = &isRevoked
+
+	if delegation.DelegatorID == delegation.DelegateID {
+		respondError(c, apperrors.Invalid("delegate_id should be different from delegator_id"))
+		return
+	}
+
+	if !delegation.EndDate.After(delegation.StartDate) {
+		respondError(c, apperrors.Invalid("end_date should be after start_date"))
+		return
+	}
+
+	if !delegation.EndDate.After(time.Now()) {
+		respondError(c, apperrors.Invalid("end_date should be in the future"))
+		return
+	}
+
+	for _, empID := range []uint16{delegation.DelegatorID, delegation.DelegateID} {
+		errCode, err := utils.CheckIndividualAgainstToss(ctx, empID)
+		if err != nil {
+			respondError(c, apperrors.FromStatus(errCode, err))
+			return
+		}
+	}
+
+	delegatorName, err := utils.GetEmployeeName(ctx, delegation.DelegatorID)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+	delegateName, err := utils.GetEmployeeName(ctx, delegation.DelegateID)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+	delegation.DelegatorName = delegatorName
+	delegation.DelegateName = delegateName
+
+	dbDelegation, err := controller.CreateDelegation(s.Db.WithContext(ctx), &delegation)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbDelegation)
+}
+
+// @summary List the delegations
+// @query delegator_id integer Supervisor who delegated
+// @query delegate_id integer Employee delegated to
+// @query active boolean Only the delegations in effect
+// @success 200 []models.Delegation
+func (s *DelegationService) GetAllDelegations(c *gin.Context) {
+	log.Info("Initializing GetAllDelegations handler function...")
+
+	ctx := c.Request.Context()
+
+	var delegations []models.Delegation
+	db := s.Db.WithContext(ctx).Model(&models.Delegation{})
+
+	delegatorID := c.Query("delegator_id")
+	delegateID := c.Query("delegate_id")
+	active := c.Query("active")
+
+	if delegatorID != "" {
+		db = db.Where("delegator_id = ?", delegatorID)
+	}
+
+	if delegateID != "" {
+		db = db.Where("delegate_id = ?", delegateID)
+	}
+
+	if active == "true" {
+		db = controller.ActiveDelegationsQuery(db, time.Now())
+	}
+
+	if err := controller.GetAllDelegations(db, &delegations); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, delegations)
+}
+
+// @summary Get a delegation
+// @success 200 models.Delegation
+func (s *DelegationService) GetDelegationByID(c *gin.Context) {
+	log.Info("Initializing GetDelegationByID handler function...")
+
+	delegation, ok := s.findDelegation(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, delegation)
+}
+
+// RevokeDelegation ends a delegation before its end date. Only the delegator and
+// HR can revoke it.
+//
+// @summary Revoke a delegation of the caller before its end date
+// @success 200 models.Delegation
+// @auth
+func (s *DelegationService) RevokeDelegation(c *gin.Context) {
+	log.Info("Initializing RevokeDelegation handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	delegation, ok := s.findDelegation(c)
+	if !ok {
+		return
+	}
+
+	if delegation.DelegatorID != tokenInfo.EmpID && !tokenInfo.IsHR() {
+		respondError(c, apperrors.Forbidden("only the delegator or hr can revoke the delegation"))
+		return
+	}
+
+	dbDelegation, err := controller.RevokeDelegation(s.Db.WithContext(ctx), &delegation)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbDelegation)
+}
+
+// findDelegation loads the delegation of the id path param and writes the error
+// response itself
+func (s *DelegationService) findDelegation(c *gin.Context) (models.Delegation, bool) {
+	ctx := c.Request.Context()
+	var delegation models.Delegation
+
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return delegation, false
+	}
+
+	err = controller.GetDelegationByID(s.Db.WithContext(ctx), &delegation, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against delegation id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return delegation, false
+	}
+
+	return delegation, true
+}
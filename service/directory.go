@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type DirectoryService struct {
+	Db *gorm.DB
+}
+
+// NewDirectoryService migrates the local mirror of the TOSS directory and makes
+// it the fallback of the TOSS functions
+func NewDirectoryService() *DirectoryService {
+	db := database.DB
+	err := db.AutoMigrate(&models.DirectoryEmployee{}, &models.DirectoryDesignation{}, &models.DirectoryProject{},
+		&models.DirectoryProjectMember{}, &models.DirectorySyncRun{}, &models.DirectoryChange{})
+	if err != nil {
+		panic(err)
+	}
+
+	utils.LocalDirectory = controller.LocalDirectory{Db: db}
+
+	return &DirectoryService{Db: db}
+}
+
+// StartPeriodicSync syncs the TOSS directory right away and then at every
+// TOSS_SYNC_INTERVAL_MINUTES, unless the interval is negative
+func (s *DirectoryService) StartPeriodicSync() {
+	minutes, err := strconv.Atoi(os.Getenv("TOSS_SYNC_INTERVAL_MINUTES"))
+	if err != nil || minutes == 0 {
+		minutes = constants.TOSS_SYNC_INTERVAL_MINUTES
+	}
+	if minutes < 0 {
+		log.Info("Periodic TOSS directory sync is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			if _, err := controller.SyncTossDirectory(context.Background(), s.Db); err != nil {
+				log.Error(err.Error())
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// SyncDirectory queues a sync of the TOSS directory right away. Fetching the
+// whole directory takes a while, so the sync is run by a background job.
+//
+// @summary Queue a sync of the TOSS directory right away
+// @success 202 models.Job
+func (s *DirectoryService) SyncDirectory(c *gin.Context) {
+	log.Info("Initializing SyncDirectory handler function...")
+
+	job, err := enqueueJob(c.Request.Context(), constants.JOB_TYPE_SYNC_DIRECTORY, struct{}{})
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	respondJobAccepted(c, job)
+}
+
+// RunSyncDirectory is the job syncing the directory queued by SyncDirectory. The
+// sync run is its result, and the job fails along with the run.
+func (s *DirectoryService) RunSyncDirectory(ctx context.Context, job *models.Job, progress *controller.JobProgress) error {
+	if err := progress.SetTotal(1); err != nil {
+		return err
+	}
+
+	run, err := controller.SyncTossDirectory(ctx, s.Db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if err := progress.SetResult(run.ID, "/v1/directory/sync_runs"); err != nil {
+		return err
+	}
+	if run.Status == constants.SYNC_STATUS_FAILED {
+		return errors.New(run.Error)
+	}
+
+	return progress.ItemSucceeded()
+}
+
+// @summary List the last runs of the directory sync
+// @query status string Either running, succeeded or failed
+// @success 200 []models.DirectorySyncRun
+func (s *DirectoryService) GetSyncRuns(c *gin.Context) {
+	log.Info("Initializing GetSyncRuns handler function...")
+
+	ctx := c.Request.Context()
+
+	runs := make([]models.DirectorySyncRun, 0)
+	db := s.Db.WithContext(ctx).Model(&models.DirectorySyncRun{})
+
+	status := c.Query("status")
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if err := controller.GetDirectorySyncRuns(db.Limit(100), &runs); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetChanges lists the detected directory changes, filtered by entity, change
+// type and a from/to date range
+//
+// @summary List the detected directory changes
+// @query entity_type string Either employee, designation or project
+// @query entity_id integer TOSS id of the entity
+// @query change_type string Type of the changes
+// @query sync_run_id integer Sync run that detected the changes
+// @query from date First day of the changes
+// @query to date Last day of the changes
+// @success 200 []models.DirectoryChange
+func (s *DirectoryService) GetChanges(c *gin.Context) {
+	log.Info("Initializing GetChanges handler function...")
+
+	ctx := c.Request.Context()
+
+	changes := make([]models.DirectoryChange, 0)
+	db := s.Db.WithContext(ctx).Model(&models.DirectoryChange{})
+
+	entityType := c.Query("entity_type")
+	entityID := c.Query("entity_id")
+	changeType := c.Query("change_type")
+	syncRunID := c.Query("sync_run_id")
+
+	if entityType != "" {
+		db = db.Where("entity_type = ?", entityType)
+	}
+
+	if entityID != "" {
+		db = db.Where("entity_id = ?", entityID)
+	}
+
+	if changeType != "" {
+		db = db.Where("change_type = ?", changeType)
+	}
+
+	if syncRunID != "" {
+		db = db.Where("sync_run_id = ?", syncRunID)
+	}
+
+	for _, param := range []string{"from", "to"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			respondError(c, apperrors.Invalid(param+" should be a date in the YYYY-MM-DD format"))
+			return
+		}
+		if param == "from" {
+			db = db.Where("detected_at >= ?", date)
+		} else {
+			db = db.Where("detected_at < ?", date.AddDate(0, 0, 1))
+		}
+	}
+
+	if err := controller.GetDirectoryChanges(db, &changes); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// GetCycleChanges reports the joiners, leavers and team moves during an
+// appraisal cycle
+//
+// @summary Report the joiners, leavers and team moves during an appraisal cycle
+// @query appraisal_year integer Year of the appraisal cycle
+// @query appraisal_type string Type of the appraisal cycle
+// @success 200 models.CycleRosterChanges
+func (s *DirectoryService) GetCycleChanges(c *gin.Context) {
+	log.Info("Initializing GetCycleChanges handler function...")
+
+	ctx := c.Request.Context()
+
+	appraisalYear, appraisalType, ok := parseAppraisalCycle(c, s.Db.WithContext(ctx))
+	if !ok {
+		return
+	}
+
+	roster, err := controller.GetCycleRosterChanges(s.Db.WithContext(ctx), appraisalYear, appraisalType)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No appraisals found in the cycle"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, roster)
+}
+
+// @summary List the employees of the directory
+// @query is_active boolean Whether the employees are active
+// @query designation_id integer Designation of the employees
+// @success 200 []models.DirectoryEmployee
+func (s *DirectoryService) GetEmployees(c *gin.Context) {
+	log.Info("Initializing GetEmployees handler function...")
+
+	ctx := c.Request.Context()
+
+	employees := make([]models.DirectoryEmployee, 0)
+	db := s.Db.WithContext(ctx).Model(&models.DirectoryEmployee{})
+
+	isActive := c.Query("is_active")
+	designation := c.Query("designation_id")
+
+	if isActive != "" {
+		db = db.Where("is_active = ?", isActive == "true")
+	}
+
+	if designation != "" {
+		db = db.Where("designation = ?", designation)
+	}
+
+	if err := controller.GetDirectoryEmployees(db, &employees); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, employees)
+}
+
+// @summary List the projects of the directory
+// @query is_active boolean Whether the projects are active
+// @success 200 []models.DirectoryProject
+func (s *DirectoryService) GetProjects(c *gin.Context) {
+	log.Info("Initializing GetProjects handler function...")
+
+	ctx := c.Request.Context()
+
+	projects := make([]models.DirectoryProject, 0)
+	db := s.Db.WithContext(ctx).Model(&models.DirectoryProject{})
+
+	isActive := c.Query("is_active")
+	if isActive != "" {
+		db = db.Where("is_active = ?", isActive == "true")
+	}
+
+	if err := controller.GetDirectoryProjects(db, &projects); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	"github.com/mrehanabbasi/appraisal-system-backend/controller"
 	"github.com/mrehanabbasi/appraisal-system-backend/database"
 	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
@@ -29,14 +30,16 @@ func NewEmployeeService() *EmployeeService {
 
 // create employee
 
+// @summary Create an employee
+// @body models.Employee
+// @success 201 models.Employee
 func (ec *EmployeeService) CreateEmployee(c *gin.Context) {
 	log.Info("Initializing CreateEmployee handler function...")
 	var employee models.Employee
 
 	err := c.ShouldBindJSON(&employee)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 	if employee.Role == "" {
@@ -45,10 +48,9 @@ func (ec *EmployeeService) CreateEmployee(c *gin.Context) {
 	}
 	// If a role is provided in the request, check if it exists in the DB and assign RoleId from the database
 	if roleName := employee.Role; roleName != "" {
-		roleId, err := controller.GetRoleIdFromDb(ec.Db, roleName)
+		roleId, err := controller.GetRoleIdFromDb(ec.Db.WithContext(c.Request.Context()), roleName)
 		if err != nil {
-			log.Error(err.Error())
-			c.JSON(http.StatusBadRequest, err.Error())
+			respondError(c, apperrors.Internal(err))
 			return
 		}
 
@@ -56,18 +58,16 @@ func (ec *EmployeeService) CreateEmployee(c *gin.Context) {
 	}
 	// checking supervisor exist in employee table
 	if supID := employee.SupervisorID; supID != 0 {
-		err := controller.ChecKSupervisorExist(ec.Db, supID)
+		err := controller.ChecKSupervisorExist(ec.Db.WithContext(c.Request.Context()), supID)
 		if err != nil {
-			log.Error(err.Error())
-			c.JSON(http.StatusBadRequest, err.Error())
+			respondError(c, apperrors.Internal(err))
 			return
 		}
 	}
 
-	err = controller.CreateEmployee(ec.Db, &employee)
+	err = controller.CreateEmployee(ec.Db.WithContext(c.Request.Context()), &employee)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
@@ -75,89 +75,96 @@ func (ec *EmployeeService) CreateEmployee(c *gin.Context) {
 }
 
 // get all employee
+//
+// @summary List the employees
+// @query name string Name of the employees
+// @query role string Role of the employees
+// @success 200 []models.Employee
 func (uc *EmployeeService) GetEmployees(c *gin.Context) {
 	log.Info("Initializing GetEmployees handler function...")
 	var employees []models.Employee
 	name := c.Query("name")
 	role := c.Query("role")
-	err := controller.GetEmployees(uc.Db, name, role, &employees)
+	err := controller.GetEmployees(uc.Db.WithContext(c.Request.Context()), name, role, &employees)
 
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, employees)
 }
 
 // get employee by id
+//
+// @summary Get an employee
+// @success 200 models.Employee
 func (ec *EmployeeService) GetEmployee(c *gin.Context) {
 	log.Info("Initializing GetEmployee By ID handler function...")
 	id, _ := strconv.Atoi(c.Param("id"))
 	var employee models.Employee
-	err := controller.GetEmployee(ec.Db, &employee, id)
+	err := controller.GetEmployee(ec.Db.WithContext(c.Request.Context()), &employee, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error("No employee found against the provided id")
-			c.JSON(http.StatusNotFound, gin.H{"error": "No employee found against the provided id"})
+			respondError(c, apperrors.NotFound("No employee found against the provided id"))
 			return
 		}
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, employee)
 }
 
 // update Employee
+//
+// @summary Update an employee
+// @body models.Employee
+// @success 200 models.Employee
 func (ec *EmployeeService) UpdateEmployee(c *gin.Context) {
 	log.Info("Initializing UpdateEmployee handler function...")
 	var employee models.Employee
 	id, _ := strconv.Atoi(c.Param("id"))
-	err := controller.GetEmployee(ec.Db, &employee, id)
+	err := controller.GetEmployee(ec.Db.WithContext(c.Request.Context()), &employee, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error("No employee found for provided id")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "No employee found for provided id"})
+			respondError(c, apperrors.NotFound("No employee found for provided id"))
 			return
 		}
 
 	}
 	err = c.ShouldBindJSON(&employee)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 	// If a role is provided in the request, check if it exists in the DB and assign RoleId from the database
 	if roleName := employee.Role; roleName != "" {
-		roleId, err := controller.GetRoleIdFromDb(ec.Db, roleName)
+		roleId, err := controller.GetRoleIdFromDb(ec.Db.WithContext(c.Request.Context()), roleName)
 		if err != nil {
-			log.Error(err.Error())
-			c.JSON(http.StatusBadRequest, err.Error())
+			respondError(c, apperrors.Internal(err))
 			return
 		}
 
 		employee.RoleID = roleId
 	}
-	err = controller.UpdateEmployee(ec.Db, &employee)
+	err = controller.UpdateEmployee(ec.Db.WithContext(c.Request.Context()), &employee)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, employee)
 }
 
 // delete Employee
+//
+// @summary Delete an employee
+// @success 204
 func (ec *EmployeeService) DeleteEmployee(c *gin.Context) {
 	log.Info("Initializing DeleteEmployee handler function...")
 	var employee models.Employee
 	id, _ := strconv.Atoi(c.Param("id"))
-	_, err := controller.DeleteEmployee(ec.Db, &employee, id)
+	_, err := controller.DeleteEmployee(ec.Db.WithContext(c.Request.Context()), &employee, id)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
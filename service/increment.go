@@ -0,0 +1,498 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type IncrementService struct {
+	Db *gorm.DB
+}
+
+func NewIncrementService() *IncrementService {
+	db := database.DB
+	err := db.AutoMigrate(&models.IncrementPolicy{}, &models.IncrementBand{}, &models.Recommendation{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &IncrementService{Db: db}
+}
+
+// @summary Create an increment policy
+// @body models.IncrementPolicy
+// @success 201 models.IncrementPolicy
+func (s *IncrementService) CreateIncrementPolicy(c *gin.Context) {
+	log.Info("Initializing CreateIncrementPolicy handler function...")
+
+	ctx := c.Request.Context()
+
+	var policy models.IncrementPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := s.validateIncrementPolicy(c, &policy); !ok {
+		return
+	}
+	policy.ID = 0
+
+	dbPolicy, err := controller.CreateIncrementPolicy(s.Db.WithContext(ctx), &policy)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbPolicy)
+}
+
+// @summary List the increment policies
+// @query designation_id integer Designation the policies target
+// @success 200 []models.IncrementPolicy
+func (s *IncrementService) GetAllIncrementPolicies(c *gin.Context) {
+	log.Info("Initializing GetAllIncrementPolicies handler function...")
+
+	ctx := c.Request.Context()
+
+	var policies []models.IncrementPolicy
+	db := s.Db.WithContext(ctx).Model(&models.IncrementPolicy{})
+
+	designation := c.Query("designation_id")
+	if designation != "" {
+		db = db.Where("designation = ?", designation)
+	}
+
+	if err := controller.GetAllIncrementPolicies(db, &policies); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+// @summary Get an increment policy
+// @success 200 models.IncrementPolicy
+func (s *IncrementService) GetIncrementPolicyByID(c *gin.Context) {
+	log.Info("Initializing GetIncrementPolicyByID handler function...")
+
+	ctx := c.Request.Context()
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var policy models.IncrementPolicy
+	err := controller.GetIncrementPolicyByID(s.Db.WithContext(ctx), &policy, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against increment policy id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// @summary Update an increment policy
+// @body models.IncrementPolicy
+// @success 200 models.IncrementPolicy
+func (s *IncrementService) UpdateIncrementPolicy(c *gin.Context) {
+	log.Info("Initializing UpdateIncrementPolicy handler function...")
+
+	ctx := c.Request.Context()
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var policy models.IncrementPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := s.validateIncrementPolicy(c, &policy); !ok {
+		return
+	}
+	policy.ID = uint16(id)
+
+	dbPolicy, err := controller.UpdateIncrementPolicy(s.Db.WithContext(ctx), &policy)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbPolicy)
+}
+
+// @summary Delete an increment policy
+// @success 204
+func (s *IncrementService) DeleteIncrementPolicy(c *gin.Context) {
+	log.Info("Initializing DeleteIncrementPolicy handler function...")
+
+	ctx := c.Request.Context()
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var policy models.IncrementPolicy
+	err := controller.GetIncrementPolicyByID(s.Db.WithContext(ctx), &policy, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against increment policy id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	if err := controller.DeleteIncrementPolicy(s.Db.WithContext(ctx), &policy); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// recommendationCycle is the payload of the job generating the recommendation
+// sheet of a cycle
+type recommendationCycle struct {
+	AppraisalYear uint16 `json:"appraisal_year"`
+	AppraisalType string `json:"appraisal_type"`
+}
+
+// GenerateRecommendations queues the generation of the recommendation sheet of
+// an annual cycle with the increments and promotion eligibility of the increment
+// policies. Every employee of the cycle is matched, so the sheet is generated by
+// a background job.
+//
+// @summary Queue the generation of the recommendation sheet of an annual appraisal cycle
+// @query appraisal_year integer Year of the appraisal cycle
+// @query appraisal_type string Type of the appraisal cycle
+// @success 202 models.Job
+func (s *IncrementService) GenerateRecommendations(c *gin.Context) {
+	log.Info("Initializing GenerateRecommendations handler function...")
+
+	ctx := c.Request.Context()
+
+	appraisalYear, appraisalType, ok := parseAppraisalCycle(c, s.Db.WithContext(ctx))
+	if !ok {
+		return
+	}
+
+	if appraisalType != constants.ANNUAL_APPRAISAL {
+		respondError(c, apperrors.Invalid("recommendations are only generated for annual appraisals"))
+		return
+	}
+
+	job, err := enqueueJob(ctx, constants.JOB_TYPE_GENERATE_RECOMMENDATIONS, recommendationCycle{
+		AppraisalYear: appraisalYear,
+		AppraisalType: appraisalType,
+	})
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	respondJobAccepted(c, job)
+}
+
+// RunGenerateRecommendations is the job generating the sheet queued by
+// GenerateRecommendations. Every employee is an item of the job, and the ones
+// no band of a policy matched are its failed items.
+func (s *IncrementService) RunGenerateRecommendations(ctx context.Context, job *models.Job, progress *controller.JobProgress) error {
+	var cycle recommendationCycle
+	if err := json.Unmarshal([]byte(job.Payload), &cycle); err != nil {
+		return err
+	}
+
+	sheet, err := controller.GenerateRecommendations(s.Db.WithContext(ctx), cycle.AppraisalYear, cycle.AppraisalType)
+	if err != nil {
+		return err
+	}
+
+	// Approved recommendations are final and left out of the generation
+	generated := make([]models.Recommendation, 0, sheet.Generated)
+	for _, recommendation := range sheet.Recommendations {
+		if recommendation.Status != constants.RECOMMENDATION_STATUS_APPROVED {
+			generated = append(generated, recommendation)
+		}
+	}
+
+	if err := progress.SetTotal(len(generated)); err != nil {
+		return err
+	}
+	for _, recommendation := range generated {
+		if recommendation.PolicyID == nil {
+			item := fmt.Sprintf("employee %d of appraisal %d", recommendation.EmployeeID, recommendation.AppraisalID)
+			err = progress.ItemFailed(item, "no band of an increment policy matches the final score")
+		} else {
+			err = progress.ItemSucceeded()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	resultURL := fmt.Sprintf("/v1/recommendations?appraisal_year=%d&appraisal_type=%s", cycle.AppraisalYear, url.QueryEscape(cycle.AppraisalType))
+	return progress.SetResult(0, resultURL)
+}
+
+// GetRecommendations returns the recommendation sheet of a cycle, as JSON or as
+// CSV with format=csv
+//
+// @summary List the recommendations of an appraisal cycle
+// @query appraisal_year integer Year of the appraisal cycle
+// @query appraisal_type string Type of the appraisal cycle
+// @query status string Status of the recommendations
+// @query supervisor_id integer Supervisor of the employees
+// @query designation_id integer Designation of the employees
+// @query format string csv for a CSV sheet
+// @success 200 []models.Recommendation
+// @success 200 file text/csv
+func (s *IncrementService) GetRecommendations(c *gin.Context) {
+	log.Info("Initializing GetRecommendations handler function...")
+
+	ctx := c.Request.Context()
+
+	appraisalYear, appraisalType, ok := parseAppraisalCycle(c, s.Db.WithContext(ctx))
+	if !ok {
+		return
+	}
+
+	db := s.Db.WithContext(ctx).Where("appraisal_year = ? AND appraisal_type = ?", appraisalYear, appraisalType)
+
+	status := c.Query("status")
+	supervisorID := c.Query("supervisor_id")
+	designation := c.Query("designation_id")
+
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if supervisorID != "" {
+		db = db.Where("supervisor_id = ?", supervisorID)
+	}
+
+	if designation != "" {
+		db = db.Where("designation = ?", designation)
+	}
+
+	recommendations := make([]models.Recommendation, 0)
+	if err := controller.GetRecommendations(db, &recommendations); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	if c.Query("format") != reportFormatCSV {
+		c.JSON(http.StatusOK, recommendations)
+		return
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{
+		"recommendation_id", "appraisal_id", "employee_id", "employee_name", "designation", "team", "final_score", "band",
+		"recommended_increment", "recommended_promotion", "final_increment", "final_promotion", "justification", "status",
+	})
+	for _, recommendation := range recommendations {
+		_ = writer.Write([]string{
+			strconv.Itoa(int(recommendation.ID)), strconv.Itoa(int(recommendation.AppraisalID)),
+			strconv.Itoa(int(recommendation.EmployeeID)), recommendation.EmployeeName, recommendation.DesignationName,
+			recommendation.TeamName, formatScore(recommendation.FinalScore), recommendation.BandName,
+			strconv.FormatFloat(recommendation.RecommendedIncrement, 'f', 2, 64), strconv.FormatBool(recommendation.RecommendedPromotion),
+			strconv.FormatFloat(recommendation.FinalIncrement, 'f', 2, 64), strconv.FormatBool(recommendation.FinalPromotion),
+			recommendation.OverrideJustification, recommendation.Status,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	fileName := fmt.Sprintf("recommendations-%s-%d.csv", appraisalType, appraisalYear)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
+
+// @summary Get a recommendation
+// @success 200 models.Recommendation
+func (s *IncrementService) GetRecommendationByID(c *gin.Context) {
+	log.Info("Initializing GetRecommendationByID handler function...")
+
+	recommendation, ok := s.findRecommendation(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendation)
+}
+
+// OverrideRecommendation lets the supervisor of the employee replace the
+// recommended increment and promotion eligibility with a justification
+//
+// @summary Override the recommended increment and promotion of an employee
+// @body models.RecommendationOverride
+// @success 200 models.Recommendation
+// @auth
+func (s *IncrementService) OverrideRecommendation(c *gin.Context) {
+	log.Info("Initializing OverrideRecommendation handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	recommendation, ok := s.findRecommendation(c)
+	if !ok {
+		return
+	}
+
+	if recommendation.SupervisorID != tokenInfo.EmpID {
+		respondError(c, apperrors.Forbidden("only the supervisor of the employee can override the recommendation"))
+		return
+	}
+
+	var override models.RecommendationOverride
+	if err := c.ShouldBindJSON(&override); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, override.Validate()); !ok {
+		return
+	}
+	override.SupervisorID = tokenInfo.EmpID
+
+	dbRecommendation, err := controller.OverrideRecommendation(s.Db.WithContext(ctx), &recommendation, &override)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbRecommendation)
+}
+
+// ApproveRecommendations lets HR approve recommendations in bulk
+//
+// @summary Approve recommendations in bulk
+// @body models.RecommendationApproval
+// @success 200 object
+// @auth
+func (s *IncrementService) ApproveRecommendations(c *gin.Context) {
+	log.Info("Initializing ApproveRecommendations handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getHRTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	var approval models.RecommendationApproval
+	if err := c.ShouldBindJSON(&approval); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, approval.Validate()); !ok {
+		return
+	}
+	approval.ApprovedBy = tokenInfo.EmpID
+
+	approved, err := controller.ApproveRecommendations(s.Db.WithContext(ctx), &approval)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"approved": approved})
+}
+
+// findRecommendation loads the recommendation of the id path param and writes
+// the error response itself
+func (s *IncrementService) findRecommendation(c *gin.Context) (models.Recommendation, bool) {
+	ctx := c.Request.Context()
+	var recommendation models.Recommendation
+
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return recommendation, false
+	}
+
+	err = controller.GetRecommendationByID(s.Db.WithContext(ctx), &recommendation, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against recommendation id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return recommendation, false
+	}
+
+	return recommendation, true
+}
+
+// validateIncrementPolicy validates the request policy and writes the error
+// response itself. Policies only apply to annual appraisals, target a
+// designation or every designation, and their bands must not overlap.
+func (s *IncrementService) validateIncrementPolicy(c *gin.Context, policy *models.IncrementPolicy) bool {
+	ctx := c.Request.Context()
+	if ok := validateStruct(c, policy.Validate()); !ok {
+		return false
+	}
+
+	if policy.AppraisalTypeStr != constants.ANNUAL_APPRAISAL {
+		respondError(c, apperrors.Invalid("increment policies only apply to annual appraisals"))
+		return false
+	}
+
+	policy.DesignationName = ""
+	if policy.Designation != 0 {
+		name, err := utils.GetDesignationName(ctx, policy.Designation)
+		if err != nil || name == "" {
+			respondError(c, apperrors.Invalid("invalid designation"))
+			return false
+		}
+		policy.DesignationName = name
+	}
+
+	sort.Slice(policy.Bands, func(i, j int) bool {
+		return policy.Bands[i].MinScore < policy.Bands[j].MinScore
+	})
+
+	for k, band := range policy.Bands {
+		if band.MinScore >= band.MaxScore {
+			errMsg := fmt.Sprintf("min_score of band '%s' should be less than its max_score", band.BandName)
+			respondError(c, apperrors.Invalid(errMsg))
+			return false
+		}
+		if k > 0 && band.MinScore < policy.Bands[k-1].MaxScore {
+			errMsg := fmt.Sprintf("band '%s' overlaps band '%s'", band.BandName, policy.Bands[k-1].BandName)
+			respondError(c, apperrors.Invalid(errMsg))
+			return false
+		}
+	}
+
+	return true
+}
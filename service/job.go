@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// JobHandler runs a job of a type, reporting its progress as it goes. The job
+// should stop once ctx is done.
+type JobHandler func(ctx context.Context, job *models.Job, progress *controller.JobProgress) error
+
+// JobService runs the background jobs with a bounded number of workers. Jobs
+// are persisted before they are queued, so queued jobs survive a restart.
+type JobService struct {
+	Db       *gorm.DB
+	queue    chan uint16
+	handlers map[string]JobHandler
+}
+
+// jobs is the service background jobs are queued to, set once it is created
+var jobs *JobService
+
+func NewJobService() *JobService {
+	db := database.DB
+	err := db.AutoMigrate(&models.Job{}, &models.JobError{})
+	if err != nil {
+		panic(err)
+	}
+
+	jobs = &JobService{Db: db, queue: make(chan uint16), handlers: make(map[string]JobHandler)}
+	return jobs
+}
+
+// Register sets the handler running the jobs of a type
+func (s *JobService) Register(jobType string, handler JobHandler) {
+	s.handlers[jobType] = handler
+}
+
+// Start runs the workers, constants.JOB_WORKERS of them unless overridden by
+// JOB_WORKERS, and queues again the jobs still queued before a restart
+func (s *JobService) Start() {
+	workers := constants.JOB_WORKERS
+	if value := os.Getenv("JOB_WORKERS"); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil || count <= 0 {
+			log.Error("invalid JOB_WORKERS, using the default")
+		} else {
+			workers = count
+		}
+	}
+
+	queuedIDs, err := controller.RecoverJobs(s.Db)
+	if err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+	for _, id := range queuedIDs {
+		s.push(id)
+	}
+}
+
+// push hands the job over to the workers without blocking the caller
+func (s *JobService) push(id uint16) {
+	go func() {
+		s.queue <- id
+	}()
+}
+
+func (s *JobService) work() {
+	for id := range s.queue {
+		s.run(id)
+	}
+}
+
+func (s *JobService) run(id uint16) {
+	job, claimed, err := controller.ClaimJob(s.Db, id)
+	if err != nil || !claimed {
+		return
+	}
+
+	log.Info(fmt.Sprintf("Running %s job %d", job.JobType, job.ID))
+	ctx, cancel := context.WithTimeout(context.Background(), constants.JOB_TIMEOUT)
+	defer cancel()
+
+	handler, ok := s.handlers[job.JobType]
+	if ok {
+		err = runJobHandler(ctx, handler, job, controller.NewJobProgress(s.Db, job))
+	} else {
+		err = fmt.Errorf("unknown job type %s", job.JobType)
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("%s job %d failed: %s", job.JobType, job.ID, err.Error()))
+	}
+
+	_ = controller.FinishJob(s.Db, job, err)
+}
+
+// runJobHandler fails the job instead of the worker if the handler panics
+func runJobHandler(ctx context.Context, handler JobHandler, job *models.Job, progress *controller.JobProgress) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job, progress)
+}
+
+// enqueueJob persists a job of the type with the payload and queues it. The
+// context is the one of the request queueing the job, not of the job.
+func enqueueJob(ctx context.Context, jobType string, payload interface{}) (*models.Job, error) {
+	if jobs == nil {
+		return nil, errors.New("background jobs are not available")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := models.Job{
+		JobType:  jobType,
+		Status:   constants.JOB_STATUS_QUEUED,
+		Payload:  string(data),
+		QueuedAt: time.Now(),
+		Errors:   make([]models.JobError, 0),
+	}
+	if err := controller.CreateJob(jobs.Db.WithContext(ctx), &job); err != nil {
+		return nil, err
+	}
+	job.StatusURL = jobStatusURL(job.ID)
+
+	jobs.push(job.ID)
+	return &job, nil
+}
+
+func jobStatusURL(id uint16) string {
+	return fmt.Sprintf("/v1/jobs/%d", id)
+}
+
+// respondJobAccepted answers a request whose work was queued as a job
+func respondJobAccepted(c *gin.Context, job *models.Job) {
+	c.Header("Location", job.StatusURL)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// @summary List the background jobs
+// @query status string Status of the jobs
+// @query job_type string Type of the jobs
+// @success 200 []models.Job
+func (s *JobService) GetAllJobs(c *gin.Context) {
+	log.Info("Initializing GetAllJobs handler function...")
+
+	ctx := c.Request.Context()
+
+	jobList := make([]models.Job, 0)
+	db := s.Db.WithContext(ctx).Model(&models.Job{})
+
+	status := c.Query("status")
+	jobType := c.Query("job_type")
+
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if jobType != "" {
+		db = db.Where("job_type = ?", jobType)
+	}
+
+	if err := controller.GetAllJobs(db, &jobList); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	for k := range jobList {
+		jobList[k].StatusURL = jobStatusURL(jobList[k].ID)
+	}
+
+	c.JSON(http.StatusOK, jobList)
+}
+
+// @summary Get the progress and result of a background job
+// @success 200 models.Job
+func (s *JobService) GetJobByID(c *gin.Context) {
+	log.Info("Initializing GetJobByID handler function...")
+
+	ctx := c.Request.Context()
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var job models.Job
+	err := controller.GetJobByID(s.Db.WithContext(ctx), &job, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against job id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+	job.StatusURL = jobStatusURL(job.ID)
+
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// MeService serves the data of the authenticated employee only. The employee is
+// always taken from the jwt, never from the request.
+type MeService struct {
+	Db *gorm.DB
+}
+
+func NewMeService() *MeService {
+	return &MeService{Db: database.DB}
+}
+
+// @summary List the appraisals of the caller
+// @query status string Either current or past
+// @success 200 []models.EmployeeAppraisal
+// @auth
+func (s *MeService) GetMyAppraisals(c *gin.Context) {
+	log.Info("Initializing GetMyAppraisals handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisals := make([]models.EmployeeAppraisal, 0)
+	db := controller.EmployeeAppraisalsQuery(s.Db.WithContext(ctx), tokenInfo.EmpID)
+
+	switch c.Query("status") {
+	case "":
+	case "current":
+		db = db.Where("appraisals.status = ? AND employee_data.appraisal_status != ?", true, constants.APPRAISAL_STATUS_PUBLISHED)
+	case "past":
+		db = db.Where("appraisals.status = ? OR employee_data.appraisal_status = ?", false, constants.APPRAISAL_STATUS_PUBLISHED)
+	default:
+		respondError(c, apperrors.Invalid("status should be either current or past"))
+		return
+	}
+
+	if err := controller.GetEmployeeAppraisals(db, &appraisals); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, appraisals)
+}
+
+// @summary List the kpis of the caller in an appraisal
+// @success 200 []models.AppraisalKpi
+// @auth
+func (s *MeService) GetMyAppraisalKpis(c *gin.Context) {
+	log.Info("Initializing GetMyAppraisalKpis handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var appraisalKpis []models.AppraisalKpi
+	err := controller.GetEmployeeAppraisalKpis(s.Db.WithContext(ctx), &appraisalKpis, appraisalID, tokenInfo.EmpID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No kpis found against the appraisal"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	// Answer keys must never reach the employee answering the questionnaire
+	models.HideAnswerKeys(appraisalKpis)
+
+	c.JSON(http.StatusOK, appraisalKpis)
+}
+
+// @summary List the self reviews the caller still owes
+// @success 200 []models.SelfReviewTask
+// @auth
+func (s *MeService) GetMyTasks(c *gin.Context) {
+	log.Info("Initializing GetMyTasks handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	tasks := make([]models.SelfReviewTask, 0)
+	if err := controller.GetSelfReviewTasks(s.Db.WithContext(ctx), &tasks, tokenInfo.EmpID); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// @summary List the published results of the caller
+// @success 200 []models.EmployeeResult
+// @auth
+func (s *MeService) GetMyResults(c *gin.Context) {
+	log.Info("Initializing GetMyResults handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	results := make([]models.EmployeeResult, 0)
+	if err := controller.GetPublishedResults(s.Db.WithContext(ctx), &results, tokenInfo.EmpID); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetMyInbox lists the employees the caller still owes scores for, sorted by
+// urgency unless another sort order is requested
+//
+// @summary List the employees the caller still owes scores for
+// @query sort string Either urgency, the default, pending or employee
+// @success 200 []models.InboxTask
+// @auth
+func (s *MeService) GetMyInbox(c *gin.Context) {
+	log.Info("Initializing GetMyInbox handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	sort := c.DefaultQuery("sort", constants.INBOX_SORT_URGENCY)
+	if !controller.IsValidInboxSort(sort) {
+		respondError(c, apperrors.Invalid("sort should be one of urgency, pending or employee"))
+		return
+	}
+
+	tasks := make([]models.InboxTask, 0)
+	if err := controller.GetEvaluatorInbox(s.Db.WithContext(ctx), &tasks, tokenInfo.EmpID, sort); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	// The caller also owes the tasks of the users who delegated their rights to them
+	delegatorIDs, err := controller.GetActiveDelegators(s.Db.WithContext(ctx), tokenInfo.EmpID)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	for _, delegatorID := range delegatorIDs {
+		var delegatedTasks []models.InboxTask
+		if err := controller.GetEvaluatorInbox(s.Db.WithContext(ctx), &delegatedTasks, delegatorID, sort); err != nil {
+			respondError(c, apperrors.Internal(err))
+			return
+		}
+
+		for _, task := range delegatedTasks {
+			if task.EmployeeID == tokenInfo.EmpID {
+				continue
+			}
+			task.AssignedAs = constants.ASSIGNED_AS_DELEGATE
+			task.OnBehalfOf = delegatorID
+			tasks = append(tasks, task)
+		}
+	}
+
+	if len(delegatorIDs) > 0 {
+		controller.SortInboxTasks(tasks, sort)
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// AcknowledgeMyResults signs the caller off on their published results of an
+// appraisal, either acknowledging or disagreeing with them
+//
+// @summary Acknowledge or disagree with the published results of the caller
+// @body models.Acknowledgement
+// @success 201 models.Acknowledgement
+// @auth
+func (s *MeService) AcknowledgeMyResults(c *gin.Context) {
+	log.Info("Initializing AcknowledgeMyResults handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalID, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	var acknowledgement models.Acknowledgement
+	if err := c.ShouldBindJSON(&acknowledgement); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, acknowledgement.Validate()); !ok {
+		return
+	}
+	acknowledgement.ID = 0
+	acknowledgement.AppraisalID = uint16(appraisalID)
+	acknowledgement.EmployeeID = tokenInfo.EmpID
+
+	dbAcknowledgement, err := controller.AcknowledgeResults(s.Db.WithContext(ctx), &acknowledgement)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No results found against the appraisal"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbAcknowledgement)
+}
+
+// @summary Get the acknowledgement of the results of the caller
+// @success 200 models.Acknowledgement
+// @auth
+func (s *MeService) GetMyAcknowledgement(c *gin.Context) {
+	log.Info("Initializing GetMyAcknowledgement handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var acknowledgement models.Acknowledgement
+	err := controller.GetAcknowledgement(s.Db.WithContext(ctx), &acknowledgement, appraisalID, uint64(tokenInfo.EmpID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No acknowledgement found against the appraisal"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, acknowledgement)
+}
@@ -0,0 +1,502 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+type PipService struct {
+	Db *gorm.DB
+}
+
+func NewPipService() *PipService {
+	db := database.DB
+	err := db.AutoMigrate(&models.Pip{}, &models.PipObjective{}, &models.PipCheckIn{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &PipService{Db: db}
+}
+
+// GetPipCandidates suggests employees for a plan from the low final scores.
+// The threshold defaults to constants.PIP_SCORE_THRESHOLD.
+//
+// @summary Suggest employees with low final scores for an improvement plan
+// @query threshold number Final score under which employees are suggested
+// @query appraisal_id integer Appraisal of the scores
+// @query team_id integer Team of the employees
+// @query supervisor_id integer Supervisor of the employees
+// @success 200 []models.PipCandidate
+func (s *PipService) GetPipCandidates(c *gin.Context) {
+	log.Info("Initializing GetPipCandidates handler function...")
+
+	ctx := c.Request.Context()
+
+	threshold := constants.PIP_SCORE_THRESHOLD
+	if value := c.Query("threshold"); value != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 || threshold > 100 {
+			respondError(c, apperrors.Invalid("threshold should be a number between 0 and 100"))
+			return
+		}
+	}
+
+	db := s.Db.WithContext(ctx)
+	appraisalID := c.Query("appraisal_id")
+	teamID := c.Query("team_id")
+	supervisorID := c.Query("supervisor_id")
+
+	if appraisalID != "" {
+		db = db.Where("results.appraisal_id = ?", appraisalID)
+	}
+
+	if teamID != "" {
+		db = db.Where("results.team_id = ?", teamID)
+	}
+
+	if supervisorID != "" {
+		db = db.Where("results.supervisor_id = ?", supervisorID)
+	}
+
+	candidates := make([]models.PipCandidate, 0)
+	if err := controller.GetPipCandidates(db, &candidates, threshold); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+// CreatePip opens a plan for an employee. Only the supervisor of the appraisal,
+// who owns the plan, and HR can open it.
+//
+// @summary Create a performance improvement plan
+// @body models.Pip
+// @success 201 models.Pip
+// @auth
+func (s *PipService) CreatePip(c *gin.Context) {
+	log.Info("Initializing CreatePip handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	var pip models.Pip
+	if err := c.ShouldBindJSON(&pip); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, pip.Validate()); !ok {
+		return
+	}
+	pip.ID = 0
+	pip.Outcome = ""
+	pip.OutcomeNotes = ""
+	pip.ClosedBy = 0
+	pip.ClosedAt = nil
+	for k := range pip.Objectives {
+		pip.Objectives[k].ID = 0
+	}
+	for k := range pip.CheckIns {
+		pip.CheckIns[k].ID = 0
+		pip.CheckIns[k].HeldAt = nil
+		pip.CheckIns[k].Progress = ""
+		pip.CheckIns[k].RecordedBy = 0
+	}
+	pip.OpenedBy = tokenInfo.EmpID
+
+	if !tokenInfo.IsHR() {
+		result, err := controller.GetEmployeeResult(s.Db.WithContext(ctx), uint64(pip.AppraisalID), uint64(pip.EmployeeID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondError(c, apperrors.NotFound("No results found against the appraisal and employee"))
+			} else {
+				respondError(c, apperrors.Internal(err))
+			}
+			return
+		}
+		if result.SupervisorID != tokenInfo.EmpID {
+			respondError(c, apperrors.Forbidden("only the supervisor of the employee or hr can open a plan"))
+			return
+		}
+	}
+
+	dbPip, err := controller.CreatePip(s.Db.WithContext(ctx), &pip)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No results found against the appraisal and employee"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbPip)
+}
+
+// @summary List the performance improvement plans
+// @query status string Status of the plans
+// @query owner_id integer Owner of the plans
+// @query appraisal_id integer Appraisal the plans follow
+// @query employee_id integer Employee of the plans
+// @success 200 []models.Pip
+func (s *PipService) GetAllPips(c *gin.Context) {
+	log.Info("Initializing GetAllPips handler function...")
+
+	ctx := c.Request.Context()
+
+	pips := make([]models.Pip, 0)
+	db := s.Db.WithContext(ctx).Model(&models.Pip{})
+
+	status := c.Query("status")
+	ownerID := c.Query("owner_id")
+	appraisalID := c.Query("appraisal_id")
+	employeeID := c.Query("employee_id")
+
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if ownerID != "" {
+		db = db.Where("owner_id = ?", ownerID)
+	}
+
+	if appraisalID != "" {
+		db = db.Where("appraisal_id = ?", appraisalID)
+	}
+
+	if employeeID != "" {
+		db = db.Where("employee_id = ?", employeeID)
+	}
+
+	if err := controller.GetAllPips(db, &pips); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, pips)
+}
+
+// @summary Get a performance improvement plan
+// @success 200 models.Pip
+func (s *PipService) GetPipByID(c *gin.Context) {
+	log.Info("Initializing GetPipByID handler function...")
+
+	pip, ok := s.findPip(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, pip)
+}
+
+// @summary Add an objective to a plan
+// @body models.PipObjective
+// @success 201 models.PipObjective
+// @auth
+func (s *PipService) AddPipObjective(c *gin.Context) {
+	log.Info("Initializing AddPipObjective handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	pip, ok := s.findOwnedPip(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	var objective models.PipObjective
+	if err := c.ShouldBindJSON(&objective); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, objective.Validate()); !ok {
+		return
+	}
+	objective.ID = 0
+
+	dbObjective, err := controller.AddPipObjective(s.Db.WithContext(ctx), &pip, &objective)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbObjective)
+}
+
+// @summary Update an objective of a plan
+// @body models.PipObjective
+// @success 200 models.PipObjective
+// @auth
+func (s *PipService) UpdatePipObjective(c *gin.Context) {
+	log.Info("Initializing UpdatePipObjective handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	pip, ok := s.findOwnedPip(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	objectiveID, err := strconv.ParseUint(c.Param("objective_id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	var objective models.PipObjective
+	if err := c.ShouldBindJSON(&objective); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, objective.Validate()); !ok {
+		return
+	}
+	objective.ID = uint16(objectiveID)
+
+	dbObjective, err := controller.UpdatePipObjective(s.Db.WithContext(ctx), &pip, &objective)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against objective id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dbObjective)
+}
+
+// ScheduleCheckIn schedules a check-in meeting of a plan
+//
+// @summary Schedule a check-in meeting of a plan
+// @body models.PipCheckIn
+// @success 201 models.PipCheckIn
+// @auth
+func (s *PipService) ScheduleCheckIn(c *gin.Context) {
+	log.Info("Initializing ScheduleCheckIn handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	pip, ok := s.findOwnedPip(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	var checkIn models.PipCheckIn
+	if err := c.ShouldBindJSON(&checkIn); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, checkIn.Validate()); !ok {
+		return
+	}
+	checkIn.ID = 0
+	checkIn.HeldAt = nil
+	checkIn.Progress = ""
+	checkIn.RecordedBy = 0
+
+	dbCheckIn, err := controller.SavePipCheckIn(s.Db.WithContext(ctx), &pip, &checkIn)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbCheckIn)
+}
+
+// RecordCheckIn records the progress and notes of a held check-in meeting
+//
+// @summary Record the progress and notes of a check-in meeting
+// @body models.PipCheckIn
+// @success 200 models.PipCheckIn
+// @auth
+func (s *PipService) RecordCheckIn(c *gin.Context) {
+	log.Info("Initializing RecordCheckIn handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	pip, ok := s.findOwnedPip(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	checkInID, err := strconv.ParseUint(c.Param("check_in_id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	var checkIn models.PipCheckIn
+	if err := c.ShouldBindJSON(&checkIn); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, checkIn.Validate()); !ok {
+		return
+	}
+	if checkIn.HeldAt == nil || checkIn.Progress == "" {
+		respondError(c, apperrors.Invalid("held_at and progress fields are required"))
+		return
+	}
+	checkIn.ID = uint16(checkInID)
+	checkIn.RecordedBy = tokenInfo.EmpID
+
+	dbCheckIn, err := controller.SavePipCheckIn(s.Db.WithContext(ctx), &pip, &checkIn)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against check-in id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dbCheckIn)
+}
+
+// DecidePipOutcome closes or extends a plan
+//
+// @summary Close or extend a plan
+// @body models.PipOutcome
+// @success 200 models.Pip
+// @auth
+func (s *PipService) DecidePipOutcome(c *gin.Context) {
+	log.Info("Initializing DecidePipOutcome handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	pip, ok := s.findOwnedPip(c, tokenInfo)
+	if !ok {
+		return
+	}
+
+	var outcome models.PipOutcome
+	if err := c.ShouldBindJSON(&outcome); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, outcome.Validate()); !ok {
+		return
+	}
+	outcome.DecidedBy = tokenInfo.EmpID
+
+	dbPip, err := controller.DecidePipOutcome(s.Db.WithContext(ctx), &pip, &outcome)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbPip)
+}
+
+// GetMyPips returns the plans of the caller
+//
+// @summary List the performance improvement plans of the caller
+// @success 200 []models.Pip
+// @auth
+func (s *PipService) GetMyPips(c *gin.Context) {
+	log.Info("Initializing GetMyPips handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	pips := make([]models.Pip, 0)
+	db := s.Db.WithContext(ctx).Model(&models.Pip{}).Where("employee_id = ?", tokenInfo.EmpID)
+	if err := controller.GetAllPips(db, &pips); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, pips)
+}
+
+// findOwnedPip is findPip restricted to the plans owned by the caller, unless
+// the caller is HR
+func (s *PipService) findOwnedPip(c *gin.Context, tokenInfo models.TokenInfo) (models.Pip, bool) {
+	pip, ok := s.findPip(c)
+	if !ok {
+		return pip, false
+	}
+
+	if pip.OwnerID != tokenInfo.EmpID && !tokenInfo.IsHR() {
+		respondError(c, apperrors.Forbidden("only the owner of the plan or hr can change it"))
+		return pip, false
+	}
+
+	return pip, true
+}
+
+// findPip loads the plan of the id path param and writes the error response
+// itself
+func (s *PipService) findPip(c *gin.Context) (models.Pip, bool) {
+	ctx := c.Request.Context()
+	var pip models.Pip
+
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return pip, false
+	}
+
+	err = controller.GetPipByID(s.Db.WithContext(ctx), &pip, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against pip id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return pip, false
+	}
+
+	return pip, true
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+// @summary List the questionnaire kpis of an employee in an appraisal
+// @success 200 []models.AppraisalKpi
+func (r *AppraisalService) GetQuestionnaire(c *gin.Context) {
+	log.Info("Initializing GetQuestionnaire handler function...")
+
+	ctx := c.Request.Context()
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 64)
+	empID, _ := strconv.ParseUint(c.Param("emp_id"), 0, 64)
+
+	var appraisalKpis []models.AppraisalKpi
+	err := controller.GetQuestionnaireKpis(r.Db.WithContext(ctx), &appraisalKpis, appraisalID, empID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No questionnaire found against the employee"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	// Answer keys must never reach the employee answering the questionnaire
+	models.HideAnswerKeys(appraisalKpis)
+
+	c.JSON(http.StatusOK, appraisalKpis)
+}
+
+// SubmitQuestionnaire grades the answers of the employee to their questionnaires.
+// Employees only answer their own questionnaires.
+//
+// @summary Submit the questionnaire answers of an employee
+// @body []models.QuestionnaireAnswer
+// @success 201 []models.Score
+// @auth
+func (r *AppraisalService) SubmitQuestionnaire(c *gin.Context) {
+	log.Info("Initializing SubmitQuestionnaire handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 64)
+	empID, _ := strconv.ParseUint(c.Param("emp_id"), 0, 16)
+
+	if uint16(empID) != tokenInfo.EmpID {
+		respondError(c, apperrors.Forbidden("employees can only submit their own questionnaires"))
+		return
+	}
+
+	var answers []models.QuestionnaireAnswer
+	if err := c.ShouldBindJSON(&answers); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if len(answers) == 0 {
+		respondError(c, apperrors.Invalid("answers field is nil"))
+		return
+	}
+
+	for k := range answers {
+		if ok := validateStruct(c, answers[k].Validate()); !ok {
+			return
+		}
+		answers[k].ID = 0
+	}
+
+	var appraisalKpis []models.AppraisalKpi
+	err := controller.GetQuestionnaireKpis(r.Db.WithContext(ctx), &appraisalKpis, appraisalID, empID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No questionnaire found against the employee"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	// Check if every answer belongs to one of the employee's questionnaires
+	existingKpiMap := make(map[uint16]bool)
+	for _, appraisalKpi := range appraisalKpis {
+		existingKpiMap[appraisalKpi.ID] = true
+	}
+	for _, answer := range answers {
+		if !existingKpiMap[answer.AppraisalKpiID] {
+			errMsg := fmt.Sprintf("invalid appraisal_kpi_id :%v", answer.AppraisalKpiID)
+			respondError(c, apperrors.Invalid(errMsg))
+			return
+		}
+	}
+
+	scores, err := controller.SubmitQuestionnaireAnswers(r.Db.WithContext(ctx), appraisalKpis, answers, uint16(empID))
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, scores)
+}
+
+// GetQuestionnaireReview returns the graded answers of the employee for their
+// evaluators and HR. The employee themselves can't review them.
+//
+// @summary Review the graded questionnaire answers of an employee
+// @success 200 []models.QuestionnaireReview
+// @auth
+func (r *AppraisalService) GetQuestionnaireReview(c *gin.Context) {
+	log.Info("Initializing GetQuestionnaireReview handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 64)
+	empID, _ := strconv.ParseUint(c.Param("emp_id"), 0, 64)
+
+	if !tokenInfo.IsHR() {
+		isMember, err := controller.IsAppraisalMember(r.Db.WithContext(ctx), uint16(appraisalID), uint16(empID), tokenInfo.EmpID)
+		if err != nil {
+			respondError(c, apperrors.Internal(err))
+			return
+		}
+		if !isMember || uint16(empID) == tokenInfo.EmpID {
+			respondError(c, apperrors.Forbidden("only the evaluators of the employee and hr can review the questionnaire"))
+			return
+		}
+	}
+
+	var appraisalKpis []models.AppraisalKpi
+	err := controller.GetQuestionnaireKpis(r.Db.WithContext(ctx), &appraisalKpis, appraisalID, empID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No questionnaire found against the employee"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	reviews, err := controller.GetQuestionnaireReview(r.Db.WithContext(ctx), appraisalKpis)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"gorm.io/gorm"
+)
+
+type RatingScaleService struct {
+	Db *gorm.DB
+}
+
+func NewRatingScaleService() *RatingScaleService {
+	db := database.DB
+	err := db.AutoMigrate(&models.RatingScale{}, &models.RatingLevel{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &RatingScaleService{Db: db}
+}
+
+// @summary Create a rating scale
+// @body models.RatingScale
+// @success 201 models.RatingScale
+func (s *RatingScaleService) CreateRatingScale(c *gin.Context) {
+	log.Info("Initializing CreateRatingScale handler function...")
+
+	ctx := c.Request.Context()
+
+	var ratingScale models.RatingScale
+	if err := c.ShouldBindJSON(&ratingScale); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateRatingScale(c, &ratingScale); !ok {
+		return
+	}
+	ratingScale.ID = 0
+
+	dbRatingScale, err := controller.CreateRatingScale(s.Db.WithContext(ctx), &ratingScale)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbRatingScale)
+}
+
+// @summary List the rating scales
+// @query scale_name string Name of the scales
+// @success 200 []models.RatingScale
+func (s *RatingScaleService) GetAllRatingScales(c *gin.Context) {
+	log.Info("Initializing GetAllRatingScales handler function...")
+
+	ctx := c.Request.Context()
+
+	var ratingScales []models.RatingScale
+	db := s.Db.WithContext(ctx).Model(&models.RatingScale{})
+
+	scaleName := c.Query("scale_name")
+	if scaleName != "" {
+		db = db.Where("scale_name LIKE ?", "%"+scaleName+"%")
+	}
+
+	if err := controller.GetAllRatingScales(db, &ratingScales); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, ratingScales)
+}
+
+// @summary Get a rating scale
+// @success 200 models.RatingScale
+func (s *RatingScaleService) GetRatingScaleByID(c *gin.Context) {
+	log.Info("Initializing GetRatingScaleByID handler function...")
+
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	ratingScale, err := controller.GetRatingScaleByID(s.Db.WithContext(ctx), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against rating scale id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ratingScale)
+}
+
+// @summary Update a rating scale
+// @body models.RatingScale
+// @success 200 models.RatingScale
+func (s *RatingScaleService) UpdateRatingScale(c *gin.Context) {
+	log.Info("Initializing UpdateRatingScale handler function...")
+
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	var ratingScale models.RatingScale
+	if err := c.ShouldBindJSON(&ratingScale); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateRatingScale(c, &ratingScale); !ok {
+		return
+	}
+	ratingScale.ID = uint16(id)
+
+	dbRatingScale, err := controller.UpdateRatingScale(s.Db.WithContext(ctx), &ratingScale)
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, dbRatingScale)
+}
+
+// @summary Delete a rating scale
+// @success 204
+func (s *RatingScaleService) DeleteRatingScale(c *gin.Context) {
+	log.Info("Initializing DeleteRatingScale handler function...")
+
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if err := controller.DeleteRatingScale(s.Db.WithContext(ctx), id); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// validateRatingScale validates the request rating scale and writes the error
+// response itself. Values and labels of the levels must be unique.
+func validateRatingScale(c *gin.Context, ratingScale *models.RatingScale) bool {
+	if ok := validateStruct(c, ratingScale.Validate()); !ok {
+		return false
+	}
+
+	values := make(map[uint16]bool)
+	labels := make(map[string]bool)
+	for k := range ratingScale.Levels {
+		level := &ratingScale.Levels[k]
+		level.Label = strings.TrimSpace(level.Label)
+
+		if values[level.Value] {
+			errMsg := fmt.Sprintf("duplicate level value %v", level.Value)
+			respondError(c, apperrors.Invalid(errMsg))
+			return false
+		}
+		if labels[strings.ToLower(level.Label)] {
+			errMsg := fmt.Sprintf("duplicate level label '%s'", level.Label)
+			respondError(c, apperrors.Invalid(errMsg))
+			return false
+		}
+		values[level.Value] = true
+		labels[strings.ToLower(level.Label)] = true
+	}
+
+	return true
+}
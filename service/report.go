@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Formats of the exported reports
+const (
+	reportFormatCSV = "csv"
+	reportFormatPDF = "pdf"
+)
+
+// ExportEmployeeReport exports the results, scores and comments of an employee as
+// CSV or PDF for HR and the members of the appraisal. Comments private to HR are
+// only included with include_private=true, which only HR can set.
+//
+// @summary Export the report of an employee in an appraisal
+// @query include_private boolean Include the comments private to HR
+// @query format string Either csv, the default, or pdf
+// @success 200 file text/csv
+// @success 200 file application/pdf
+// @auth
+func (r *AppraisalService) ExportEmployeeReport(c *gin.Context) {
+	log.Info("Initializing ExportEmployeeReport handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+	employeeID, _ := strconv.ParseUint(c.Param("emp_id"), 0, 16)
+	includePrivate := c.Query("include_private") == "true"
+
+	if includePrivate && !tokenInfo.IsHR() {
+		respondError(c, apperrors.Forbidden("only hr can export the comments private to hr"))
+		return
+	}
+
+	if !tokenInfo.IsHR() {
+		isMember, err := controller.IsAppraisalMember(r.Db.WithContext(ctx), uint16(appraisalID), uint16(employeeID), tokenInfo.EmpID)
+		if err != nil {
+			respondError(c, apperrors.Internal(err))
+			return
+		}
+		if !isMember {
+			respondError(c, apperrors.Forbidden("you are not a member of this appraisal"))
+			return
+		}
+	}
+
+	exportReport(c, r.Db.WithContext(ctx), appraisalID, employeeID, includePrivate)
+}
+
+// ExportMyReport exports the published results of the caller, with the comments
+// shared with them
+//
+// @summary Export the published report of the caller
+// @query format string Either csv, the default, or pdf
+// @success 200 file text/csv
+// @success 200 file application/pdf
+// @auth
+func (s *MeService) ExportMyReport(c *gin.Context) {
+	log.Info("Initializing ExportMyReport handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 64)
+
+	var count int64
+	err := controller.EmployeeAppraisalsQuery(s.Db.WithContext(ctx), tokenInfo.EmpID).
+		Where("employee_data.appraisal_id = ? AND employee_data.appraisal_status = ?", appraisalID, constants.APPRAISAL_STATUS_PUBLISHED).
+		Count(&count).Error
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+	if count == 0 {
+		respondError(c, apperrors.NotFound("No published results found against the appraisal"))
+		return
+	}
+
+	exportReport(c, s.Db.WithContext(ctx), appraisalID, uint64(tokenInfo.EmpID), false)
+}
+
+// exportReport writes the report of an employee in the format of the format
+// query param
+func exportReport(c *gin.Context, db *gorm.DB, appraisalID, employeeID uint64, includePrivate bool) {
+	format := c.DefaultQuery("format", reportFormatCSV)
+	if format != reportFormatCSV && format != reportFormatPDF {
+		respondError(c, apperrors.Invalid("format should be either csv or pdf"))
+		return
+	}
+
+	report, err := controller.GetEmployeeReport(db, appraisalID, employeeID, includePrivate)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No results found against the employee"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	fileName := fmt.Sprintf("appraisal-%d-employee-%d.%s", appraisalID, employeeID, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	if format == reportFormatPDF {
+		title := fmt.Sprintf("%s - %s", report.Result.AppraisalName, report.Result.EmployeeName)
+		c.Data(http.StatusOK, "application/pdf", utils.RenderTextPDF(title, reportLines(&report)))
+		return
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"record_type", "reference", "name", "value", "details"})
+	_ = writer.WriteAll(reportRecords(&report))
+	if err := writer.Error(); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
+
+// reportRecords flattens the report into CSV records of the result, the kpis,
+// the scores and the comments
+func reportRecords(report *models.EmployeeReport) [][]string {
+	result := report.Result
+	records := [][]string{
+		{"result", strconv.Itoa(int(result.AppraisalID)), "appraisal", result.AppraisalName, result.AppraisalType + " " + strconv.Itoa(int(result.AppraisalYear))},
+		{"result", strconv.Itoa(int(result.EmployeeID)), "employee", result.EmployeeName, result.TeamName},
+		{"result", "", "computed_score", resultScore(result.ComputedScore, result.ComputedScoreLabel), ""},
+		{"result", "", "final_score", resultScore(result.FinalScore, result.FinalScoreLabel), adjustedDetails(result.Adjusted)},
+	}
+	if result.Acknowledgement != "" {
+		records = append(records, []string{"result", "", "acknowledgement", result.Acknowledgement, result.AcknowledgedAt.Format("2006-01-02 15:04")})
+	}
+
+	kpiNames := make(map[uint16]string)
+	for _, appraisalKpi := range report.Kpis {
+		kpiNames[appraisalKpi.ID] = appraisalKpi.Kpi.KpiName
+		records = append(records, []string{
+			"kpi", strconv.Itoa(int(appraisalKpi.ID)), appraisalKpi.Kpi.KpiName, appraisalKpi.Kpi.KpiTypeStr,
+			fmt.Sprintf("weight %d", appraisalKpi.Kpi.KpiWeight),
+		})
+	}
+
+	for _, score := range report.Scores {
+		records = append(records, []string{
+			"score", strconv.Itoa(int(score.AppraisalKpiID)), kpiNames[score.AppraisalKpiID], scoreValue(score), score.TextAnswer,
+		})
+	}
+
+	for _, comment := range report.Comments {
+		records = append(records, []string{
+			"comment", commentReference(comment), fmt.Sprintf("%s (%s)", comment.AuthorName, comment.AuthorRole),
+			comment.Visibility, comment.Body,
+		})
+	}
+
+	return records
+}
+
+// reportLines lays the report out as the lines of a text document
+func reportLines(report *models.EmployeeReport) []string {
+	result := report.Result
+	lines := []string{
+		fmt.Sprintf("Appraisal: %s (%s %d)", result.AppraisalName, result.AppraisalType, result.AppraisalYear),
+		fmt.Sprintf("Employee: %s, %s, %s", result.EmployeeName, result.DesignationName, result.TeamName),
+		fmt.Sprintf("Supervisor: %s", result.SupervisorName),
+		fmt.Sprintf("Computed score: %s", resultScore(result.ComputedScore, result.ComputedScoreLabel)),
+		fmt.Sprintf("Final score: %s %s", resultScore(result.FinalScore, result.FinalScoreLabel), adjustedDetails(result.Adjusted)),
+	}
+	if result.Acknowledgement != "" {
+		lines = append(lines, fmt.Sprintf("Employee %s on %s", result.Acknowledgement, result.AcknowledgedAt.Format("2006-01-02 15:04")))
+	}
+
+	scoresByKpi := make(map[uint16][]models.Score)
+	for _, score := range report.Scores {
+		scoresByKpi[score.AppraisalKpiID] = append(scoresByKpi[score.AppraisalKpiID], score)
+	}
+	commentsByKpi := make(map[uint16][]models.Comment)
+	scoreKpis := make(map[uint16]uint16)
+	for _, score := range report.Scores {
+		scoreKpis[score.ID] = score.AppraisalKpiID
+	}
+	for _, comment := range report.Comments {
+		if comment.AppraisalKpiID != nil {
+			commentsByKpi[*comment.AppraisalKpiID] = append(commentsByKpi[*comment.AppraisalKpiID], comment)
+		} else if comment.ScoreID != nil {
+			commentsByKpi[scoreKpis[*comment.ScoreID]] = append(commentsByKpi[scoreKpis[*comment.ScoreID]], comment)
+		}
+	}
+
+	for _, appraisalKpi := range report.Kpis {
+		lines = append(lines, "", fmt.Sprintf("KPI: %s (%s, weight %d)", appraisalKpi.Kpi.KpiName, appraisalKpi.Kpi.KpiTypeStr, appraisalKpi.Kpi.KpiWeight))
+		for _, score := range scoresByKpi[appraisalKpi.ID] {
+			line := "  Score: " + scoreValue(score)
+			if score.TextAnswer != "" {
+				line += " - " + score.TextAnswer
+			}
+			lines = append(lines, line)
+		}
+		for _, comment := range commentsByKpi[appraisalKpi.ID] {
+			line := fmt.Sprintf("  %s (%s): %s", comment.AuthorName, comment.AuthorRole, comment.Body)
+			if comment.Visibility == constants.COMMENT_VISIBILITY_HR_PRIVATE {
+				line = "  [HR private]" + line[1:]
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func formatScore(score *float64) string {
+	if score == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*score, 'f', 2, 64)
+}
+
+func resultScore(score *float64, label string) string {
+	if score == nil || label == "" {
+		return formatScore(score)
+	}
+	return fmt.Sprintf("%s (%s)", formatScore(score), label)
+}
+
+func scoreValue(score models.Score) string {
+	switch {
+	case score.Score == nil:
+		return "-"
+	case score.ScoreLabel != "":
+		return fmt.Sprintf("%d (%s)", *score.Score, score.ScoreLabel)
+	default:
+		return strconv.Itoa(int(*score.Score))
+	}
+}
+
+func adjustedDetails(adjusted bool) string {
+	if adjusted {
+		return "(adjusted)"
+	}
+	return ""
+}
+
+func commentReference(comment models.Comment) string {
+	if comment.ScoreID != nil {
+		return fmt.Sprintf("score %d", *comment.ScoreID)
+	}
+	if comment.AppraisalKpiID != nil {
+		return fmt.Sprintf("kpi %d", *comment.AppraisalKpiID)
+	}
+	return ""
+}
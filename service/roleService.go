@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	"gorm.io/gorm"
 
 	"github.com/mrehanabbasi/appraisal-system-backend/controller"
@@ -27,8 +28,15 @@ func NewRoleService() *RoleService {
 	return &RoleService{Db: db}
 }
 
+// @summary List the roles
+// @query role_name string Name of the roles
+// @query is_active boolean Whether the roles are active
+// @success 200 []models.Role
 func (r *RoleService) GetAllRoles(c *gin.Context) {
 	log.Info("Initializing GetAllRoles handler function...")
+
+	ctx := c.Request.Context()
+
 	var role []models.Role
 	var err error
 
@@ -36,37 +44,39 @@ func (r *RoleService) GetAllRoles(c *gin.Context) {
 	isActive := c.Query("is_active")
 
 	if roleName != "" && isActive != "" {
-		err = r.Db.Table("roles").Where("role_name = ? AND is_active = ?", roleName, isActive).Find(&role).Error
+		err = r.Db.WithContext(ctx).Table("roles").Where("role_name = ? AND is_active = ?", roleName, isActive).Find(&role).Error
 	} else if roleName != "" {
-		err = r.Db.Table("roles").Where("role_name = ?", roleName).Find(&role).Error
+		err = r.Db.WithContext(ctx).Table("roles").Where("role_name = ?", roleName).Find(&role).Error
 	} else if isActive != "" {
-		err = r.Db.Table("roles").Where("is_active = ?", isActive).Find(&role).Error
+		err = r.Db.WithContext(ctx).Table("roles").Where("is_active = ?", isActive).Find(&role).Error
 	} else {
-		err = controller.GetAllRoles(r.Db, &role)
+		err = controller.GetAllRoles(r.Db.WithContext(ctx), &role)
 	}
 
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, role)
 }
 
+// @summary Get a role
+// @success 200 models.Role
 func (r *RoleService) GetRoleByID(c *gin.Context) {
 	log.Info("Initializing GetRolesByID handler function...")
+
+	ctx := c.Request.Context()
+
 	id, _ := strconv.Atoi(c.Param("id"))
 	var role models.Role
-	err := controller.GetRoleByID(r.Db, &role, id)
+	err := controller.GetRoleByID(r.Db.WithContext(ctx), &role, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error("No Role found against the provided id")
-			c.JSON(http.StatusNotFound, gin.H{"error": "No Role found against the provided id"})
+			respondError(c, apperrors.NotFound("No Role found against the provided id"))
 
 		} else {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(c, apperrors.Internal(err))
 		}
 
 		return
@@ -74,62 +84,73 @@ func (r *RoleService) GetRoleByID(c *gin.Context) {
 	c.JSON(http.StatusOK, role)
 }
 
+// @summary Create a role
+// @body models.Role
+// @success 200 models.Role
 func (r *RoleService) CreateRole(c *gin.Context) {
 	log.Info("Initializing CreateRole handler function...")
+
+	ctx := c.Request.Context()
+
 	var role models.Role
 	err := c.ShouldBindJSON(&role)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 
-	role, err = controller.CreateRole(r.Db, role)
+	role, err = controller.CreateRole(r.Db.WithContext(ctx), role)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, role)
 }
 
+// @summary Update a role
+// @body models.Role
+// @success 200 models.Role
 func (r *RoleService) UpdateRole(c *gin.Context) {
 	log.Info("Initializing UpdateRoles handler function...")
+
+	ctx := c.Request.Context()
+
 	var role models.Role
 	id, _ := strconv.Atoi(c.Param("id"))
-	err := controller.GetRoleByID(r.Db, &role, id)
+	err := controller.GetRoleByID(r.Db.WithContext(ctx), &role, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(c, apperrors.Internal(err))
 			return
 		}
 	}
 	err = c.ShouldBindJSON(&role)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
-	err = controller.UpdateRole(r.Db, &role)
+	err = controller.UpdateRole(r.Db.WithContext(ctx), &role)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 	c.JSON(http.StatusOK, role)
 }
 
+// @summary Delete a role
+// @success 204
 func (r *RoleService) DeleteRole(c *gin.Context) {
 	log.Info("Initializing DeleteRoles handler function...")
+
+	ctx := c.Request.Context()
+
 	var role models.Role
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 16)
 	role.ID = uint16(id)
-	err := controller.DeleteRole(r.Db, &role, role.ID)
+	err := controller.DeleteRole(r.Db.WithContext(ctx), &role, role.ID)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	"github.com/mrehanabbasi/appraisal-system-backend/database"
+	"github.com/mrehanabbasi/appraisal-system-backend/domain"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+type RosterService struct {
+	Db *gorm.DB
+}
+
+func NewRosterService() *RosterService {
+	db := database.DB
+	err := db.AutoMigrate(&models.RosterReconciliation{}, &models.RosterChange{})
+	if err != nil {
+		panic(err)
+	}
+
+	return &RosterService{Db: db}
+}
+
+// ProposeReconciliations compares the open team appraisals, or the one given by
+// appraisal_id, against the team rosters in TOSS and proposes the changes for
+// HR to approve. Appraisals whose roster is up to date get no proposal.
+//
+// @summary Propose the reconciliation of the open team appraisals with the TOSS rosters
+// @query appraisal_id integer Only reconcile this appraisal
+// @success 201 []models.RosterReconciliation
+func (s *RosterService) ProposeReconciliations(c *gin.Context) {
+	log.Info("Initializing ProposeReconciliations handler function...")
+
+	ctx := c.Request.Context()
+
+	appraisals := make([]models.Appraisal, 0)
+	db := s.Db.WithContext(ctx)
+	if appraisalID := c.Query("appraisal_id"); appraisalID != "" {
+		db = db.Where("id = ?", appraisalID)
+	}
+
+	if err := controller.GetOpenTeamAppraisals(db, &appraisals); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	if len(appraisals) == 0 && c.Query("appraisal_id") != "" {
+		respondError(c, apperrors.NotFound("No open team appraisal found against appraisal id"))
+		return
+	}
+
+	reconciliations := make([]models.RosterReconciliation, 0)
+	for k := range appraisals {
+		reconciliation, err := s.proposeReconciliation(ctx, &appraisals[k])
+		if err != nil {
+			log.Error("failed to reconcile appraisal ", appraisals[k].ID)
+			respondError(c, err)
+			return
+		}
+		if reconciliation != nil {
+			reconciliations = append(reconciliations, *reconciliation)
+		}
+	}
+
+	c.JSON(http.StatusCreated, reconciliations)
+}
+
+func (s *RosterService) proposeReconciliation(ctx context.Context, appraisal *models.Appraisal) (*models.RosterReconciliation, error) {
+	rosterIDs, err := utils.GetEmployeesId(ctx, appraisal.SelectedFieldID)
+	if err != nil {
+		return nil, apperrors.Upstream("failed to fetch employee IDs", err)
+	}
+
+	memberIDs, err := controller.GetAppraisalEmployeeIDs(s.Db.WithContext(ctx), appraisal.ID)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	members := make(map[uint16]bool, len(memberIDs))
+	for _, memberID := range memberIDs {
+		members[memberID] = true
+	}
+
+	joinerIDs := make([]uint16, 0)
+	for _, empID := range rosterIDs {
+		if !members[empID] {
+			joinerIDs = append(joinerIDs, empID)
+		}
+	}
+
+	// The joiners are looked up the same way the employees of a new appraisal are
+	details, err := domain.LookupEmployees(ctx, joinerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	joiners := make([]models.RosterChange, 0, len(details))
+	for _, detail := range details {
+		joiners = append(joiners, models.RosterChange{
+			EmployeeID:      detail.EmployeeID,
+			EmployeeName:    detail.EmployeeName,
+			Designation:     detail.Designation,
+			DesignationName: detail.DesignationName,
+			EmployeeImage:   os.Getenv("TOSS_BASE_URL") + "/" + detail.EmployeeImage,
+		})
+	}
+
+	reconciliation, err := controller.ProposeRosterReconciliation(s.Db.WithContext(ctx), appraisal, rosterIDs, joiners)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return reconciliation, nil
+}
+
+// @summary List the roster reconciliations
+// @query appraisal_id integer Appraisal of the reconciliations
+// @query status string Status of the reconciliations
+// @success 200 []models.RosterReconciliation
+func (s *RosterService) GetReconciliations(c *gin.Context) {
+	log.Info("Initializing GetReconciliations handler function...")
+
+	ctx := c.Request.Context()
+
+	reconciliations := make([]models.RosterReconciliation, 0)
+	db := s.Db.WithContext(ctx).Model(&models.RosterReconciliation{})
+
+	appraisalID := c.Query("appraisal_id")
+	status := c.Query("status")
+
+	if appraisalID != "" {
+		db = db.Where("appraisal_id = ?", appraisalID)
+	}
+
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	if err := controller.GetRosterReconciliations(db, &reconciliations); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliations)
+}
+
+// @summary Get a roster reconciliation
+// @success 200 models.RosterReconciliation
+func (s *RosterService) GetReconciliationByID(c *gin.Context) {
+	log.Info("Initializing GetReconciliationByID handler function...")
+
+	ctx := c.Request.Context()
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var reconciliation models.RosterReconciliation
+	err := controller.GetRosterReconciliationByID(s.Db.WithContext(ctx), &reconciliation, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against reconciliation id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliation)
+}
+
+// rosterApproval is the payload of the job applying an approved reconciliation
+type rosterApproval struct {
+	ReconciliationID uint64  `json:"reconciliation_id"`
+	ReviewedBy       uint16  `json:"reviewed_by"`
+	ChangeIDs        []int64 `json:"change_ids"`
+	Notes            string  `json:"notes"`
+}
+
+// ApproveReconciliation queues the application of the proposed changes, or only
+// of the ones listed in change_ids. Enrolling employees takes several TOSS calls
+// each, so the changes are applied by a background job.
+//
+// @summary Queue the application of the proposed changes of a roster reconciliation
+// @body models.RosterReview
+// @success 202 models.Job
+// @auth
+func (s *RosterService) ApproveReconciliation(c *gin.Context) {
+	log.Info("Initializing ApproveReconciliation handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getHRTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var request models.RosterReview
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, request.Validate()); !ok {
+		return
+	}
+
+	// Reviewed reconciliations are refused right away rather than by the job
+	var reconciliation models.RosterReconciliation
+	err := controller.GetRosterReconciliationByID(s.Db.WithContext(ctx), &reconciliation, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Record not found against reconciliation id"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+	if reconciliation.Status != constants.RECONCILIATION_STATUS_PROPOSED {
+		respondError(c, apperrors.Wrap(apperrors.KindConflict, "", controller.ErrReconciliationReviewed))
+		return
+	}
+
+	job, err := enqueueJob(ctx, constants.JOB_TYPE_APPLY_ROSTER_RECONCILIATION, rosterApproval{
+		ReconciliationID: id,
+		ReviewedBy:       tokenInfo.EmpID,
+		ChangeIDs:        request.ChangeIDs,
+		Notes:            request.Notes,
+	})
+	if err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	respondJobAccepted(c, job)
+}
+
+// RunApplyReconciliation is the job applying the reconciliation approved by
+// ApproveReconciliation. The reconciliation is its result.
+func (s *RosterService) RunApplyReconciliation(ctx context.Context, job *models.Job, progress *controller.JobProgress) error {
+	var approval rosterApproval
+	if err := json.Unmarshal([]byte(job.Payload), &approval); err != nil {
+		return err
+	}
+
+	if err := progress.SetTotal(1); err != nil {
+		return err
+	}
+
+	review := models.RosterReview{
+		ReviewedBy: approval.ReviewedBy,
+		ChangeIDs:  approval.ChangeIDs,
+		Notes:      approval.Notes,
+	}
+	reconciliation, err := controller.ApplyRosterReconciliation(s.Db.WithContext(ctx), approval.ReconciliationID, &review)
+	if err != nil {
+		return err
+	}
+
+	if err := progress.ItemSucceeded(); err != nil {
+		return err
+	}
+	return progress.SetResult(reconciliation.ID, fmt.Sprintf("/v1/roster_reconciliations/%d", reconciliation.ID))
+}
+
+// RejectReconciliation lets HR reject a reconciliation as the caller
+//
+// @summary Reject a roster reconciliation
+// @body models.RosterReview
+// @success 200 models.RosterReconciliation
+// @auth
+func (s *RosterService) RejectReconciliation(c *gin.Context) {
+	log.Info("Initializing RejectReconciliation handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getHRTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.ParseUint(c.Param("id"), 0, 16)
+
+	var request models.RosterReview
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, request.Validate()); !ok {
+		return
+	}
+	request.ReviewedBy = tokenInfo.EmpID
+
+	reconciliation, err := controller.RejectRosterReconciliation(s.Db.WithContext(ctx), id, &request)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			respondError(c, apperrors.NotFound("Record not found against reconciliation id"))
+		case errors.Is(err, controller.ErrReconciliationReviewed):
+			respondError(c, apperrors.Wrap(apperrors.KindConflict, "", err))
+		default:
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliation)
+}
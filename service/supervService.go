@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
 	"gorm.io/gorm"
 
 	"github.com/mrehanabbasi/appraisal-system-backend/controller"
@@ -28,29 +29,30 @@ func NewSupervisorService() *SupervisorService {
 const supervisorRoleName = "supervisor"
 
 // Create Supervisor (handler)
+//
+// @summary Create a supervisor
+// @body models.Supervisor
+// @success 201 models.Employee
 func (sc *SupervisorService) ConvertSupervisorToEmployee(c *gin.Context) {
 	log.Info("Initializing ConvertSupervisorToEmployee handler function...")
 	// Get the supervisor data from the request body
 	var req models.Supervisor
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 
 	// Get the supervisor role from the roles table
 	var supervisorRole models.Role
-	if err := sc.db.Table("roles").Where("role_name = ?", supervisorRoleName).First(&supervisorRole).Error; err != nil {
-		log.Error("supervisor role does not exist")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "supervisor role does not exist"})
+	if err := sc.db.WithContext(c.Request.Context()).Table("roles").Where("role_name = ?", supervisorRoleName).First(&supervisorRole).Error; err != nil {
+		respondError(c, apperrors.Invalid("supervisor role does not exist"))
 		return
 	}
 
 	// Create a new employee with supervisor role
-	employee, err := controller.CreateSupervisor(sc.db, req.Name, req.Email, supervisorRoleName, uint(supervisorRole.ID))
+	employee, err := controller.CreateSupervisor(sc.db.WithContext(c.Request.Context()), req.Name, req.Email, supervisorRoleName, uint(supervisorRole.ID))
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
@@ -62,13 +64,16 @@ func (sc *SupervisorService) ConvertSupervisorToEmployee(c *gin.Context) {
 }
 
 // GET Supervisors from employee table(with query parameter) Handler
+//
+// @summary List the supervisors
+// @query name string Name of the supervisors
+// @success 200 []models.Employee
 func (sc *SupervisorService) GetSupervisors(c *gin.Context) {
 	name := c.Query("name")
 
-	supervisors, err := controller.GetSupervisorsWithQuery(sc.db, name)
+	supervisors, err := controller.GetSupervisorsWithQuery(sc.db.WithContext(c.Request.Context()), name)
 	if err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
@@ -77,16 +82,18 @@ func (sc *SupervisorService) GetSupervisors(c *gin.Context) {
 }
 
 // Get Supervisor by ID (handler)
+//
+// @summary Get a supervisor
+// @success 200 models.Employee
 func (sc *SupervisorService) GetSupervisorById(c *gin.Context) {
 	log.Info("Initializing GetSupervisorById handler function...")
 	// Get the supervisor ID from the request parameters
 	supervisorId := c.Param("id")
 
 	// Get the supervisor from the database
-	supervisor, err := controller.GetSupervisorByIdDB(sc.db, supervisorId)
+	supervisor, err := controller.GetSupervisorByIdDB(sc.db.WithContext(c.Request.Context()), supervisorId)
 	if err != nil {
-		log.Error("supervisor not found")
-		c.JSON(http.StatusNotFound, gin.H{"error": "supervisor not found"})
+		respondError(c, apperrors.NotFound("supervisor not found"))
 		return
 	}
 
@@ -95,6 +102,10 @@ func (sc *SupervisorService) GetSupervisorById(c *gin.Context) {
 }
 
 // Update Supervisor by ID (Handler)
+//
+// @summary Update a supervisor
+// @body models.Supervisor
+// @success 200 models.Employee
 func (sc *SupervisorService) UpdateSupervisor(c *gin.Context) {
 	log.Info("Initializing UpdateSupervisor handler function...")
 	// Get the supervisor ID from the request parameters
@@ -103,28 +114,24 @@ func (sc *SupervisorService) UpdateSupervisor(c *gin.Context) {
 	// Get the updated supervisor data from the request body
 	var req models.Supervisor
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Validation(err))
 		return
 	}
 
 	// Update the supervisor in the database
-	if err := controller.UpdateSupervisorInDatabase(sc.db, supervisorId, req); err != nil {
+	if err := controller.UpdateSupervisorInDatabase(sc.db.WithContext(c.Request.Context()), supervisorId, req); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			log.Error("supervisor not found")
-			c.JSON(http.StatusNotFound, gin.H{"error": "supervisor not found"})
+			respondError(c, apperrors.NotFound("supervisor not found"))
 			return
 		}
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
 	// Query the employees table for the updated supervisor
 	var updatedSupervisor models.Employee
-	if err := sc.db.Table("employees").Where("id = ?", supervisorId).First(&updatedSupervisor).Error; err != nil {
-		log.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := sc.db.WithContext(c.Request.Context()).Table("employees").Where("id = ?", supervisorId).First(&updatedSupervisor).Error; err != nil {
+		respondError(c, apperrors.Internal(err))
 		return
 	}
 
@@ -133,15 +140,17 @@ func (sc *SupervisorService) UpdateSupervisor(c *gin.Context) {
 }
 
 // DeleteSupervisor deletes a supervisor from the employee table (handler)
+//
+// @summary Delete a supervisor
+// @success 204
 func (sc *SupervisorService) DeleteSupervisor(c *gin.Context) {
 	log.Info("Initializing DeleteSupervisor handler function...")
 	// Get the supervisor ID from the request parameters
 	supervisorId := c.Param("id")
 
 	// Call the database function to delete the supervisor
-	if err := controller.DeleteSupervisorFromDB(sc.db, supervisorId); err != nil {
-		log.Error("supervisor not found")
-		c.JSON(http.StatusNotFound, gin.H{"error": "supervisor not found"})
+	if err := controller.DeleteSupervisorFromDB(sc.db.WithContext(c.Request.Context()), supervisorId); err != nil {
+		respondError(c, apperrors.NotFound("supervisor not found"))
 		return
 	}
 
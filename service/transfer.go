@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"github.com/mrehanabbasi/appraisal-system-backend/models"
+	"github.com/mrehanabbasi/appraisal-system-backend/utils"
+	"gorm.io/gorm"
+)
+
+// TransferEmployee moves the employee of an in-flight appraisal to the supervisor
+// and team they moved to in TOSS. The designation of the employee is refreshed
+// from TOSS as well.
+//
+// @summary Transfer the employee of an appraisal to another supervisor
+// @body models.Transfer
+// @success 201 models.Transfer
+// @auth
+func (r *AppraisalService) TransferEmployee(c *gin.Context) {
+	log.Info("Initializing TransferEmployee handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getHRTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	appraisalID, err := strconv.ParseUint(c.Param("id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Invalid("invalid appraisal id"))
+		return
+	}
+	employeeID, err := strconv.ParseUint(c.Param("emp_id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Invalid("invalid employee id"))
+		return
+	}
+
+	var transfer models.Transfer
+	if err := c.ShouldBindJSON(&transfer); err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	if ok := validateStruct(c, transfer.Validate()); !ok {
+		return
+	}
+	transfer.ID = 0
+	transfer.AppraisalID = uint16(appraisalID)
+	transfer.EmployeeID = uint16(employeeID)
+	transfer.RemovedKpiIDs = nil
+	transfer.AddedKpiIDs = nil
+	transfer.TransferredBy = tokenInfo.EmpID
+	transfer.EffectiveAt = time.Now()
+
+	if !transfer.SplitScoring {
+		transfer.PreviousSupervisorWeight = 0
+	} else if transfer.PreviousSupervisorWeight <= 0 || transfer.PreviousSupervisorWeight >= 100 {
+		respondError(c, apperrors.Invalid("previous_supervisor_weight should be between 0 and 100 when scoring is split"))
+		return
+	}
+
+	errCode, teamName, err := utils.VerifyTeamAndSupervisorID(ctx, transfer.ToTeamID, transfer.ToSupervisorID)
+	if err != nil {
+		respondError(c, apperrors.FromStatus(errCode, err))
+		return
+	}
+	transfer.ToTeamName = teamName
+
+	// The employee should already be a member of the new team in TOSS
+	teamMemberIDs, err := utils.GetEmployeesId(ctx, transfer.ToTeamID)
+	if err != nil {
+		respondError(c, apperrors.Upstream("failed to fetch team members", err))
+		return
+	}
+	isMember := false
+	for _, memberID := range teamMemberIDs {
+		if memberID == transfer.EmployeeID {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		respondError(c, apperrors.Invalid("employee is not a member of the selected team in TOSS"))
+		return
+	}
+
+	supervisorName, err := utils.GetSupervisorName(ctx, transfer.ToSupervisorID)
+	if err != nil {
+		respondError(c, apperrors.Upstream("failed to get supervisor name", err))
+		return
+	}
+	transfer.ToSupervisorName = supervisorName
+
+	roleIDs, err := utils.GetRolesID(ctx, []uint16{transfer.EmployeeID})
+	if err != nil {
+		respondError(c, apperrors.Upstream("failed to fetch role IDs", err))
+		return
+	}
+	transfer.ToDesignation = roleIDs[0]
+
+	designationName, err := utils.GetDesignationName(ctx, transfer.ToDesignation)
+	if err != nil {
+		respondError(c, apperrors.Upstream("failed to get designation name", err))
+		return
+	}
+	transfer.ToDesignationName = designationName
+
+	dbTransfer, err := controller.TransferEmployee(r.Db.WithContext(ctx), &transfer)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("Employee not found against the appraisal"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dbTransfer)
+}
+
+// @summary List the transfers of the employee of an appraisal
+// @success 200 []models.Transfer
+func (r *AppraisalService) GetTransfers(c *gin.Context) {
+	log.Info("Initializing GetTransfers handler function...")
+
+	ctx := c.Request.Context()
+
+	appraisalID, _ := strconv.ParseUint(c.Param("id"), 0, 64)
+	employeeID, _ := strconv.ParseUint(c.Param("emp_id"), 0, 64)
+
+	transfers := make([]models.Transfer, 0)
+	if err := controller.GetTransfers(r.Db.WithContext(ctx), &transfers, appraisalID, employeeID); err != nil {
+		respondError(c, apperrors.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
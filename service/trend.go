@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mrehanabbasi/appraisal-system-backend/apperrors"
+	"github.com/mrehanabbasi/appraisal-system-backend/controller"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+	"gorm.io/gorm"
+)
+
+// GetPerformanceHistory returns the results of an employee across all their
+// appraisals with the score trends and the year over year deltas
+//
+// @summary Get the performance history of an employee across their appraisals
+// @success 200 models.PerformanceHistory
+func (s *AnalyticsService) GetPerformanceHistory(c *gin.Context) {
+	log.Info("Initializing GetPerformanceHistory handler function...")
+
+	ctx := c.Request.Context()
+
+	empID, err := strconv.ParseUint(c.Param("emp_id"), 0, 16)
+	if err != nil {
+		respondError(c, apperrors.Validation(err))
+		return
+	}
+
+	history, err := controller.GetPerformanceHistory(s.Db.WithContext(ctx), uint16(empID), false)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No appraisals found against the employee"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetMyPerformanceHistory returns the history of the caller over the appraisals
+// with published results
+//
+// @summary Get the performance history of the caller
+// @success 200 models.PerformanceHistory
+// @auth
+func (s *MeService) GetMyPerformanceHistory(c *gin.Context) {
+	log.Info("Initializing GetMyPerformanceHistory handler function...")
+
+	ctx := c.Request.Context()
+
+	tokenInfo, ok := getTokenInfo(c)
+	if !ok {
+		return
+	}
+
+	history, err := controller.GetPerformanceHistory(s.Db.WithContext(ctx), tokenInfo.EmpID, true)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, apperrors.NotFound("No published results found"))
+		} else {
+			respondError(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
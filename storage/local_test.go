@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	local := NewLocalStorage(root)
+	ctx := context.Background()
+
+	key := "appraisals/7/evidence.pdf"
+	data := []byte("%PDF-1.4 evidence")
+
+	if err := local.Put(ctx, key, data, "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "appraisals", "7", "evidence.pdf")); err != nil {
+		t.Fatalf("file is not under the root: %v", err)
+	}
+
+	file, err := local.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Fatalf("Get returned %q, want %q", got, data)
+	}
+
+	if err := local.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := local.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalStorageRefusesKeysEscapingTheRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "attachments")
+	local := NewLocalStorage(root)
+	ctx := context.Background()
+
+	secret := filepath.Join(parent, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "/", "..", "../secret.txt", "appraisals/../../secret.txt", "/../secret.txt", `..\secret.txt`} {
+		if err := local.Put(ctx, key, []byte("overwritten"), "text/plain"); err == nil {
+			t.Errorf("Put accepted the key %q", key)
+		}
+		if file, err := local.Get(ctx, key); err == nil {
+			file.Close()
+			t.Errorf("Get accepted the key %q", key)
+		}
+		if err := local.Delete(ctx, key); err == nil {
+			t.Errorf("Delete accepted the key %q", key)
+		}
+	}
+
+	data, err := os.ReadFile(secret)
+	if err != nil || string(data) != "secret" {
+		t.Fatalf("file outside of the root was changed: %q, %v", data, err)
+	}
+
+	// Absolute keys stay under the root
+	if err := local.Put(ctx, "/appraisals/7/evidence.pdf", []byte("evidence"), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "appraisals", "7", "evidence.pdf")); err != nil {
+		t.Fatalf("file of an absolute key is not under the root: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// Scanner checks an uploaded file for malware before it is stored
+type Scanner interface {
+	Scan(ctx context.Context, fileName string, data []byte) error
+}
+
+// ErrInfected is returned by a Scanner for a file that must not be stored
+var ErrInfected = errors.New("file failed the virus scan")
+
+// NoopScanner accepts every file. It is the default until a real scanner is
+// hooked in through FileScanner.
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(ctx context.Context, fileName string, data []byte) error {
+	return nil
+}
+
+// FileScanner is the scanner every attachment goes through
+var FileScanner Scanner = NoopScanner{}
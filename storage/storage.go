@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+)
+
+// Storage keeps the attachment files. Keys are slash separated paths.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrNotFound is returned by Get for a key without a file
+var ErrNotFound = errors.New("file not found in storage")
+
+// Store is the storage configured by Connect
+var Store Storage
+
+// Connect configures Store from the environment. STORAGE_DRIVER selects the
+// local filesystem (default) or an S3-compatible object storage.
+func Connect() {
+	switch strings.ToLower(os.Getenv("STORAGE_DRIVER")) {
+	case "s3":
+		s3, err := NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+		if err != nil {
+			panic(err)
+		}
+		Store = s3
+		log.Info("Using S3 storage for attachments")
+	default:
+		root := os.Getenv("STORAGE_LOCAL_PATH")
+		if root == "" {
+			root = "attachments"
+		}
+		Store = NewLocalStorage(root)
+		log.Info("Using local storage for attachments")
+	}
+}
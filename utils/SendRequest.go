@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"os"
 	"time"
@@ -9,8 +10,8 @@ import (
 	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
 )
 
-func SendRequest(method string, url string, body []byte) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+func SendRequest(ctx context.Context, method string, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
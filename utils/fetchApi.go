@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,7 +14,7 @@ import (
 	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
 )
 
-func GetRolesID(empIds []uint16) ([]uint16, error) {
+func GetRolesID(ctx context.Context, empIds []uint16) ([]uint16, error) {
 	tossBaseUrl := os.Getenv("TOSS_BASE_URL") // Get the TOSS base URL from environment variable
 	method := http.MethodGet                  // HTTP method for sending the request
 
@@ -22,7 +23,7 @@ func GetRolesID(empIds []uint16) ([]uint16, error) {
 	for _, empId := range empIds {
 		url := tossBaseUrl + "/api/Employee/" + strconv.FormatUint(uint64(empId), 10) // Construct the URL for fetching employee details based on empId
 
-		resp, err := SendRequest(method, url, nil) // Send the HTTP request to the specified URL
+		resp, err := SendRequest(ctx, method, url, nil) // Send the HTTP request to the specified URL
 		if err != nil {
 			log.Error(err.Error())
 			return nil, err
@@ -71,32 +72,13 @@ type ProjectResponse struct {
 	ProjectEmployees []Employee `json:"projectEmployees"`
 }
 
-func GetEmployeesId(teamID uint16) ([]uint16, error) {
-
-	tossBaseUrl := os.Getenv("TOSS_BASE_URL")
-	method := http.MethodGet
-	url := tossBaseUrl + "/api/Project/AllProjectsWithEmployeesList?IsActive=true"
-
-	resp, err := SendRequest(method, url, nil)
-	if err != nil {
-		log.Error(err.Error())
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
+func GetEmployeesId(ctx context.Context, teamID uint16) ([]uint16, error) {
+	projects, err := GetTossProjects(ctx)
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
 
-	var projects []ProjectResponse
-
-	if err := json.Unmarshal(responseBody, &projects); err != nil {
-		log.Error(err.Error())
-		return nil, err
-	}
-
 	var employeeIDs []uint16
 
 	for _, project := range projects {
@@ -113,31 +95,12 @@ func GetEmployeesId(teamID uint16) ([]uint16, error) {
 	return employeeIDs, nil
 }
 
-func VerifyTeamAndSupervisorID(teamID, supervisorID uint16) (int, string, error) {
-	tossBaseUrl := os.Getenv("TOSS_BASE_URL")
-
-	method := http.MethodGet
-	url := tossBaseUrl + "/api/Project/AllProjectsWithEmployeesList?IsActive=true"
-
-	resp, err := SendRequest(method, url, nil)
+func VerifyTeamAndSupervisorID(ctx context.Context, teamID, supervisorID uint16) (int, string, error) {
+	projects, err := GetTossProjects(ctx)
 	if err != nil {
 		log.Error(err.Error())
 		return http.StatusInternalServerError, "", err
 	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Error(err.Error())
-		return http.StatusInternalServerError, "", err
-	}
-
-	var projects []ProjectResponse
-
-	if err := json.Unmarshal(responseBody, &projects); err != nil {
-		log.Error(err.Error())
-		return http.StatusInternalServerError, "", err
-	}
 
 	var teamName string
 	foundTeam, foundSupervisor := false, false
@@ -171,33 +134,12 @@ func VerifyTeamAndSupervisorID(teamID, supervisorID uint16) (int, string, error)
 	return 0, trimmedStr, nil
 }
 
-func GetSupervisorName(SprID uint16) (string, error) {
-	tossBaseUrl := os.Getenv("TOSS_BASE_URL") // Get the TOSS base URL from the environment variable
-	method := http.MethodGet                  // HTTP method for sending the request
-
-	url := tossBaseUrl + "/api/Project/AllProjectsWithEmployeesList?IsActive=true" // Construct the URL for fetching all projects
-
-	resp, err := SendRequest(method, url, nil) // Send the HTTP request to the specified URL
+func GetSupervisorName(ctx context.Context, SprID uint16) (string, error) {
+	projects, err := GetTossProjects(ctx)
 	if err != nil {
+		log.Error(err.Error())
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		errMsg := "Failed to get supervisor name for supervisor ID: " + strconv.Itoa(int(SprID)) + ". Status code: " + strconv.Itoa(resp.StatusCode)
-		return "", errors.New(errMsg) // Return an error if the response status code is not OK
-	}
-
-	var projects []ProjectResponse
-
-	responseBody, err := io.ReadAll(resp.Body) // Read the response body
-	if err != nil {
-		return "", err // Return an error if there's an issue reading the response body
-	}
-
-	if err := json.Unmarshal(responseBody, &projects); err != nil {
-		return "", err // Return an error if there's an issue unmarshaling the JSON response
-	}
 
 	for _, project := range projects {
 		for _, employee := range project.ProjectEmployees {
@@ -210,30 +152,12 @@ func GetSupervisorName(SprID uint16) (string, error) {
 	return "", errors.New("supervisor not found") // Return an error if the supervisor ID is not found in the projects
 }
 
-func VerifyIndividualAndSupervisorID(indID, supervisorID uint16) (int, string, error) {
-	tossBaseUrl := os.Getenv("TOSS_BASE_URL")
-	method := http.MethodGet
-	url := tossBaseUrl + "/api/Project/AllProjectsWithEmployeesList?IsActive=true"
-
-	resp, err := SendRequest(method, url, nil)
+func VerifyIndividualAndSupervisorID(ctx context.Context, indID, supervisorID uint16) (int, string, error) {
+	projects, err := GetTossProjects(ctx)
 	if err != nil {
 		log.Error(err.Error())
 		return http.StatusInternalServerError, "", err
 	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Error(err.Error())
-		return http.StatusInternalServerError, "", err
-	}
-
-	var projects []ProjectResponse
-
-	if err := json.Unmarshal(responseBody, &projects); err != nil {
-		log.Error(err.Error())
-		return http.StatusInternalServerError, "", err
-	}
 
 	var empName string
 	foundIndividual, foundSupervisor := false, false
@@ -268,13 +192,13 @@ func VerifyIndividualAndSupervisorID(indID, supervisorID uint16) (int, string, e
 	return 0, empName, nil
 }
 
-func GetDesignationName(DesignationID uint16) (string, error) {
+func GetDesignationName(ctx context.Context, DesignationID uint16) (string, error) {
 	tossBaseUrl := os.Getenv("TOSS_BASE_URL") // Get the TOSS base URL from environment variable
 	method := http.MethodGet                  // HTTP method for sending the request
 
 	url := tossBaseUrl + "/api/Designation/" + strconv.FormatUint(uint64(DesignationID), 10) // Construct the URL for fetching designation details based on designation ID
 
-	resp, err := SendRequest(method, url, nil) // Send the HTTP request to the specified URL
+	resp, err := SendRequest(ctx, method, url, nil) // Send the HTTP request to the specified URL
 	if err != nil {
 		log.Error(err.Error())
 		return "", err
@@ -306,76 +230,28 @@ func GetDesignationName(DesignationID uint16) (string, error) {
 	return designation.DesignationName, nil // Return the designation name
 }
 
-func GetEmployeeIDsByDesignation(designation uint16) ([]uint16, error) {
-	tossBaseURL := os.Getenv("TOSS_BASE_URL") // Get the TOSS base URL from the environment variable
-	method := http.MethodGet                  // HTTP method for sending the request
-
-	url := tossBaseURL + "/api/Employee/GetAllEmployeesInfo?Status=1&PageSize=10000&Designation=" + strconv.Itoa(int(designation))
-
-	resp, err := SendRequest(method, url, nil) // Send the HTTP request to the specified URL
+// GetEmployeeIDsByDesignation returns the active employees of a designation
+func GetEmployeeIDsByDesignation(ctx context.Context, designation uint16) ([]uint16, error) {
+	employees, err := GetTossActiveEmployeesByDesignation(ctx, designation)
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		errMsg := fmt.Sprintf("failed to get employee IDs for designation '%d'. status code: %d", designation, resp.StatusCode)
-		log.Error(errMsg)
-		return nil, errors.New(errMsg) // Return an error if the response status code is not OK
-	}
-
-	responseBody, err := io.ReadAll(resp.Body) // Read the response body
-	if err != nil {
-		log.Error(err.Error())
-		return nil, err // Return an error if there's an issue reading the response body
-	}
-
-	type EmployeeInfo struct {
-		ID   uint16 `json:"id"`
-		Name string `json:"name"`
-	}
-
-	var response struct {
-		EmployeeInfo []EmployeeInfo `json:"employeeInfo"`
-	}
-
-	if err := json.Unmarshal(responseBody, &response); err != nil {
-		log.Error(err.Error())
-		return nil, err // Return an error if there's an issue unmarshaling the JSON response
-	}
 
-	employeeIDs := make([]uint16, 0, len(response.EmployeeInfo))
-	for _, employee := range response.EmployeeInfo {
-		employeeIDs = append(employeeIDs, employee.ID) // Append the employee ID to the slice
+	employeeIDs := make([]uint16, 0, len(employees))
+	for _, employee := range employees {
+		employeeIDs = append(employeeIDs, employee.EmployeeID) // Append the employee ID to the slice
 	}
 
 	return employeeIDs, nil // Return the list of employee IDs
 }
-func GetProjectDetailsByEmployeeID(employeeID uint16) ([]ProjectResponse, error) {
-	tossBaseUrl := os.Getenv("TOSS_BASE_URL") // Get the TOSS base URL from environment variable
-	method := http.MethodGet                  // HTTP method for sending the request
-	url := tossBaseUrl + "/api/Project/AllProjectsWithEmployeesList?IsActive=true"
 
-	resp, err := SendRequest(method, url, nil) // Send the HTTP request to fetch all projects
+func GetProjectDetailsByEmployeeID(ctx context.Context, employeeID uint16) ([]ProjectResponse, error) {
+	projects, err := GetTossProjects(ctx)
 	if err != nil {
 		log.Error(err.Error())
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Error(err.Error())
-		return nil, err
-	}
-
-	var projects []ProjectResponse
-
-	if err := json.Unmarshal(responseBody, &projects); err != nil {
-		log.Error(err.Error())
-		return nil, err
-	}
 
 	var projectDetails []ProjectResponse
 
@@ -395,14 +271,14 @@ type EmployeeImage struct {
 	EmployeeImage string `json:"employeeImage"`
 }
 
-func GetEmployeeImageByID(employeeID uint64) (string, error) {
+func GetEmployeeImageByID(ctx context.Context, employeeID uint64) (string, error) {
 
 	tossBaseURL := os.Getenv("TOSS_BASE_URL") // Get the TOSS base URL from the environment variable
 	method := http.MethodGet                  // HTTP method for sending the request
 
 	url := tossBaseURL + "/api/Employee/" + strconv.FormatUint(uint64(employeeID), 10)
 
-	resp, err := SendRequest(method, url, nil) // Send the HTTP request to fetch all projects
+	resp, err := SendRequest(ctx, method, url, nil) // Send the HTTP request to fetch all projects
 	if err != nil {
 		log.Error(err.Error())
 		return "", err
@@ -422,3 +298,72 @@ func GetEmployeeImageByID(employeeID uint64) (string, error) {
 
 	return employeeImage.EmployeeImage, nil
 }
+
+// GetSkipLevelSupervisor returns the ID and name of the supervisor of the given
+// supervisor, resolved from the project supervisors in TOSS
+func GetSkipLevelSupervisor(ctx context.Context, supervisorID uint16) (uint16, string, error) {
+	projects, err := GetTossProjects(ctx)
+	if err != nil {
+		log.Error(err.Error())
+		return 0, "", err
+	}
+
+	// Find who supervises the supervisor in any of their projects
+	skipLevelName := ""
+	for _, project := range projects {
+		for _, employee := range project.ProjectEmployees {
+			if employee.EmployeeID == supervisorID && employee.EmployeeProjectSupervisor != "" &&
+				employee.EmployeeProjectSupervisor != employee.EmployeeName {
+				skipLevelName = employee.EmployeeProjectSupervisor
+				break
+			}
+		}
+		if skipLevelName != "" {
+			break
+		}
+	}
+
+	if skipLevelName == "" {
+		return 0, "", errors.New("skip-level supervisor not found")
+	}
+
+	// Resolve the name of the skip-level supervisor to their employee ID
+	for _, project := range projects {
+		for _, employee := range project.ProjectEmployees {
+			if employee.EmployeeName == skipLevelName {
+				return employee.EmployeeID, employee.EmployeeName, nil
+			}
+		}
+	}
+
+	return 0, "", errors.New("skip-level supervisor not found")
+}
+
+// GetTeamSupervisor returns the supervisor of a project: the project supervisor
+// most of its employees report to, the same way projects are listed for new
+// appraisals. Ties go to the supervisor listed first.
+func GetTeamSupervisor(project ProjectResponse) (uint16, string, error) {
+	reports := make(map[string]int)
+	for _, employee := range project.ProjectEmployees {
+		if employee.EmployeeProjectSupervisor != "" && employee.EmployeeProjectSupervisor != employee.EmployeeName {
+			reports[employee.EmployeeProjectSupervisor]++
+		}
+	}
+
+	var supervisorID uint16
+	supervisorName := ""
+	for _, employee := range project.ProjectEmployees {
+		if employee.EmployeeName != employee.EmployeeProjectSupervisor {
+			continue
+		}
+		if supervisorName == "" || reports[employee.EmployeeName] > reports[supervisorName] {
+			supervisorID, supervisorName = employee.EmployeeID, employee.EmployeeName
+		}
+	}
+
+	if supervisorName == "" {
+		return 0, "", fmt.Errorf("no supervisor found for team %d", project.ProjectID)
+	}
+
+	return supervisorID, strings.Trim(supervisorName, "\r\n"), nil
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+)
+
+type DesignationInfo struct {
+	Value uint16 `json:"value"`
+	Label string `json:"label"`
+}
+
+// EmployeeInfo is an employee of the TOSS directory. The designation is only
+// known for the active employees listed by designation.
+type EmployeeInfo struct {
+	EmployeeID  uint16 `json:"employeeId"`
+	Name        string `json:"name"`
+	Designation uint16 `json:"-"`
+	IsActive    bool   `json:"-"`
+}
+
+// Directory serves the TOSS directory from the local mirror kept by the sync
+// job. The TOSS functions fall back to it when TOSS cannot be reached, unless
+// the request was cancelled.
+type Directory interface {
+	Projects(ctx context.Context) ([]ProjectResponse, error)
+	Designations(ctx context.Context) ([]DesignationInfo, error)
+	Employees(ctx context.Context) ([]EmployeeInfo, error)
+}
+
+// LocalDirectory is set once the local mirror is available
+var LocalDirectory Directory
+
+// getTossJSON sends a GET request to the TOSS endpoint and unmarshals the
+// response into v
+func getTossJSON(ctx context.Context, endpoint string, v interface{}) error {
+	url := os.Getenv("TOSS_BASE_URL") + endpoint
+
+	resp, err := SendRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errMsg := fmt.Sprintf("request to %s failed with status code %d", endpoint, resp.StatusCode)
+		log.Error(errMsg)
+		return errors.New(errMsg)
+	}
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	if err := json.Unmarshal(responseBody, v); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// FetchTossProjects returns the active projects with their employees from TOSS
+func FetchTossProjects(ctx context.Context) ([]ProjectResponse, error) {
+	var projects []ProjectResponse
+	err := getTossJSON(ctx, "/api/Project/AllProjectsWithEmployeesList?IsActive=true", &projects)
+	return projects, err
+}
+
+// FetchTossDesignations returns the designations from TOSS
+func FetchTossDesignations(ctx context.Context) ([]DesignationInfo, error) {
+	var response struct {
+		Designations []DesignationInfo `json:"designations"`
+	}
+	err := getTossJSON(ctx, "/api/Employee/GetDesignationsList", &response)
+	return response.Designations, err
+}
+
+// FetchTossEmployees returns all the employees from TOSS, active or not
+func FetchTossEmployees(ctx context.Context) ([]EmployeeInfo, error) {
+	var employees []EmployeeInfo
+	err := getTossJSON(ctx, "/api/Employee/GetAllEmployees?AllEmployees=true", &employees)
+	return employees, err
+}
+
+// FetchTossActiveEmployeesByDesignation returns the active employees of a
+// designation from TOSS
+func FetchTossActiveEmployeesByDesignation(ctx context.Context, designation uint16) ([]EmployeeInfo, error) {
+	var response struct {
+		EmployeeInfo []struct {
+			ID   uint16 `json:"id"`
+			Name string `json:"name"`
+		} `json:"employeeInfo"`
+	}
+	err := getTossJSON(ctx, "/api/Employee/GetAllEmployeesInfo?Status=1&PageSize=10000&Designation="+strconv.Itoa(int(designation)), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	employees := make([]EmployeeInfo, 0, len(response.EmployeeInfo))
+	for _, employee := range response.EmployeeInfo {
+		employees = append(employees, EmployeeInfo{EmployeeID: employee.ID, Name: employee.Name, Designation: designation, IsActive: true})
+	}
+
+	return employees, nil
+}
+
+// GetTossProjects returns the projects from TOSS, or from the local directory if
+// TOSS cannot be reached
+func GetTossProjects(ctx context.Context) ([]ProjectResponse, error) {
+	projects, err := FetchTossProjects(ctx)
+	if err != nil && ctx.Err() == nil && LocalDirectory != nil {
+		log.Info("TOSS not reachable, using the local directory for projects")
+		return LocalDirectory.Projects(ctx)
+	}
+
+	return projects, err
+}
+
+// GetTossDesignations returns the designations from TOSS, or from the local
+// directory if TOSS cannot be reached
+func GetTossDesignations(ctx context.Context) ([]DesignationInfo, error) {
+	designations, err := FetchTossDesignations(ctx)
+	if err != nil && ctx.Err() == nil && LocalDirectory != nil {
+		log.Info("TOSS not reachable, using the local directory for designations")
+		return LocalDirectory.Designations(ctx)
+	}
+
+	return designations, err
+}
+
+// GetTossActiveEmployeesByDesignation returns the active employees of a
+// designation from TOSS, or from the local directory if TOSS cannot be reached
+func GetTossActiveEmployeesByDesignation(ctx context.Context, designation uint16) ([]EmployeeInfo, error) {
+	employees, err := FetchTossActiveEmployeesByDesignation(ctx, designation)
+	if err == nil || ctx.Err() != nil || LocalDirectory == nil {
+		return employees, err
+	}
+
+	log.Info("TOSS not reachable, using the local directory for employees of the designation")
+	directoryEmployees, err := LocalDirectory.Employees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	employees = make([]EmployeeInfo, 0)
+	for _, employee := range directoryEmployees {
+		if employee.IsActive && employee.Designation == designation {
+			employees = append(employees, employee)
+		}
+	}
+
+	return employees, nil
+}
+
+// GetTossEmployees returns the employees from TOSS, or from the local directory
+// if TOSS cannot be reached
+func GetTossEmployees(ctx context.Context) ([]EmployeeInfo, error) {
+	employees, err := FetchTossEmployees(ctx)
+	if err != nil && ctx.Err() == nil && LocalDirectory != nil {
+		log.Info("TOSS not reachable, using the local directory for employees")
+		return LocalDirectory.Employees(ctx)
+	}
+
+	return employees, err
+}
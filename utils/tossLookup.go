@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/constants"
+	log "github.com/mrehanabbasi/appraisal-system-backend/logger"
+)
+
+// ErrEmployeeNotFound is returned when an employee looked up is not in TOSS
+var ErrEmployeeNotFound = errors.New("employee not found")
+
+// EmployeeDetails are the details from TOSS an employee is enrolled in an
+// appraisal with
+type EmployeeDetails struct {
+	EmployeeID      uint16
+	EmployeeName    string
+	Designation     uint16
+	DesignationName string
+	EmployeeImage   string
+	TeamID          uint16
+	TeamName        string
+}
+
+// GetEmployeesDetails looks up the employees in TOSS with a bounded pool of
+// workers. The employee and project lists are fetched once for the batch and
+// every designation is looked up once. The lookups stop at the first failure
+// or once ctx is done, and the details are in the order of empIDs.
+func GetEmployeesDetails(ctx context.Context, empIDs []uint16) ([]EmployeeDetails, error) {
+	details := make([]EmployeeDetails, len(empIDs))
+	if len(empIDs) == 0 {
+		return details, nil
+	}
+	workers := tossLookupWorkers()
+
+	var employees []EmployeeInfo
+	var projects []ProjectResponse
+	err := runConcurrently(ctx, 2, workers, func(ctx context.Context, i int) error {
+		var err error
+		if i == 0 {
+			employees, err = GetTossEmployees(ctx)
+		} else {
+			projects, err = GetTossProjects(ctx)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint16]string, len(employees))
+	for _, employee := range employees {
+		names[employee.EmployeeID] = employee.Name
+	}
+	for k, empID := range empIDs {
+		name, ok := names[empID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrEmployeeNotFound, empID)
+		}
+		details[k].EmployeeID = empID
+		details[k].EmployeeName = name
+
+		// The employee is reported in the last of their projects
+		for _, project := range projects {
+			for _, employee := range project.ProjectEmployees {
+				if employee.EmployeeID == empID {
+					details[k].TeamID = project.ProjectID
+					details[k].TeamName = strings.Trim(project.ProjectName, "\r\n")
+					break
+				}
+			}
+		}
+	}
+
+	err = runConcurrently(ctx, len(empIDs), workers, func(ctx context.Context, i int) error {
+		employee, err := fetchTossEmployee(ctx, empIDs[i])
+		if err != nil {
+			return err
+		}
+		details[i].Designation = employee.RoleID
+		details[i].EmployeeImage = employee.EmployeeImage
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	designations := make([]uint16, 0)
+	designationNames := make(map[uint16]string)
+	for _, detail := range details {
+		if _, ok := designationNames[detail.Designation]; !ok {
+			designationNames[detail.Designation] = ""
+			designations = append(designations, detail.Designation)
+		}
+	}
+
+	var mu sync.Mutex
+	err = runConcurrently(ctx, len(designations), workers, func(ctx context.Context, i int) error {
+		name, err := GetDesignationName(ctx, designations[i])
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		designationNames[designations[i]] = name
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range details {
+		details[k].DesignationName = designationNames[details[k].Designation]
+	}
+
+	return details, nil
+}
+
+type tossEmployee struct {
+	RoleID        uint16 `json:"empDesignation"`
+	EmployeeImage string `json:"employeeImage"`
+}
+
+// fetchTossEmployee returns the designation and image of an employee, which
+// GetRolesID and GetEmployeeImageByID otherwise fetch separately
+func fetchTossEmployee(ctx context.Context, empID uint16) (tossEmployee, error) {
+	var employee tossEmployee
+	err := getTossJSON(ctx, "/api/Employee/"+strconv.FormatUint(uint64(empID), 10), &employee)
+	return employee, err
+}
+
+// runConcurrently calls fn for every index below n on at most workers
+// goroutines. It stops handing out indexes at the first error, which cancels the
+// context passed to fn, or once ctx is done.
+func runConcurrently(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if workers > n {
+		workers = n
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	indexes := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// tossLookupWorkers is constants.TOSS_LOOKUP_WORKERS unless overridden by
+// TOSS_LOOKUP_WORKERS
+func tossLookupWorkers() int {
+	value := os.Getenv("TOSS_LOOKUP_WORKERS")
+	if value == "" {
+		return constants.TOSS_LOOKUP_WORKERS
+	}
+
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers <= 0 {
+		log.Error("invalid TOSS_LOOKUP_WORKERS, using the default")
+		return constants.TOSS_LOOKUP_WORKERS
+	}
+
+	return workers
+}
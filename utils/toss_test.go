@@ -0,0 +1,367 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrehanabbasi/appraisal-system-backend/logger"
+)
+
+const designations = 5
+
+func TestMain(m *testing.M) {
+	_ = os.Setenv("LOG_LEVEL", "error")
+	logger.TextLogInit()
+	os.Exit(m.Run())
+}
+
+// fakeToss serves the TOSS endpoints used to enroll employees for a single team
+// of the given number of employees, answering every request after a fixed
+// latency. It counts the requests and the most requests it served at once.
+type fakeToss struct {
+	employees int
+	latency   time.Duration
+	// failEmployee fails the lookup of the employee with a server error
+	failEmployee int
+
+	requests    int64
+	inFlight    int64
+	maxInFlight int64
+}
+
+func newFakeToss(t testing.TB, toss *fakeToss) *fakeToss {
+	server := httptest.NewServer(toss)
+	t.Cleanup(server.Close)
+	t.Setenv("TOSS_BASE_URL", server.URL)
+	return toss
+}
+
+func (f *fakeToss) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&f.requests, 1)
+	inFlight := atomic.AddInt64(&f.inFlight, 1)
+	defer atomic.AddInt64(&f.inFlight, -1)
+	for {
+		max := atomic.LoadInt64(&f.maxInFlight)
+		if inFlight <= max || atomic.CompareAndSwapInt64(&f.maxInFlight, max, inFlight) {
+			break
+		}
+	}
+
+	select {
+	case <-time.After(f.latency):
+	case <-r.Context().Done():
+		return
+	}
+
+	type projectEmployee struct {
+		EmployeeID   uint16 `json:"employeeId"`
+		EmployeeName string `json:"employeeName"`
+	}
+
+	var response interface{}
+	switch {
+	case r.URL.Path == "/api/Employee/GetAllEmployees":
+		employees := make([]EmployeeInfo, 0, f.employees)
+		for id := 1; id <= f.employees; id++ {
+			employees = append(employees, EmployeeInfo{EmployeeID: uint16(id), Name: fmt.Sprintf("Employee %d", id)})
+		}
+		response = employees
+	case r.URL.Path == "/api/Project/AllProjectsWithEmployeesList":
+		members := make([]projectEmployee, 0, f.employees)
+		for id := 1; id <= f.employees; id++ {
+			members = append(members, projectEmployee{EmployeeID: uint16(id), EmployeeName: fmt.Sprintf("Employee %d", id)})
+		}
+		response = []map[string]interface{}{
+			{"projectId": 1, "projectName": "Team 1\r\n", "projectEmployees": members},
+		}
+	case strings.HasPrefix(r.URL.Path, "/api/Designation/"):
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/Designation/"))
+		response = map[string]interface{}{"designationId": id, "designationName": fmt.Sprintf("Designation %d", id)}
+	case strings.HasPrefix(r.URL.Path, "/api/Employee/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/Employee/"))
+		if err != nil || id < 1 || id > f.employees {
+			http.NotFound(w, r)
+			return
+		}
+		if id == f.failEmployee {
+			http.Error(w, "TOSS failed", http.StatusInternalServerError)
+			return
+		}
+		response = map[string]interface{}{
+			"empDesignation": id%designations + 1,
+			"employeeImage":  fmt.Sprintf("images/%d.png", id),
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func teamIDs(employees int) []uint16 {
+	empIDs := make([]uint16, employees)
+	for k := range empIDs {
+		empIDs[k] = uint16(k + 1)
+	}
+	return empIDs
+}
+
+func TestGetEmployeesDetails(t *testing.T) {
+	toss := newFakeToss(t, &fakeToss{employees: 12})
+
+	// Asked in another order than TOSS lists them
+	empIDs := []uint16{7, 3, 12, 1}
+	details, err := GetEmployeesDetails(context.Background(), empIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(details) != len(empIDs) {
+		t.Fatalf("got %d details, want %d", len(details), len(empIDs))
+	}
+	for k, empID := range empIDs {
+		designation := uint16(int(empID)%designations + 1)
+		want := EmployeeDetails{
+			EmployeeID:      empID,
+			EmployeeName:    fmt.Sprintf("Employee %d", empID),
+			Designation:     designation,
+			DesignationName: fmt.Sprintf("Designation %d", designation),
+			EmployeeImage:   fmt.Sprintf("images/%d.png", empID),
+			TeamID:          1,
+			TeamName:        "Team 1",
+		}
+		if details[k] != want {
+			t.Errorf("details[%d] = %+v, want %+v", k, details[k], want)
+		}
+	}
+
+	// The employee and project lists, every employee and every designation once
+	uniqueDesignations := make(map[uint16]bool)
+	for _, detail := range details {
+		uniqueDesignations[detail.Designation] = true
+	}
+	want := int64(2 + len(empIDs) + len(uniqueDesignations))
+	if requests := atomic.LoadInt64(&toss.requests); requests != want {
+		t.Errorf("made %d TOSS requests, want %d", requests, want)
+	}
+}
+
+func TestGetEmployeesDetailsUnknownEmployee(t *testing.T) {
+	newFakeToss(t, &fakeToss{employees: 3})
+
+	_, err := GetEmployeesDetails(context.Background(), []uint16{1, 4})
+	if !errors.Is(err, ErrEmployeeNotFound) {
+		t.Fatalf("got %v, want ErrEmployeeNotFound", err)
+	}
+}
+
+func TestGetEmployeesDetailsBoundsWorkers(t *testing.T) {
+	t.Setenv("TOSS_LOOKUP_WORKERS", "3")
+	toss := newFakeToss(t, &fakeToss{employees: 24, latency: 10 * time.Millisecond})
+
+	if _, err := GetEmployeesDetails(context.Background(), teamIDs(24)); err != nil {
+		t.Fatal(err)
+	}
+
+	maxInFlight := atomic.LoadInt64(&toss.maxInFlight)
+	if maxInFlight > 3 {
+		t.Fatalf("%d TOSS requests were made at once, want at most 3", maxInFlight)
+	}
+	if maxInFlight < 2 {
+		t.Fatalf("TOSS requests were made one at a time")
+	}
+}
+
+func TestGetEmployeesDetailsStopsAtFirstError(t *testing.T) {
+	t.Setenv("TOSS_LOOKUP_WORKERS", "2")
+	toss := newFakeToss(t, &fakeToss{employees: 40, latency: 5 * time.Millisecond, failEmployee: 3})
+
+	_, err := GetEmployeesDetails(context.Background(), teamIDs(40))
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("got %v, want the failure of the lookup of employee 3", err)
+	}
+
+	// The lists and a few employees, not the whole team
+	if requests := atomic.LoadInt64(&toss.requests); requests >= 2+40 {
+		t.Fatalf("made %d TOSS requests, the lookups went on after the failure", requests)
+	}
+}
+
+func TestGetEmployeesDetailsCancel(t *testing.T) {
+	t.Setenv("TOSS_LOOKUP_WORKERS", "2")
+	toss := newFakeToss(t, &fakeToss{employees: 40, latency: 20 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := GetEmployeesDetails(ctx, teamIDs(40))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("returned %s after the deadline", elapsed)
+	}
+	if requests := atomic.LoadInt64(&toss.requests); requests >= 2+40 {
+		t.Fatalf("made %d TOSS requests, the lookups went on after the cancellation", requests)
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	t.Run("bounds the workers", func(t *testing.T) {
+		var inFlight, maxInFlight int64
+		var mu sync.Mutex
+		seen := make(map[int]bool)
+
+		err := runConcurrently(context.Background(), 50, 4, func(ctx context.Context, i int) error {
+			current := atomic.AddInt64(&inFlight, 1)
+			defer atomic.AddInt64(&inFlight, -1)
+
+			mu.Lock()
+			if current > maxInFlight {
+				maxInFlight = current
+			}
+			seen[i] = true
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if maxInFlight > 4 {
+			t.Fatalf("%d calls ran at once, want at most 4", maxInFlight)
+		}
+		if len(seen) != 50 {
+			t.Fatalf("called for %d indexes, want 50", len(seen))
+		}
+	})
+
+	t.Run("returns the first error and cancels the others", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		var calls int64
+
+		err := runConcurrently(context.Background(), 100, 2, func(ctx context.Context, i int) error {
+			atomic.AddInt64(&calls, 1)
+			if i == 1 {
+				return errFailed
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Millisecond):
+				return nil
+			}
+		})
+		if !errors.Is(err, errFailed) {
+			t.Fatalf("got %v, want the error of the failed call", err)
+		}
+		if calls >= 100 {
+			t.Fatalf("made %d calls, the indexes were handed out after the failure", calls)
+		}
+	})
+
+	t.Run("stops once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int64
+
+		err := runConcurrently(ctx, 100, 2, func(ctx context.Context, i int) error {
+			if atomic.AddInt64(&calls, 1) == 5 {
+				cancel()
+			}
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+		if calls >= 100 {
+			t.Fatalf("made %d calls, the indexes were handed out after the cancellation", calls)
+		}
+	})
+}
+
+// BenchmarkGetEmployeesDetails compares enrolling the members of a team one TOSS
+// call at a time with the concurrent lookups, against a fake TOSS answering
+// every request after a fixed latency
+//
+//	go test ./utils -run '^$' -bench GetEmployeesDetails
+func BenchmarkGetEmployeesDetails(b *testing.B) {
+	const (
+		employees = 40
+		roleKpis  = 3
+	)
+	b.Setenv("TOSS_LOOKUP_WORKERS", "8")
+	toss := newFakeToss(b, &fakeToss{employees: employees, latency: 2 * time.Millisecond})
+	empIDs := teamIDs(employees)
+	ctx := context.Background()
+
+	b.Run("sequential", func(b *testing.B) {
+		atomic.StoreInt64(&toss.requests, 0)
+		for n := 0; n < b.N; n++ {
+			if err := sequentialLookups(ctx, empIDs, roleKpis); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(atomic.LoadInt64(&toss.requests))/float64(b.N), "requests/op")
+	})
+
+	b.Run("concurrent", func(b *testing.B) {
+		atomic.StoreInt64(&toss.requests, 0)
+		for n := 0; n < b.N; n++ {
+			if _, err := GetEmployeesDetails(ctx, empIDs); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(atomic.LoadInt64(&toss.requests))/float64(b.N), "requests/op")
+	})
+}
+
+// sequentialLookups makes the TOSS calls team appraisals were created with
+// before the concurrent lookups, one employee and one call at a time
+func sequentialLookups(ctx context.Context, empIDs []uint16, roleKpis int) error {
+	for _, empID := range empIDs {
+		if _, err := GetEmployeeName(ctx, empID); err != nil {
+			return err
+		}
+		roleIDs, err := GetRolesID(ctx, []uint16{empID})
+		if err != nil {
+			return err
+		}
+		if _, err := GetDesignationName(ctx, roleIDs[0]); err != nil {
+			return err
+		}
+		if _, err := GetEmployeeImageByID(ctx, uint64(empID)); err != nil {
+			return err
+		}
+		if _, err := GetProjectDetailsByEmployeeID(ctx, empID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := GetRolesID(ctx, empIDs); err != nil {
+		return err
+	}
+	for k := 0; k < roleKpis; k++ {
+		for _, empID := range empIDs {
+			if _, err := GetRolesID(ctx, []uint16{empID}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}